@@ -0,0 +1,755 @@
+package backup
+
+import (
+	"archive/tar"
+	"backend-go/models"
+	"backend-go/utils"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pgvector/pgvector-go"
+	"gorm.io/gorm"
+)
+
+// FormatVersion is bumped whenever the archive layout changes incompatibly
+const FormatVersion = 1
+
+// Archive entry names
+const (
+	ManifestFile   = "manifest.json"
+	PDFsFile       = "pdfs.jsonl"
+	SummariesFile  = "summaries.jsonl"
+	EmbeddingsFile = "embeddings.jsonl"
+	FeedbackFile   = "summary_feedbacks.jsonl"
+	FlashcardsFile = "flashcards.jsonl"
+	SourcesFile    = "summary_sources.jsonl"
+	StudyItemsFile = "study_items.jsonl"
+	SchedulesFile  = "study_schedules.jsonl"
+	ReviewsFile    = "study_reviews.jsonl"
+	QuizzesFile    = "quizzes.jsonl"
+	QuestionsFile  = "quiz_questions.jsonl"
+	AttemptsFile   = "quiz_attempts.jsonl"
+	AnswersFile    = "quiz_answers.jsonl"
+	GlossaryFile   = "glossary_terms.jsonl"
+	OutlinesFile   = "document_outlines.jsonl"
+	TopicsFile     = "topics.jsonl"
+	MembersFile    = "topic_members.jsonl"
+	FilesDir       = "files/"
+)
+
+// Manifest describes the contents of a backup archive
+type Manifest struct {
+	FormatVersion int            `json:"format_version"`
+	CreatedAt     time.Time      `json:"created_at"`
+	AppVersion    string         `json:"app_version"`
+	Counts        map[string]int `json:"counts"`
+	MissingFiles  []string       `json:"missing_files,omitempty"`
+}
+
+type PDFRecord struct {
+	ID               uint      `json:"id"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	Filename         string    `json:"filename"`
+	FileSize         int64     `json:"file_size"`
+	Title            string    `json:"title"`
+	PageCount        int       `json:"page_count"`
+	Summary          string    `json:"summary"`
+	Style            string    `json:"style"`
+	Language         string    `json:"language"`
+	SummaryTime      float64   `json:"summary_time"`
+	SummaryVersion   int       `json:"summary_version"`
+	DetectedLanguage string    `json:"detected_language"`
+	PinnedSummaryID  *uint     `json:"pinned_summary_id"`
+}
+
+type SummaryRecord struct {
+	ID              uint       `json:"id"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	PDFID           *uint      `json:"pdf_id"`
+	Style           string     `json:"style"`
+	Content         string     `json:"content"`
+	Language        string     `json:"language"`
+	SummaryTime     float64    `json:"summary_time"`
+	SourceSummaryID *uint      `json:"source_summary_id"`
+	OriginalContent string     `json:"original_content"`
+	ModelName       string     `json:"model_name"`
+	PromptVersion   string     `json:"prompt_version"`
+	EditedBy        string     `json:"edited_by"`
+	EditedAt        *time.Time `json:"edited_at"`
+	Flagged         bool       `json:"flagged"`
+	Focus           string     `json:"focus"`
+}
+
+type EmbeddingRecord struct {
+	SummaryID uint      `json:"summary_id"`
+	Embedding []float32 `json:"embedding"`
+}
+
+type FeedbackRecord struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	SummaryID uint      `json:"summary_id"`
+	Vote      int       `json:"vote"`
+	Rating    *int      `json:"rating"`
+	Comment   string    `json:"comment"`
+	Issues    string    `json:"issues"`
+}
+
+type FlashcardRecord struct {
+	ID            uint      `json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	PDFID         uint      `json:"pdf_id"`
+	SummaryID     *uint     `json:"summary_id"`
+	Question      string    `json:"question"`
+	Answer        string    `json:"answer"`
+	SourcePage    *int      `json:"source_page"`
+	Language      string    `json:"language"`
+	ModelName     string    `json:"model_name"`
+	PromptVersion string    `json:"prompt_version"`
+}
+
+type SourceRecord struct {
+	ID               uint      `json:"id"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	SummaryID        uint      `json:"summary_id"`
+	PDFID            uint      `json:"pdf_id"`
+	Position         int       `json:"position"`
+	ContextSummaryID *uint     `json:"context_summary_id"`
+}
+
+type StudyItemRecord struct {
+	ID          uint      `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	WorkspaceID string    `json:"workspace_id"`
+	SummaryID   uint      `json:"summary_id"`
+	Position    int       `json:"position"`
+	PDFID       uint      `json:"pdf_id"`
+	Content     string    `json:"content"`
+}
+
+type StudyScheduleRecord struct {
+	ID             uint       `json:"id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	StudyItemID    uint       `json:"study_item_id"`
+	UserID         string     `json:"user_id"`
+	EaseFactor     float64    `json:"ease_factor"`
+	IntervalDays   int        `json:"interval_days"`
+	Repetitions    int        `json:"repetitions"`
+	DueAt          time.Time  `json:"due_at"`
+	LastReviewedAt *time.Time `json:"last_reviewed_at"`
+}
+
+type StudyReviewRecord struct {
+	ID           uint      `json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	StudyItemID  uint      `json:"study_item_id"`
+	UserID       string    `json:"user_id"`
+	WorkspaceID  string    `json:"workspace_id"`
+	Grade        int       `json:"grade"`
+	EaseFactor   float64   `json:"ease_factor"`
+	IntervalDays int       `json:"interval_days"`
+	DueAt        time.Time `json:"due_at"`
+}
+
+type QuizRecord struct {
+	ID            uint      `json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	PDFID         uint      `json:"pdf_id"`
+	WorkspaceID   string    `json:"workspace_id"`
+	Title         string    `json:"title"`
+	Language      string    `json:"language"`
+	ModelName     string    `json:"model_name"`
+	PromptVersion string    `json:"prompt_version"`
+}
+
+type QuestionRecord struct {
+	ID            uint      `json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	QuizID        uint      `json:"quiz_id"`
+	Position      int       `json:"position"`
+	Type          string    `json:"type"`
+	Question      string    `json:"question"`
+	Options       []string  `json:"options"`
+	CorrectOption *int      `json:"correct_option"`
+	Answer        string    `json:"answer"`
+	Explanation   string    `json:"explanation"`
+	SourcePage    *int      `json:"source_page"`
+}
+
+type AttemptRecord struct {
+	ID           uint      `json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	QuizID       uint      `json:"quiz_id"`
+	UserID       string    `json:"user_id"`
+	WorkspaceID  string    `json:"workspace_id"`
+	CorrectCount int       `json:"correct_count"`
+	Total        int       `json:"total"`
+	Score        float64   `json:"score"`
+}
+
+type AnswerRecord struct {
+	ID             uint      `json:"id"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	AttemptID      uint      `json:"attempt_id"`
+	QuestionID     uint      `json:"question_id"`
+	SelectedOption *int      `json:"selected_option"`
+	Response       string    `json:"response"`
+	Correct        bool      `json:"correct"`
+	Similarity     *float64  `json:"similarity"`
+}
+
+type GlossaryTermRecord struct {
+	ID             uint      `json:"id"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	PDFID          uint      `json:"pdf_id"`
+	Term           string    `json:"term"`
+	NormalizedTerm string    `json:"normalized_term"`
+	Definition     string    `json:"definition"`
+	Page           *int      `json:"page"`
+	Language       string    `json:"language"`
+	ModelName      string    `json:"model_name"`
+	PromptVersion  string    `json:"prompt_version"`
+}
+
+type OutlineRecord struct {
+	ID            uint                    `json:"id"`
+	CreatedAt     time.Time               `json:"created_at"`
+	UpdatedAt     time.Time               `json:"updated_at"`
+	PDFID         uint                    `json:"pdf_id"`
+	Source        string                  `json:"source"`
+	Sections      []models.OutlineSection `json:"sections"`
+	Language      string                  `json:"language"`
+	ModelName     string                  `json:"model_name"`
+	PromptVersion string                  `json:"prompt_version"`
+}
+
+type TopicRecord struct {
+	ID            uint      `json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Label         string    `json:"label"`
+	Description   string    `json:"description"`
+	Size          int       `json:"size"`
+	ModelName     string    `json:"model_name"`
+	PromptVersion string    `json:"prompt_version"`
+}
+
+type TopicMemberRecord struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	TopicID   uint      `json:"topic_id"`
+	PDFID     uint      `json:"pdf_id"`
+	Score     float64   `json:"score"`
+}
+
+// Export writes a gzipped tar archive of all PDFs, summaries, embeddings,
+// feedback, flashcards, summary sources, study items with their schedules and reviews,
+// quizzes with their questions, attempts and answers, glossary terms, document outlines,
+// topics with their PDF assignments and stored files to w. Queries and file reads use
+// the context of db.
+func Export(db *gorm.DB, w io.Writer) (*Manifest, error) {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	manifest := &Manifest{
+		FormatVersion: FormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		AppVersion:    "1.0.0",
+		Counts:        make(map[string]int),
+	}
+
+	var pdfs []models.PDF
+	if err := db.Order("id").Find(&pdfs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch PDFs: %w", err)
+	}
+	pdfRecords := make([]interface{}, len(pdfs))
+	for i, pdf := range pdfs {
+		pdfRecords[i] = PDFRecord{
+			ID:               pdf.ID,
+			CreatedAt:        pdf.CreatedAt,
+			UpdatedAt:        pdf.UpdatedAt,
+			Filename:         pdf.Filename,
+			FileSize:         pdf.FileSize,
+			Title:            pdf.Title,
+			PageCount:        pdf.PageCount,
+			Summary:          pdf.Summary,
+			Style:            pdf.Style,
+			Language:         pdf.Language,
+			SummaryTime:      pdf.SummaryTime,
+			SummaryVersion:   pdf.SummaryVersion,
+			DetectedLanguage: pdf.DetectedLanguage,
+			PinnedSummaryID:  pdf.PinnedSummaryID,
+		}
+	}
+	if err := writeJSONL(tw, PDFsFile, pdfRecords); err != nil {
+		return nil, err
+	}
+	manifest.Counts["pdfs"] = len(pdfRecords)
+
+	// Embeddings are read separately so summaries without one can still be exported
+	var summaries []models.Summaries
+	if err := db.Omit("embedding").Order("id").Find(&summaries).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch summaries: %w", err)
+	}
+	var embeddings []struct {
+		ID        uint
+		Embedding pgvector.Vector
+	}
+	if err := db.Model(&models.Summaries{}).Select("id", "embedding").
+		Where("embedding IS NOT NULL").Order("id").Scan(&embeddings).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch embeddings: %w", err)
+	}
+
+	summaryRecords := make([]interface{}, len(summaries))
+	for i, s := range summaries {
+		summaryRecords[i] = SummaryRecord{
+			ID:              s.ID,
+			CreatedAt:       s.CreatedAt,
+			UpdatedAt:       s.UpdatedAt,
+			PDFID:           s.PDFID,
+			Style:           s.Style,
+			Content:         s.Content,
+			Language:        s.Language,
+			SummaryTime:     s.SummaryTime,
+			SourceSummaryID: s.SourceSummaryID,
+			OriginalContent: s.OriginalContent,
+			ModelName:       s.ModelName,
+			PromptVersion:   s.PromptVersion,
+			EditedBy:        s.EditedBy,
+			EditedAt:        s.EditedAt,
+			Flagged:         s.Flagged,
+			Focus:           s.Focus,
+		}
+	}
+	embeddingRecords := make([]interface{}, len(embeddings))
+	for i, e := range embeddings {
+		embeddingRecords[i] = EmbeddingRecord{SummaryID: e.ID, Embedding: e.Embedding.Slice()}
+	}
+	if err := writeJSONL(tw, SummariesFile, summaryRecords); err != nil {
+		return nil, err
+	}
+	if err := writeJSONL(tw, EmbeddingsFile, embeddingRecords); err != nil {
+		return nil, err
+	}
+	manifest.Counts["summaries"] = len(summaryRecords)
+	manifest.Counts["embeddings"] = len(embeddingRecords)
+
+	var feedback []models.SummaryFeedback
+	if err := db.Order("id").Find(&feedback).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch summary feedback: %w", err)
+	}
+	feedbackRecords := make([]interface{}, len(feedback))
+	for i, f := range feedback {
+		feedbackRecords[i] = FeedbackRecord{
+			ID:        f.ID,
+			CreatedAt: f.CreatedAt,
+			UpdatedAt: f.UpdatedAt,
+			SummaryID: f.SummaryID,
+			Vote:      f.Vote,
+			Rating:    f.Rating,
+			Comment:   f.Comment,
+			Issues:    f.Issues,
+		}
+	}
+	if err := writeJSONL(tw, FeedbackFile, feedbackRecords); err != nil {
+		return nil, err
+	}
+	manifest.Counts["summary_feedbacks"] = len(feedbackRecords)
+
+	var flashcards []models.Flashcard
+	if err := db.Order("id").Find(&flashcards).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch flashcards: %w", err)
+	}
+	flashcardRecords := make([]interface{}, len(flashcards))
+	for i, f := range flashcards {
+		flashcardRecords[i] = FlashcardRecord{
+			ID:            f.ID,
+			CreatedAt:     f.CreatedAt,
+			UpdatedAt:     f.UpdatedAt,
+			PDFID:         f.PDFID,
+			SummaryID:     f.SummaryID,
+			Question:      f.Question,
+			Answer:        f.Answer,
+			SourcePage:    f.SourcePage,
+			Language:      f.Language,
+			ModelName:     f.ModelName,
+			PromptVersion: f.PromptVersion,
+		}
+	}
+	if err := writeJSONL(tw, FlashcardsFile, flashcardRecords); err != nil {
+		return nil, err
+	}
+	manifest.Counts["flashcards"] = len(flashcardRecords)
+
+	var sources []models.SummarySource
+	if err := db.Order("id").Find(&sources).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch summary sources: %w", err)
+	}
+	sourceRecords := make([]interface{}, len(sources))
+	for i, src := range sources {
+		sourceRecords[i] = SourceRecord{
+			ID:               src.ID,
+			CreatedAt:        src.CreatedAt,
+			UpdatedAt:        src.UpdatedAt,
+			SummaryID:        src.SummaryID,
+			PDFID:            src.PDFID,
+			Position:         src.Position,
+			ContextSummaryID: src.ContextSummaryID,
+		}
+	}
+	if err := writeJSONL(tw, SourcesFile, sourceRecords); err != nil {
+		return nil, err
+	}
+	manifest.Counts["summary_sources"] = len(sourceRecords)
+
+	var studyItems []models.StudyItem
+	if err := db.Order("id").Find(&studyItems).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch study items: %w", err)
+	}
+	studyItemRecords := make([]interface{}, len(studyItems))
+	for i, item := range studyItems {
+		studyItemRecords[i] = StudyItemRecord{
+			ID:          item.ID,
+			CreatedAt:   item.CreatedAt,
+			UpdatedAt:   item.UpdatedAt,
+			WorkspaceID: item.WorkspaceID,
+			SummaryID:   item.SummaryID,
+			Position:    item.Position,
+			PDFID:       item.PDFID,
+			Content:     item.Content,
+		}
+	}
+	if err := writeJSONL(tw, StudyItemsFile, studyItemRecords); err != nil {
+		return nil, err
+	}
+	manifest.Counts["study_items"] = len(studyItemRecords)
+
+	var schedules []models.StudySchedule
+	if err := db.Order("id").Find(&schedules).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch study schedules: %w", err)
+	}
+	scheduleRecords := make([]interface{}, len(schedules))
+	for i, schedule := range schedules {
+		scheduleRecords[i] = StudyScheduleRecord{
+			ID:             schedule.ID,
+			CreatedAt:      schedule.CreatedAt,
+			UpdatedAt:      schedule.UpdatedAt,
+			StudyItemID:    schedule.StudyItemID,
+			UserID:         schedule.UserID,
+			EaseFactor:     schedule.EaseFactor,
+			IntervalDays:   schedule.IntervalDays,
+			Repetitions:    schedule.Repetitions,
+			DueAt:          schedule.DueAt,
+			LastReviewedAt: schedule.LastReviewedAt,
+		}
+	}
+	if err := writeJSONL(tw, SchedulesFile, scheduleRecords); err != nil {
+		return nil, err
+	}
+	manifest.Counts["study_schedules"] = len(scheduleRecords)
+
+	var reviews []models.StudyReview
+	if err := db.Order("id").Find(&reviews).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch study reviews: %w", err)
+	}
+	reviewRecords := make([]interface{}, len(reviews))
+	for i, review := range reviews {
+		reviewRecords[i] = StudyReviewRecord{
+			ID:           review.ID,
+			CreatedAt:    review.CreatedAt,
+			UpdatedAt:    review.UpdatedAt,
+			StudyItemID:  review.StudyItemID,
+			UserID:       review.UserID,
+			WorkspaceID:  review.WorkspaceID,
+			Grade:        review.Grade,
+			EaseFactor:   review.EaseFactor,
+			IntervalDays: review.IntervalDays,
+			DueAt:        review.DueAt,
+		}
+	}
+	if err := writeJSONL(tw, ReviewsFile, reviewRecords); err != nil {
+		return nil, err
+	}
+	manifest.Counts["study_reviews"] = len(reviewRecords)
+
+	var quizzes []models.Quiz
+	if err := db.Order("id").Find(&quizzes).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch quizzes: %w", err)
+	}
+	quizRecords := make([]interface{}, len(quizzes))
+	for i, quiz := range quizzes {
+		quizRecords[i] = QuizRecord{
+			ID:            quiz.ID,
+			CreatedAt:     quiz.CreatedAt,
+			UpdatedAt:     quiz.UpdatedAt,
+			PDFID:         quiz.PDFID,
+			WorkspaceID:   quiz.WorkspaceID,
+			Title:         quiz.Title,
+			Language:      quiz.Language,
+			ModelName:     quiz.ModelName,
+			PromptVersion: quiz.PromptVersion,
+		}
+	}
+	if err := writeJSONL(tw, QuizzesFile, quizRecords); err != nil {
+		return nil, err
+	}
+	manifest.Counts["quizzes"] = len(quizRecords)
+
+	var questions []models.QuizQuestion
+	if err := db.Order("id").Find(&questions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch quiz questions: %w", err)
+	}
+	questionRecords := make([]interface{}, len(questions))
+	for i, q := range questions {
+		questionRecords[i] = QuestionRecord{
+			ID:            q.ID,
+			CreatedAt:     q.CreatedAt,
+			UpdatedAt:     q.UpdatedAt,
+			QuizID:        q.QuizID,
+			Position:      q.Position,
+			Type:          q.Type,
+			Question:      q.Question,
+			Options:       q.Options,
+			CorrectOption: q.CorrectOption,
+			Answer:        q.Answer,
+			Explanation:   q.Explanation,
+			SourcePage:    q.SourcePage,
+		}
+	}
+	if err := writeJSONL(tw, QuestionsFile, questionRecords); err != nil {
+		return nil, err
+	}
+	manifest.Counts["quiz_questions"] = len(questionRecords)
+
+	var attempts []models.QuizAttempt
+	if err := db.Order("id").Find(&attempts).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch quiz attempts: %w", err)
+	}
+	attemptRecords := make([]interface{}, len(attempts))
+	for i, attempt := range attempts {
+		attemptRecords[i] = AttemptRecord{
+			ID:           attempt.ID,
+			CreatedAt:    attempt.CreatedAt,
+			UpdatedAt:    attempt.UpdatedAt,
+			QuizID:       attempt.QuizID,
+			UserID:       attempt.UserID,
+			WorkspaceID:  attempt.WorkspaceID,
+			CorrectCount: attempt.CorrectCount,
+			Total:        attempt.Total,
+			Score:        attempt.Score,
+		}
+	}
+	if err := writeJSONL(tw, AttemptsFile, attemptRecords); err != nil {
+		return nil, err
+	}
+	manifest.Counts["quiz_attempts"] = len(attemptRecords)
+
+	var answers []models.QuizAnswer
+	if err := db.Order("id").Find(&answers).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch quiz answers: %w", err)
+	}
+	answerRecords := make([]interface{}, len(answers))
+	for i, answer := range answers {
+		answerRecords[i] = AnswerRecord{
+			ID:             answer.ID,
+			CreatedAt:      answer.CreatedAt,
+			UpdatedAt:      answer.UpdatedAt,
+			AttemptID:      answer.AttemptID,
+			QuestionID:     answer.QuestionID,
+			SelectedOption: answer.SelectedOption,
+			Response:       answer.Response,
+			Correct:        answer.Correct,
+			Similarity:     answer.Similarity,
+		}
+	}
+	if err := writeJSONL(tw, AnswersFile, answerRecords); err != nil {
+		return nil, err
+	}
+	manifest.Counts["quiz_answers"] = len(answerRecords)
+
+	var terms []models.GlossaryTerm
+	if err := db.Order("id").Find(&terms).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch glossary terms: %w", err)
+	}
+	termRecords := make([]interface{}, len(terms))
+	for i, term := range terms {
+		termRecords[i] = GlossaryTermRecord{
+			ID:             term.ID,
+			CreatedAt:      term.CreatedAt,
+			UpdatedAt:      term.UpdatedAt,
+			PDFID:          term.PDFID,
+			Term:           term.Term,
+			NormalizedTerm: term.NormalizedTerm,
+			Definition:     term.Definition,
+			Page:           term.Page,
+			Language:       term.Language,
+			ModelName:      term.ModelName,
+			PromptVersion:  term.PromptVersion,
+		}
+	}
+	if err := writeJSONL(tw, GlossaryFile, termRecords); err != nil {
+		return nil, err
+	}
+	manifest.Counts["glossary_terms"] = len(termRecords)
+
+	var outlines []models.DocumentOutline
+	if err := db.Order("id").Find(&outlines).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch document outlines: %w", err)
+	}
+	outlineRecords := make([]interface{}, len(outlines))
+	for i, outline := range outlines {
+		outlineRecords[i] = OutlineRecord{
+			ID:            outline.ID,
+			CreatedAt:     outline.CreatedAt,
+			UpdatedAt:     outline.UpdatedAt,
+			PDFID:         outline.PDFID,
+			Source:        outline.Source,
+			Sections:      outline.Sections,
+			Language:      outline.Language,
+			ModelName:     outline.ModelName,
+			PromptVersion: outline.PromptVersion,
+		}
+	}
+	if err := writeJSONL(tw, OutlinesFile, outlineRecords); err != nil {
+		return nil, err
+	}
+	manifest.Counts["document_outlines"] = len(outlineRecords)
+
+	var topicList []models.Topic
+	if err := db.Order("id").Find(&topicList).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch topics: %w", err)
+	}
+	topicRecords := make([]interface{}, len(topicList))
+	for i, topic := range topicList {
+		topicRecords[i] = TopicRecord{
+			ID:            topic.ID,
+			CreatedAt:     topic.CreatedAt,
+			UpdatedAt:     topic.UpdatedAt,
+			Label:         topic.Label,
+			Description:   topic.Description,
+			Size:          topic.Size,
+			ModelName:     topic.ModelName,
+			PromptVersion: topic.PromptVersion,
+		}
+	}
+	if err := writeJSONL(tw, TopicsFile, topicRecords); err != nil {
+		return nil, err
+	}
+	manifest.Counts["topics"] = len(topicRecords)
+
+	var members []models.TopicMember
+	if err := db.Order("id").Find(&members).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch topic members: %w", err)
+	}
+	memberRecords := make([]interface{}, len(members))
+	for i, member := range members {
+		memberRecords[i] = TopicMemberRecord{
+			ID:        member.ID,
+			CreatedAt: member.CreatedAt,
+			UpdatedAt: member.UpdatedAt,
+			TopicID:   member.TopicID,
+			PDFID:     member.PDFID,
+			Score:     member.Score,
+		}
+	}
+	if err := writeJSONL(tw, MembersFile, memberRecords); err != nil {
+		return nil, err
+	}
+	manifest.Counts["topic_members"] = len(memberRecords)
+
+	// Stored PDF files; missing files are recorded rather than failing the backup
+	for _, pdf := range pdfs {
+		reader, size, err := utils.OpenStoredFile(db.Statement.Context, pdf.Filename)
+		if err != nil {
+			fmt.Printf("Warning: Skipping file %s: %v\n", pdf.Filename, err)
+			manifest.MissingFiles = append(manifest.MissingFiles, pdf.Filename)
+			continue
+		}
+		err = writeEntry(tw, FilesDir+pdf.Filename, size, reader)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+		manifest.Counts["files"]++
+	}
+
+	// The manifest goes last so it can record what was actually written;
+	// Restore extracts the whole archive before reading it
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := writeEntry(tw, ManifestFile, int64(len(manifestData)), bytes.NewReader(manifestData)); err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish compression: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// writeJSONL spools records to a temporary file first because tar needs the entry size up front
+func writeJSONL(tw *tar.Writer, name string, records []interface{}) error {
+	tmp, err := os.CreateTemp("", "backup-*.jsonl")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	encoder := json.NewEncoder(tmp)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to size %s: %w", name, err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind %s: %w", name, err)
+	}
+
+	return writeEntry(tw, name, size, tmp)
+}
+
+func writeEntry(tw *tar.Writer, name string, size int64, r io.Reader) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    size,
+		ModTime: time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to write header for %s: %w", name, err)
+	}
+	if _, err := io.Copy(tw, r); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,804 @@
+package backup
+
+import (
+	"archive/tar"
+	"backend-go/models"
+	"backend-go/utils"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pgvector/pgvector-go"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RestoreResult counts what a restore wrote
+type RestoreResult struct {
+	PDFs          int `json:"pdfs"`
+	Summaries     int `json:"summaries"`
+	Embeddings    int `json:"embeddings"`
+	Feedback      int `json:"summary_feedbacks"`
+	Flashcards    int `json:"flashcards"`
+	Sources       int `json:"summary_sources"`
+	StudyItems    int `json:"study_items"`
+	Schedules     int `json:"study_schedules"`
+	Reviews       int `json:"study_reviews"`
+	Quizzes       int `json:"quizzes"`
+	Questions     int `json:"quiz_questions"`
+	Attempts      int `json:"quiz_attempts"`
+	Answers       int `json:"quiz_answers"`
+	GlossaryTerms int `json:"glossary_terms"`
+	Outlines      int `json:"document_outlines"`
+	Topics        int `json:"topics"`
+	TopicMembers  int `json:"topic_members"`
+	FilesRestored int `json:"files_restored"`
+	FilesSkipped  int `json:"files_skipped"`
+}
+
+// Restore imports an archive produced by Export. Rows keep their original IDs and
+// are upserted, so restoring the same archive twice leaves the database unchanged.
+// A row matching an existing row on a unique key (e.g. a study schedule for the same
+// user and item) is merged into that row and takes its ID. Unless force is set, the restore is refused when an existing row with the same ID
+// belongs to different data (e.g. a PDF with another file). Queries and file writes use
+// the context of db.
+func Restore(db *gorm.DB, r io.Reader, force bool) (*RestoreResult, error) {
+	dir, err := os.MkdirTemp("", "restore-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := extract(r, dir); err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	if err := readJSON(filepath.Join(dir, ManifestFile), &manifest); err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if manifest.FormatVersion < 1 || manifest.FormatVersion > FormatVersion {
+		return nil, fmt.Errorf("unsupported backup format version %d", manifest.FormatVersion)
+	}
+
+	var pdfs []PDFRecord
+	var summaries []SummaryRecord
+	var embeddings []EmbeddingRecord
+	var feedback []FeedbackRecord
+	var flashcards []FlashcardRecord
+	var sources []SourceRecord
+	var studyItems []StudyItemRecord
+	var schedules []StudyScheduleRecord
+	var reviews []StudyReviewRecord
+	var quizzes []QuizRecord
+	var questions []QuestionRecord
+	var attempts []AttemptRecord
+	var answers []AnswerRecord
+	var terms []GlossaryTermRecord
+	var outlines []OutlineRecord
+	var topicList []TopicRecord
+	var members []TopicMemberRecord
+	for name, target := range map[string]interface{}{
+		PDFsFile:       &pdfs,
+		SummariesFile:  &summaries,
+		EmbeddingsFile: &embeddings,
+		FeedbackFile:   &feedback,
+		FlashcardsFile: &flashcards,
+		SourcesFile:    &sources,
+		StudyItemsFile: &studyItems,
+		SchedulesFile:  &schedules,
+		ReviewsFile:    &reviews,
+		QuizzesFile:    &quizzes,
+		QuestionsFile:  &questions,
+		AttemptsFile:   &attempts,
+		AnswersFile:    &answers,
+		GlossaryFile:   &terms,
+		OutlinesFile:   &outlines,
+		TopicsFile:     &topicList,
+		MembersFile:    &members,
+	} {
+		if err := readJSONL(filepath.Join(dir, name), target); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+	}
+
+	if !force {
+		if err := checkConflicts(db, pdfs, summaries, feedback, flashcards, studyItems, quizzes, terms, outlines, members); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &RestoreResult{}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		upsert := func(omit ...string) *gorm.DB {
+			return tx.Omit(append([]string{clause.Associations}, omit...)...).Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "id"}},
+				UpdateAll: true,
+			})
+		}
+
+		// PDFs first without their pinned summary, which does not exist yet
+		pdfModels := make([]models.PDF, len(pdfs))
+		for i, p := range pdfs {
+			pdfModels[i] = models.PDF{
+				Model:            gorm.Model{ID: p.ID, CreatedAt: p.CreatedAt, UpdatedAt: p.UpdatedAt},
+				Filename:         p.Filename,
+				FileSize:         p.FileSize,
+				Title:            p.Title,
+				PageCount:        p.PageCount,
+				DetectedLanguage: p.DetectedLanguage,
+			}
+		}
+		if len(pdfModels) > 0 {
+			if err := upsert("pinned_summary_id").CreateInBatches(&pdfModels, 100).Error; err != nil {
+				return fmt.Errorf("failed to restore PDFs: %w", err)
+			}
+		}
+		result.PDFs = len(pdfModels)
+
+		// Embeddings are applied separately since an empty vector is not a valid column value
+		summaryModels := make([]models.Summaries, len(summaries))
+		for i, s := range summaries {
+			summaryModels[i] = models.Summaries{
+				Model:           gorm.Model{ID: s.ID, CreatedAt: s.CreatedAt, UpdatedAt: s.UpdatedAt},
+				Style:           s.Style,
+				Content:         s.Content,
+				PDFID:           s.PDFID,
+				Language:        s.Language,
+				SummaryTime:     s.SummaryTime,
+				SourceSummaryID: s.SourceSummaryID,
+				OriginalContent: s.OriginalContent,
+				ModelName:       s.ModelName,
+				PromptVersion:   s.PromptVersion,
+				EditedBy:        s.EditedBy,
+				EditedAt:        s.EditedAt,
+				Flagged:         s.Flagged,
+				Focus:           s.Focus,
+			}
+		}
+		if len(summaryModels) > 0 {
+			if err := upsert("embedding").CreateInBatches(&summaryModels, 100).Error; err != nil {
+				return fmt.Errorf("failed to restore summaries: %w", err)
+			}
+		}
+		result.Summaries = len(summaryModels)
+
+		for _, e := range embeddings {
+			if err := tx.Model(&models.Summaries{}).Where("id = ?", e.SummaryID).
+				UpdateColumn("embedding", pgvector.NewVector(e.Embedding)).Error; err != nil {
+				return fmt.Errorf("failed to restore embedding for summary %d: %w", e.SummaryID, err)
+			}
+		}
+		result.Embeddings = len(embeddings)
+
+		feedbackModels := make([]models.SummaryFeedback, len(feedback))
+		for i, f := range feedback {
+			feedbackModels[i] = models.SummaryFeedback{
+				Model:     gorm.Model{ID: f.ID, CreatedAt: f.CreatedAt, UpdatedAt: f.UpdatedAt},
+				SummaryID: f.SummaryID,
+				Vote:      f.Vote,
+				Rating:    f.Rating,
+				Comment:   f.Comment,
+				Issues:    f.Issues,
+			}
+		}
+		if len(feedbackModels) > 0 {
+			if err := upsert().CreateInBatches(&feedbackModels, 100).Error; err != nil {
+				return fmt.Errorf("failed to restore summary feedback: %w", err)
+			}
+		}
+		result.Feedback = len(feedbackModels)
+
+		flashcardModels := make([]models.Flashcard, len(flashcards))
+		for i, f := range flashcards {
+			flashcardModels[i] = models.Flashcard{
+				Model:         gorm.Model{ID: f.ID, CreatedAt: f.CreatedAt, UpdatedAt: f.UpdatedAt},
+				PDFID:         f.PDFID,
+				SummaryID:     f.SummaryID,
+				Question:      f.Question,
+				Answer:        f.Answer,
+				SourcePage:    f.SourcePage,
+				Language:      f.Language,
+				ModelName:     f.ModelName,
+				PromptVersion: f.PromptVersion,
+			}
+		}
+		if len(flashcardModels) > 0 {
+			if err := upsert().CreateInBatches(&flashcardModels, 100).Error; err != nil {
+				return fmt.Errorf("failed to restore flashcards: %w", err)
+			}
+		}
+		result.Flashcards = len(flashcardModels)
+
+		sourceModels := make([]models.SummarySource, len(sources))
+		for i, src := range sources {
+			sourceModels[i] = models.SummarySource{
+				Model:            gorm.Model{ID: src.ID, CreatedAt: src.CreatedAt, UpdatedAt: src.UpdatedAt},
+				SummaryID:        src.SummaryID,
+				PDFID:            src.PDFID,
+				Position:         src.Position,
+				ContextSummaryID: src.ContextSummaryID,
+			}
+		}
+		if _, err := matchNaturalKeys(tx, "summary_sources", len(sourceModels), func(i int) (*uint, map[string]interface{}) {
+			src := &sourceModels[i]
+			return &src.ID, map[string]interface{}{"summary_id": src.SummaryID, "pdf_id": src.PDFID}
+		}); err != nil {
+			return err
+		}
+		if len(sourceModels) > 0 {
+			if err := upsert().CreateInBatches(&sourceModels, 100).Error; err != nil {
+				return fmt.Errorf("failed to restore summary sources: %w", err)
+			}
+		}
+		result.Sources = len(sourceModels)
+
+		studyItemModels := make([]models.StudyItem, len(studyItems))
+		for i, item := range studyItems {
+			studyItemModels[i] = models.StudyItem{
+				Model:       gorm.Model{ID: item.ID, CreatedAt: item.CreatedAt, UpdatedAt: item.UpdatedAt},
+				WorkspaceID: item.WorkspaceID,
+				SummaryID:   item.SummaryID,
+				Position:    item.Position,
+				PDFID:       item.PDFID,
+				Content:     item.Content,
+			}
+		}
+		// Schedules and reviews follow study items that take over the ID of an existing item
+		itemIDs, err := matchNaturalKeys(tx, "study_items", len(studyItemModels), func(i int) (*uint, map[string]interface{}) {
+			item := &studyItemModels[i]
+			return &item.ID, map[string]interface{}{"workspace_id": item.WorkspaceID, "summary_id": item.SummaryID, "position": item.Position}
+		})
+		if err != nil {
+			return err
+		}
+		if len(studyItemModels) > 0 {
+			if err := upsert().CreateInBatches(&studyItemModels, 100).Error; err != nil {
+				return fmt.Errorf("failed to restore study items: %w", err)
+			}
+		}
+		result.StudyItems = len(studyItemModels)
+
+		scheduleModels := make([]models.StudySchedule, len(schedules))
+		for i, schedule := range schedules {
+			scheduleModels[i] = models.StudySchedule{
+				Model:          gorm.Model{ID: schedule.ID, CreatedAt: schedule.CreatedAt, UpdatedAt: schedule.UpdatedAt},
+				StudyItemID:    remapID(itemIDs, schedule.StudyItemID),
+				UserID:         schedule.UserID,
+				EaseFactor:     schedule.EaseFactor,
+				IntervalDays:   schedule.IntervalDays,
+				Repetitions:    schedule.Repetitions,
+				DueAt:          schedule.DueAt,
+				LastReviewedAt: schedule.LastReviewedAt,
+			}
+		}
+		if _, err := matchNaturalKeys(tx, "study_schedules", len(scheduleModels), func(i int) (*uint, map[string]interface{}) {
+			schedule := &scheduleModels[i]
+			return &schedule.ID, map[string]interface{}{"study_item_id": schedule.StudyItemID, "user_id": schedule.UserID}
+		}); err != nil {
+			return err
+		}
+		if len(scheduleModels) > 0 {
+			if err := upsert().CreateInBatches(&scheduleModels, 100).Error; err != nil {
+				return fmt.Errorf("failed to restore study schedules: %w", err)
+			}
+		}
+		result.Schedules = len(scheduleModels)
+
+		reviewModels := make([]models.StudyReview, len(reviews))
+		for i, review := range reviews {
+			reviewModels[i] = models.StudyReview{
+				Model:        gorm.Model{ID: review.ID, CreatedAt: review.CreatedAt, UpdatedAt: review.UpdatedAt},
+				StudyItemID:  remapID(itemIDs, review.StudyItemID),
+				UserID:       review.UserID,
+				WorkspaceID:  review.WorkspaceID,
+				Grade:        review.Grade,
+				EaseFactor:   review.EaseFactor,
+				IntervalDays: review.IntervalDays,
+				DueAt:        review.DueAt,
+			}
+		}
+		if len(reviewModels) > 0 {
+			if err := upsert().CreateInBatches(&reviewModels, 100).Error; err != nil {
+				return fmt.Errorf("failed to restore study reviews: %w", err)
+			}
+		}
+		result.Reviews = len(reviewModels)
+
+		quizModels := make([]models.Quiz, len(quizzes))
+		for i, quiz := range quizzes {
+			// Archives from before quizzes were scoped to workspaces have no workspace
+			if quiz.WorkspaceID == "" {
+				quiz.WorkspaceID = utils.DefaultWorkspaceID
+			}
+			quizModels[i] = models.Quiz{
+				Model:         gorm.Model{ID: quiz.ID, CreatedAt: quiz.CreatedAt, UpdatedAt: quiz.UpdatedAt},
+				PDFID:         quiz.PDFID,
+				WorkspaceID:   quiz.WorkspaceID,
+				Title:         quiz.Title,
+				Language:      quiz.Language,
+				ModelName:     quiz.ModelName,
+				PromptVersion: quiz.PromptVersion,
+			}
+		}
+		if len(quizModels) > 0 {
+			if err := upsert().CreateInBatches(&quizModels, 100).Error; err != nil {
+				return fmt.Errorf("failed to restore quizzes: %w", err)
+			}
+		}
+		result.Quizzes = len(quizModels)
+
+		questionModels := make([]models.QuizQuestion, len(questions))
+		for i, q := range questions {
+			questionModels[i] = models.QuizQuestion{
+				Model:         gorm.Model{ID: q.ID, CreatedAt: q.CreatedAt, UpdatedAt: q.UpdatedAt},
+				QuizID:        q.QuizID,
+				Position:      q.Position,
+				Type:          q.Type,
+				Question:      q.Question,
+				Options:       q.Options,
+				CorrectOption: q.CorrectOption,
+				Answer:        q.Answer,
+				Explanation:   q.Explanation,
+				SourcePage:    q.SourcePage,
+			}
+		}
+		if len(questionModels) > 0 {
+			if err := upsert().CreateInBatches(&questionModels, 100).Error; err != nil {
+				return fmt.Errorf("failed to restore quiz questions: %w", err)
+			}
+		}
+		result.Questions = len(questionModels)
+
+		attemptModels := make([]models.QuizAttempt, len(attempts))
+		for i, attempt := range attempts {
+			attemptModels[i] = models.QuizAttempt{
+				Model:        gorm.Model{ID: attempt.ID, CreatedAt: attempt.CreatedAt, UpdatedAt: attempt.UpdatedAt},
+				QuizID:       attempt.QuizID,
+				UserID:       attempt.UserID,
+				WorkspaceID:  attempt.WorkspaceID,
+				CorrectCount: attempt.CorrectCount,
+				Total:        attempt.Total,
+				Score:        attempt.Score,
+			}
+		}
+		if len(attemptModels) > 0 {
+			if err := upsert().CreateInBatches(&attemptModels, 100).Error; err != nil {
+				return fmt.Errorf("failed to restore quiz attempts: %w", err)
+			}
+		}
+		result.Attempts = len(attemptModels)
+
+		answerModels := make([]models.QuizAnswer, len(answers))
+		for i, answer := range answers {
+			answerModels[i] = models.QuizAnswer{
+				Model:          gorm.Model{ID: answer.ID, CreatedAt: answer.CreatedAt, UpdatedAt: answer.UpdatedAt},
+				AttemptID:      answer.AttemptID,
+				QuestionID:     answer.QuestionID,
+				SelectedOption: answer.SelectedOption,
+				Response:       answer.Response,
+				Correct:        answer.Correct,
+				Similarity:     answer.Similarity,
+			}
+		}
+		if len(answerModels) > 0 {
+			if err := upsert().CreateInBatches(&answerModels, 100).Error; err != nil {
+				return fmt.Errorf("failed to restore quiz answers: %w", err)
+			}
+		}
+		result.Answers = len(answerModels)
+
+		termModels := make([]models.GlossaryTerm, len(terms))
+		for i, term := range terms {
+			termModels[i] = models.GlossaryTerm{
+				Model:          gorm.Model{ID: term.ID, CreatedAt: term.CreatedAt, UpdatedAt: term.UpdatedAt},
+				PDFID:          term.PDFID,
+				Term:           term.Term,
+				NormalizedTerm: term.NormalizedTerm,
+				Definition:     term.Definition,
+				Page:           term.Page,
+				Language:       term.Language,
+				ModelName:      term.ModelName,
+				PromptVersion:  term.PromptVersion,
+			}
+		}
+		if _, err := matchNaturalKeys(tx, "glossary_terms", len(termModels), func(i int) (*uint, map[string]interface{}) {
+			term := &termModels[i]
+			return &term.ID, map[string]interface{}{"pdf_id": term.PDFID, "normalized_term": term.NormalizedTerm}
+		}); err != nil {
+			return err
+		}
+		if len(termModels) > 0 {
+			if err := upsert().CreateInBatches(&termModels, 100).Error; err != nil {
+				return fmt.Errorf("failed to restore glossary terms: %w", err)
+			}
+		}
+		result.GlossaryTerms = len(termModels)
+
+		outlineModels := make([]models.DocumentOutline, len(outlines))
+		for i, outline := range outlines {
+			outlineModels[i] = models.DocumentOutline{
+				Model:         gorm.Model{ID: outline.ID, CreatedAt: outline.CreatedAt, UpdatedAt: outline.UpdatedAt},
+				PDFID:         outline.PDFID,
+				Source:        outline.Source,
+				Sections:      outline.Sections,
+				Language:      outline.Language,
+				ModelName:     outline.ModelName,
+				PromptVersion: outline.PromptVersion,
+			}
+		}
+		if _, err := matchNaturalKeys(tx, "document_outlines", len(outlineModels), func(i int) (*uint, map[string]interface{}) {
+			outline := &outlineModels[i]
+			return &outline.ID, map[string]interface{}{"pdf_id": outline.PDFID}
+		}); err != nil {
+			return err
+		}
+		if len(outlineModels) > 0 {
+			if err := upsert().CreateInBatches(&outlineModels, 100).Error; err != nil {
+				return fmt.Errorf("failed to restore document outlines: %w", err)
+			}
+		}
+		result.Outlines = len(outlineModels)
+
+		topicModels := make([]models.Topic, len(topicList))
+		for i, topic := range topicList {
+			topicModels[i] = models.Topic{
+				Model:         gorm.Model{ID: topic.ID, CreatedAt: topic.CreatedAt, UpdatedAt: topic.UpdatedAt},
+				Label:         topic.Label,
+				Description:   topic.Description,
+				Size:          topic.Size,
+				ModelName:     topic.ModelName,
+				PromptVersion: topic.PromptVersion,
+			}
+		}
+		if len(topicModels) > 0 {
+			if err := upsert().CreateInBatches(&topicModels, 100).Error; err != nil {
+				return fmt.Errorf("failed to restore topics: %w", err)
+			}
+		}
+		result.Topics = len(topicModels)
+
+		memberModels := make([]models.TopicMember, len(members))
+		for i, member := range members {
+			memberModels[i] = models.TopicMember{
+				Model:   gorm.Model{ID: member.ID, CreatedAt: member.CreatedAt, UpdatedAt: member.UpdatedAt},
+				TopicID: member.TopicID,
+				PDFID:   member.PDFID,
+				Score:   member.Score,
+			}
+		}
+		if _, err := matchNaturalKeys(tx, "topic_members", len(memberModels), func(i int) (*uint, map[string]interface{}) {
+			member := &memberModels[i]
+			return &member.ID, map[string]interface{}{"topic_id": member.TopicID, "pdf_id": member.PDFID}
+		}); err != nil {
+			return err
+		}
+		if len(memberModels) > 0 {
+			if err := upsert().CreateInBatches(&memberModels, 100).Error; err != nil {
+				return fmt.Errorf("failed to restore topic members: %w", err)
+			}
+		}
+		result.TopicMembers = len(memberModels)
+
+		// The summary insert trigger rewrites the PDFs' denormalized fields, so put the archived values back
+		for _, p := range pdfs {
+			if err := tx.Model(&models.PDF{}).Where("id = ?", p.ID).UpdateColumns(map[string]interface{}{
+				"summary":           p.Summary,
+				"style":             p.Style,
+				"language":          p.Language,
+				"summary_time":      p.SummaryTime,
+				"summary_version":   p.SummaryVersion,
+				"pinned_summary_id": p.PinnedSummaryID,
+				"updated_at":        p.UpdatedAt,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to restore PDF %d: %w", p.ID, err)
+			}
+		}
+
+		// Keep sequences ahead of the restored IDs
+		for _, table := range []string{
+			"pdfs", "summaries", "summary_feedbacks", "flashcards", "summary_sources",
+			"study_items", "study_schedules", "study_reviews",
+			"quizzes", "quiz_questions", "quiz_attempts", "quiz_answers", "glossary_terms", "document_outlines",
+			"topics", "topic_members",
+		} {
+			if err := tx.Exec(fmt.Sprintf(
+				"SELECT setval(pg_get_serial_sequence('%s', 'id'), COALESCE((SELECT MAX(id) FROM %s), 1))",
+				table, table,
+			)).Error; err != nil {
+				return fmt.Errorf("failed to reset %s sequence: %w", table, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Files are restored after the rows commit; existing files are left untouched
+	for _, p := range pdfs {
+		exists, err := utils.StoredFileExists(db.Statement.Context, p.Filename)
+		if err != nil {
+			return result, fmt.Errorf("failed to check file %s: %w", p.Filename, err)
+		}
+		if exists {
+			result.FilesSkipped++
+			continue
+		}
+
+		file, err := os.Open(filepath.Join(dir, FilesDir, p.Filename))
+		if os.IsNotExist(err) {
+			fmt.Printf("Warning: File %s is not in the backup\n", p.Filename)
+			continue
+		} else if err != nil {
+			return result, fmt.Errorf("failed to open file %s: %w", p.Filename, err)
+		}
+
+		info, _ := file.Stat()
+		err = utils.SaveStoredFile(db.Statement.Context, p.Filename, file, info.Size())
+		file.Close()
+		if err != nil {
+			return result, fmt.Errorf("failed to restore file %s: %w", p.Filename, err)
+		}
+		result.FilesRestored++
+	}
+
+	return result, nil
+}
+
+// checkConflicts refuses to overwrite rows whose IDs are used by unrelated data
+func checkConflicts(db *gorm.DB, pdfs []PDFRecord, summaries []SummaryRecord, feedback []FeedbackRecord, flashcards []FlashcardRecord, studyItems []StudyItemRecord, quizzes []QuizRecord, terms []GlossaryTermRecord, outlines []OutlineRecord, members []TopicMemberRecord) error {
+	for _, p := range pdfs {
+		var existing models.PDF
+		if err := db.Unscoped().Select("id", "filename").Where("id = ?", p.ID).Limit(1).Find(&existing).Error; err != nil {
+			return fmt.Errorf("failed to check PDF %d: %w", p.ID, err)
+		}
+		if existing.ID != 0 && existing.Filename != p.Filename {
+			return fmt.Errorf("PDF %d already exists with a different file (%s); use force to overwrite", p.ID, existing.Filename)
+		}
+	}
+
+	for _, s := range summaries {
+		var existing models.Summaries
+		if err := db.Unscoped().Select("id", "pdf_id").Where("id = ?", s.ID).Limit(1).Find(&existing).Error; err != nil {
+			return fmt.Errorf("failed to check summary %d: %w", s.ID, err)
+		}
+		if existing.ID != 0 && !samePDF(existing.PDFID, s.PDFID) {
+			return fmt.Errorf("summary %d already exists for another PDF; use force to overwrite", s.ID)
+		}
+	}
+
+	for _, f := range feedback {
+		var existing models.SummaryFeedback
+		if err := db.Unscoped().Select("id", "summary_id").Where("id = ?", f.ID).Limit(1).Find(&existing).Error; err != nil {
+			return fmt.Errorf("failed to check feedback %d: %w", f.ID, err)
+		}
+		if existing.ID != 0 && existing.SummaryID != f.SummaryID {
+			return fmt.Errorf("feedback %d already exists for summary %d; use force to overwrite", f.ID, existing.SummaryID)
+		}
+	}
+
+	for _, f := range flashcards {
+		var existing models.Flashcard
+		if err := db.Unscoped().Select("id", "pdf_id").Where("id = ?", f.ID).Limit(1).Find(&existing).Error; err != nil {
+			return fmt.Errorf("failed to check flashcard %d: %w", f.ID, err)
+		}
+		if existing.ID != 0 && existing.PDFID != f.PDFID {
+			return fmt.Errorf("flashcard %d already exists for PDF %d; use force to overwrite", f.ID, existing.PDFID)
+		}
+	}
+
+	for _, item := range studyItems {
+		var existing models.StudyItem
+		if err := db.Unscoped().Select("id", "summary_id").Where("id = ?", item.ID).Limit(1).Find(&existing).Error; err != nil {
+			return fmt.Errorf("failed to check study item %d: %w", item.ID, err)
+		}
+		if existing.ID != 0 && existing.SummaryID != item.SummaryID {
+			return fmt.Errorf("study item %d already exists for summary %d; use force to overwrite", item.ID, existing.SummaryID)
+		}
+	}
+
+	for _, quiz := range quizzes {
+		var existing models.Quiz
+		if err := db.Unscoped().Select("id", "pdf_id").Where("id = ?", quiz.ID).Limit(1).Find(&existing).Error; err != nil {
+			return fmt.Errorf("failed to check quiz %d: %w", quiz.ID, err)
+		}
+		if existing.ID != 0 && existing.PDFID != quiz.PDFID {
+			return fmt.Errorf("quiz %d already exists for PDF %d; use force to overwrite", quiz.ID, existing.PDFID)
+		}
+	}
+
+	for _, term := range terms {
+		var existing models.GlossaryTerm
+		if err := db.Unscoped().Select("id", "pdf_id").Where("id = ?", term.ID).Limit(1).Find(&existing).Error; err != nil {
+			return fmt.Errorf("failed to check glossary term %d: %w", term.ID, err)
+		}
+		if existing.ID != 0 && existing.PDFID != term.PDFID {
+			return fmt.Errorf("glossary term %d already exists for PDF %d; use force to overwrite", term.ID, existing.PDFID)
+		}
+	}
+
+	for _, outline := range outlines {
+		var existing models.DocumentOutline
+		if err := db.Unscoped().Select("id", "pdf_id").Where("id = ?", outline.ID).Limit(1).Find(&existing).Error; err != nil {
+			return fmt.Errorf("failed to check document outline %d: %w", outline.ID, err)
+		}
+		if existing.ID != 0 && existing.PDFID != outline.PDFID {
+			return fmt.Errorf("document outline %d already exists for PDF %d; use force to overwrite", outline.ID, existing.PDFID)
+		}
+	}
+
+	for _, member := range members {
+		var existing models.TopicMember
+		if err := db.Unscoped().Select("id", "pdf_id").Where("id = ?", member.ID).Limit(1).Find(&existing).Error; err != nil {
+			return fmt.Errorf("failed to check topic member %d: %w", member.ID, err)
+		}
+		if existing.ID != 0 && existing.PDFID != member.PDFID {
+			return fmt.Errorf("topic member %d already exists for PDF %d; use force to overwrite", member.ID, existing.PDFID)
+		}
+	}
+
+	return nil
+}
+
+// matchNaturalKeys gives the n archived rows of table the ID of an existing row with the same
+// natural key (the columns of its unique index), so that rows created independently in both
+// databases are merged by the upsert instead of violating that index. row returns a row's ID,
+// which is updated in place, and its key. An archived row whose own ID is taken over this way
+// moves to a new ID. The returned map holds every moved row's archived and new ID.
+func matchNaturalKeys(tx *gorm.DB, table string, n int, row func(i int) (*uint, map[string]interface{})) (map[uint]uint, error) {
+	moved := make(map[uint]uint)
+	taken := make(map[uint]bool)
+	var maxID uint
+	for i := 0; i < n; i++ {
+		id, key := row(i)
+		maxID = max(maxID, *id)
+
+		// Soft-deleted rows still hold their unique index entries
+		var existing []uint
+		if err := tx.Table(table).Where(key).Limit(1).Pluck("id", &existing).Error; err != nil {
+			return nil, fmt.Errorf("failed to match %s %d: %w", table, *id, err)
+		}
+		if len(existing) > 0 && existing[0] != *id {
+			moved[*id] = existing[0]
+			taken[existing[0]] = true
+		}
+	}
+	if len(moved) == 0 {
+		return moved, nil
+	}
+
+	// New IDs come from the sequence, moved past every archived and existing ID first
+	sequenceReady := false
+	for i := 0; i < n; i++ {
+		id, _ := row(i)
+		if target, ok := moved[*id]; ok {
+			*id = target
+			continue
+		}
+		if !taken[*id] {
+			continue
+		}
+		if !sequenceReady {
+			if err := tx.Exec(fmt.Sprintf(
+				"SELECT setval(pg_get_serial_sequence('%s', 'id'), GREATEST(COALESCE((SELECT MAX(id) FROM %s), 1), ?))",
+				table, table,
+			), maxID).Error; err != nil {
+				return nil, fmt.Errorf("failed to advance %s sequence: %w", table, err)
+			}
+			sequenceReady = true
+		}
+		var next uint
+		if err := tx.Raw(fmt.Sprintf("SELECT nextval(pg_get_serial_sequence('%s', 'id'))", table)).Scan(&next).Error; err != nil {
+			return nil, fmt.Errorf("failed to allocate a %s ID: %w", table, err)
+		}
+		moved[*id] = next
+		*id = next
+	}
+	return moved, nil
+}
+
+// remapID returns the new ID of a row moved by matchNaturalKeys, or id when it kept its ID
+func remapID(moved map[uint]uint, id uint) uint {
+	if target, ok := moved[id]; ok {
+		return target
+	}
+	return id
+}
+
+// samePDF reports whether two optional PDF IDs refer to the same PDF (or both to none)
+func samePDF(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// extract unpacks a gzipped tar archive into dir, rejecting paths that escape it
+func extract(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("invalid backup archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid backup archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(header.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid path in backup archive: %s", header.Name)
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+
+		file, err := os.Create(target)
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %w", name, err)
+		}
+		_, err = io.Copy(file, tr)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %w", name, err)
+		}
+	}
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// readJSONL decodes one JSON value per line into the slice pointed to by target.
+// A missing file is treated as empty.
+func readJSONL(path string, target interface{}) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Decode into a JSON array so the slice type drives decoding
+	var lines []json.RawMessage
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	for {
+		var line json.RawMessage
+		if err := decoder.Decode(&line); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		lines = append(lines, line)
+	}
+
+	array, err := json.Marshal(lines)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(array, target)
+}
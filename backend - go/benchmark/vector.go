@@ -0,0 +1,290 @@
+package benchmark
+
+import (
+	"backend-go/utils"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/pgvector/pgvector-go"
+	"gorm.io/gorm"
+)
+
+// benchTable is created as a temporary table, so benchmarks never touch real summaries
+const benchTable = "bench_summaries"
+
+// Config describes a vector retrieval benchmark
+type Config struct {
+	N         int    // Synthetic vectors to seed
+	Dims      int    // Vector dimensions; 1024 matches the summaries.embedding column
+	Clusters  int    // Vectors are drawn around this many centres so neighbourhoods are realistic
+	PDFs      int    // Distinct pdf_id values the vectors are spread over
+	Filter    int    // PDFs each query is restricted to, like chat with selected sources (0 = no filter)
+	Queries   int    // Queries measured per setting
+	K         int    // Neighbours retrieved per query; recall is measured at K
+	IndexType string // utils.VectorIndexHNSW or utils.VectorIndexIVFFlat
+	EfSearch  []int  // hnsw.ef_search values to compare
+	Probes    []int  // ivfflat.probes values to compare
+	Seed      int64
+}
+
+// Run is the outcome of the queries for one search setting
+type Run struct {
+	Label  string
+	Recall float64 // Mean fraction of the exact top K that was returned
+	Mean   time.Duration
+	P50    time.Duration
+	P95    time.Duration
+}
+
+// Report is the outcome of a benchmark
+type Report struct {
+	Seed  time.Duration // Time to insert the synthetic vectors
+	Build time.Duration // Time to build the index
+	Exact Run           // Sequential scan, the behaviour without an index
+	Runs  []Run
+}
+
+type query struct {
+	vector pgvector.Vector
+	pdfIDs []int
+	exact  map[int64]bool
+}
+
+// RunVector seeds cfg.N synthetic vectors into a temporary table, builds the configured
+// index and measures latency and recall of the chat retrieval query
+// (ORDER BY embedding <=> ? LIMIT k, optionally filtered by pdf_id) for each setting
+func RunVector(db *gorm.DB, cfg Config) (*Report, error) {
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	report := &Report{}
+
+	// The temporary table only exists on one connection, so pin it for the whole run
+	err := db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec(fmt.Sprintf(
+			"CREATE TEMPORARY TABLE %s (id bigserial PRIMARY KEY, pdf_id bigint NOT NULL, embedding vector(%d))",
+			benchTable, cfg.Dims,
+		)).Error; err != nil {
+			return fmt.Errorf("failed to create benchmark table: %w", err)
+		}
+		defer conn.Exec("DROP TABLE IF EXISTS " + benchTable)
+
+		centres := make([][]float64, cfg.Clusters)
+		for i := range centres {
+			centres[i] = randomVector(rng, cfg.Dims)
+		}
+
+		type row struct {
+			PDFID     int
+			Embedding pgvector.Vector
+		}
+		vectors := make([][]float64, cfg.N)
+		pdfIDs := make([]int, cfg.N)
+		start := time.Now()
+		for offset := 0; offset < cfg.N; offset += 500 {
+			batch := make([]row, 0, 500)
+			for i := offset; i < cfg.N && i < offset+500; i++ {
+				vectors[i] = nearby(rng, centres[rng.Intn(len(centres))])
+				pdfIDs[i] = rng.Intn(cfg.PDFs) + 1
+				batch = append(batch, row{PDFID: pdfIDs[i], Embedding: toVector(vectors[i])})
+			}
+			if err := conn.Table(benchTable).Create(&batch).Error; err != nil {
+				return fmt.Errorf("failed to seed vectors: %w", err)
+			}
+		}
+		report.Seed = time.Since(start)
+
+		// Ground truth is computed in Go; IDs are 1-based insertion order
+		queries := make([]query, cfg.Queries)
+		for q := range queries {
+			v := nearby(rng, centres[rng.Intn(len(centres))])
+			queries[q] = query{vector: toVector(v)}
+			allowed := map[int]bool{}
+			for len(queries[q].pdfIDs) < cfg.Filter && len(queries[q].pdfIDs) < cfg.PDFs {
+				id := rng.Intn(cfg.PDFs) + 1
+				if !allowed[id] {
+					allowed[id] = true
+					queries[q].pdfIDs = append(queries[q].pdfIDs, id)
+				}
+			}
+			queries[q].exact = exactNeighbours(vectors, pdfIDs, v, allowed, cfg.K)
+		}
+
+		// Without an index the planner can only scan; this is the pre-index chat path
+		exact, err := measure(conn, "exact (sequential scan)", queries, cfg.K, []string{"SET LOCAL enable_indexscan = off"})
+		if err != nil {
+			return err
+		}
+		report.Exact = exact
+
+		start = time.Now()
+		if err := conn.Exec(utils.VectorIndexSQL(benchTable, "embedding", cfg.IndexType)).Error; err != nil {
+			return fmt.Errorf("failed to build %s index: %w", cfg.IndexType, err)
+		}
+		report.Build = time.Since(start)
+		if err := conn.Exec("ANALYZE " + benchTable).Error; err != nil {
+			return fmt.Errorf("failed to analyze benchmark table: %w", err)
+		}
+
+		settings := cfg.EfSearch
+		if cfg.IndexType == utils.VectorIndexIVFFlat {
+			settings = cfg.Probes
+		}
+		for _, value := range settings {
+			opts := utils.VectorSearchOptions{EfSearch: value}
+			label := fmt.Sprintf("hnsw ef_search=%d", value)
+			if cfg.IndexType == utils.VectorIndexIVFFlat {
+				opts = utils.VectorSearchOptions{Probes: value}
+				label = fmt.Sprintf("ivfflat probes=%d", value)
+			}
+			run, err := measure(conn, label, queries, cfg.K, opts.Settings())
+			if err != nil {
+				return err
+			}
+			report.Runs = append(report.Runs, run)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// measure runs every query in its own transaction with settings applied and times the query alone
+func measure(conn *gorm.DB, label string, queries []query, k int, settings []string) (Run, error) {
+	durations := make([]time.Duration, 0, len(queries))
+	recall := 0.0
+
+	for _, q := range queries {
+		var ids []int64
+		var elapsed time.Duration
+		err := conn.Transaction(func(tx *gorm.DB) error {
+			for _, statement := range settings {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+
+			sql := "SELECT id FROM " + benchTable + " WHERE embedding IS NOT NULL"
+			args := []interface{}{}
+			if len(q.pdfIDs) > 0 {
+				sql += " AND pdf_id IN ?"
+				args = append(args, q.pdfIDs)
+			}
+			sql += " ORDER BY embedding <=> ? LIMIT ?"
+			args = append(args, q.vector, k)
+
+			start := time.Now()
+			err := tx.Raw(sql, args...).Scan(&ids).Error
+			elapsed = time.Since(start)
+			return err
+		})
+		if err != nil {
+			return Run{}, fmt.Errorf("%s: query failed: %w", label, err)
+		}
+
+		durations = append(durations, elapsed)
+		if len(q.exact) > 0 {
+			found := 0
+			for _, id := range ids {
+				if q.exact[id] {
+					found++
+				}
+			}
+			recall += float64(found) / float64(len(q.exact))
+		} else {
+			recall++
+		}
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+
+	return Run{
+		Label:  label,
+		Recall: recall / float64(len(queries)),
+		Mean:   total / time.Duration(len(durations)),
+		P50:    percentile(durations, 0.50),
+		P95:    percentile(durations, 0.95),
+	}, nil
+}
+
+// exactNeighbours returns the IDs of the k vectors closest to v by cosine distance,
+// among those whose pdf_id is allowed (all when allowed is empty)
+func exactNeighbours(vectors [][]float64, pdfIDs []int, v []float64, allowed map[int]bool, k int) map[int64]bool {
+	type candidate struct {
+		id         int64
+		similarity float64
+	}
+	candidates := make([]candidate, 0, len(vectors))
+	for i, vector := range vectors {
+		if len(allowed) > 0 && !allowed[pdfIDs[i]] {
+			continue
+		}
+		candidates = append(candidates, candidate{id: int64(i + 1), similarity: dot(vector, v)})
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].similarity > candidates[j].similarity })
+
+	exact := make(map[int64]bool, k)
+	for i := 0; i < k && i < len(candidates); i++ {
+		exact[candidates[i].id] = true
+	}
+	return exact
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(math.Ceil(p*float64(len(sorted))))-1]
+}
+
+// randomVector returns a random unit vector
+func randomVector(rng *rand.Rand, dims int) []float64 {
+	v := make([]float64, dims)
+	for i := range v {
+		v[i] = rng.NormFloat64()
+	}
+	return normalize(v)
+}
+
+// nearby returns a unit vector scattered around centre
+func nearby(rng *rand.Rand, centre []float64) []float64 {
+	v := make([]float64, len(centre))
+	noise := 1 / math.Sqrt(float64(len(centre)))
+	for i, x := range centre {
+		v[i] = x + rng.NormFloat64()*noise
+	}
+	return normalize(v)
+}
+
+func normalize(v []float64) []float64 {
+	norm := math.Sqrt(dot(v, v))
+	for i := range v {
+		v[i] /= norm
+	}
+	return v
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+func toVector(v []float64) pgvector.Vector {
+	values := make([]float32, len(v))
+	for i, x := range v {
+		values[i] = float32(x)
+	}
+	return pgvector.NewVector(values)
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"backend-go/backup"
+	"backend-go/benchmark"
+	"backend-go/config"
+	"backend-go/topics"
+	"backend-go/utils"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// commands are the CLI subcommands, which run instead of the server
+var commands = map[string]func(db *gorm.DB, args []string) error{
+	"backup":        runBackup,
+	"restore":       runRestore,
+	"topics":        runTopics,
+	"bench-vectors": runBenchVectors,
+}
+
+// isCommand reports whether args name a CLI subcommand
+func isCommand(args []string) bool {
+	return len(args) > 0 && commands[args[0]] != nil
+}
+
+// runCommand runs a CLI subcommand such as "backup" or "restore".
+// It reports false when args do not name a subcommand so the server starts instead.
+func runCommand(db *gorm.DB, args []string) (bool, error) {
+	if !isCommand(args) {
+		return false, nil
+	}
+	return true, commands[args[0]](db, args[1:])
+}
+
+// runConfig runs "config print", which shows the effective configuration with secrets masked
+// and then reports whether it is valid
+func runConfig(cfg *config.Config, args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		return fmt.Errorf("usage: config print")
+	}
+	if err := cfg.Print(os.Stdout); err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return nil
+}
+
+func runBackup(db *gorm.DB, args []string) error {
+	flags := flag.NewFlagSet("backup", flag.ExitOnError)
+	output := flags.String("o", fmt.Sprintf("backup-%s.tar.gz", time.Now().Format("20060102-150405")), "archive file to write")
+	flags.Parse(args)
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", *output, err)
+	}
+	defer file.Close()
+
+	manifest, err := backup.Export(db, file)
+	if err != nil {
+		os.Remove(*output)
+		return err
+	}
+
+	fmt.Printf("✓ Backup written to %s\n", *output)
+	fmt.Printf("  PDFs: %d, summaries: %d, embeddings: %d, feedback: %d, flashcards: %d, summary sources: %d, files: %d\n",
+		manifest.Counts["pdfs"], manifest.Counts["summaries"], manifest.Counts["embeddings"],
+		manifest.Counts["summary_feedbacks"], manifest.Counts["flashcards"], manifest.Counts["summary_sources"],
+		manifest.Counts["files"])
+	fmt.Printf("  Study items: %d, schedules: %d, reviews: %d\n",
+		manifest.Counts["study_items"], manifest.Counts["study_schedules"], manifest.Counts["study_reviews"])
+	fmt.Printf("  Quizzes: %d, questions: %d, attempts: %d, answers: %d, glossary terms: %d, outlines: %d\n",
+		manifest.Counts["quizzes"], manifest.Counts["quiz_questions"], manifest.Counts["quiz_attempts"], manifest.Counts["quiz_answers"],
+		manifest.Counts["glossary_terms"], manifest.Counts["document_outlines"])
+	fmt.Printf("  Topics: %d, topic assignments: %d\n", manifest.Counts["topics"], manifest.Counts["topic_members"])
+	if len(manifest.MissingFiles) > 0 {
+		fmt.Printf("Warning: %d files were missing from storage\n", len(manifest.MissingFiles))
+	}
+	return nil
+}
+
+func runRestore(db *gorm.DB, args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	force := flags.Bool("force", false, "overwrite rows whose IDs are used by different data")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: restore [-force] <archive.tar.gz>")
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", flags.Arg(0), err)
+	}
+	defer file.Close()
+
+	result, err := backup.Restore(db, file, *force)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Restored %s\n", flags.Arg(0))
+	fmt.Printf("  PDFs: %d, summaries: %d, embeddings: %d, feedback: %d, flashcards: %d, summary sources: %d\n",
+		result.PDFs, result.Summaries, result.Embeddings, result.Feedback, result.Flashcards, result.Sources)
+	fmt.Printf("  Study items: %d, schedules: %d, reviews: %d\n", result.StudyItems, result.Schedules, result.Reviews)
+	fmt.Printf("  Quizzes: %d, questions: %d, attempts: %d, answers: %d, glossary terms: %d, outlines: %d\n",
+		result.Quizzes, result.Questions, result.Attempts, result.Answers, result.GlossaryTerms, result.Outlines)
+	fmt.Printf("  Topics: %d, topic assignments: %d\n", result.Topics, result.TopicMembers)
+	fmt.Printf("  Files restored: %d, already present: %d\n", result.FilesRestored, result.FilesSkipped)
+	return nil
+}
+
+func runTopics(db *gorm.DB, args []string) error {
+	flags := flag.NewFlagSet("topics", flag.ExitOnError)
+	k := flags.Int("k", 0, "number of topics (0 picks one from the library size)")
+	flags.Parse(args)
+
+	if *k < 0 {
+		return fmt.Errorf("k cannot be negative")
+	}
+
+	result, err := topics.Rebuild(db, *k)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Clustered %d PDFs into %d topics (%d labeled by AI)\n", result.Documents, result.Topics, result.Labeled)
+	return nil
+}
+
+func runBenchVectors(db *gorm.DB, args []string) error {
+	flags := flag.NewFlagSet("bench-vectors", flag.ExitOnError)
+	n := flags.Int("n", 10000, "synthetic vectors to seed")
+	dims := flags.Int("dims", 1024, "vector dimensions")
+	clusters := flags.Int("clusters", 50, "clusters the vectors are drawn around")
+	pdfs := flags.Int("pdfs", 100, "distinct PDF IDs the vectors belong to")
+	filter := flags.Int("filter", 0, "PDFs each query is restricted to, as in chat (0 = no filter)")
+	queries := flags.Int("queries", 100, "queries per setting")
+	k := flags.Int("k", 10, "neighbours per query (recall@k)")
+	indexType := flags.String("index", utils.VectorIndexType(), "index type: hnsw or ivfflat")
+	efSearch := flags.String("ef-search", "10,40,100,200", "comma-separated hnsw.ef_search values")
+	probes := flags.String("probes", "1,5,10,20", "comma-separated ivfflat.probes values")
+	seed := flags.Int64("seed", 1, "random seed")
+	flags.Parse(args)
+
+	if *n < 1 || *dims < 1 || *clusters < 1 || *pdfs < 1 || *queries < 1 || *k < 1 || *filter < 0 {
+		return fmt.Errorf("n, dims, clusters, pdfs, queries and k must be positive")
+	}
+	if *indexType != utils.VectorIndexHNSW && *indexType != utils.VectorIndexIVFFlat {
+		return fmt.Errorf("index must be hnsw or ivfflat")
+	}
+	efValues, err := parseIntList(*efSearch)
+	if err != nil {
+		return fmt.Errorf("invalid -ef-search: %w", err)
+	}
+	probeValues, err := parseIntList(*probes)
+	if err != nil {
+		return fmt.Errorf("invalid -probes: %w", err)
+	}
+
+	fmt.Printf("Seeding %d vectors (%d dims) and benchmarking the %s index...\n", *n, *dims, *indexType)
+	report, err := benchmark.RunVector(db, benchmark.Config{
+		N:         *n,
+		Dims:      *dims,
+		Clusters:  *clusters,
+		PDFs:      *pdfs,
+		Filter:    *filter,
+		Queries:   *queries,
+		K:         *k,
+		IndexType: *indexType,
+		EfSearch:  efValues,
+		Probes:    probeValues,
+		Seed:      *seed,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Seeded in %s, index built in %s\n", report.Seed.Round(time.Millisecond), report.Build.Round(time.Millisecond))
+	fmt.Printf("  %-26s %10s %10s %10s %10s\n", "setting", fmt.Sprintf("recall@%d", *k), "mean", "p50", "p95")
+	for _, run := range append([]benchmark.Run{report.Exact}, report.Runs...) {
+		fmt.Printf("  %-26s %10.3f %10s %10s %10s\n", run.Label, run.Recall,
+			run.Mean.Round(time.Microsecond), run.P50.Round(time.Microsecond), run.P95.Round(time.Microsecond))
+	}
+	return nil
+}
+
+// parseIntList parses a comma-separated list of positive integers such as "10,40,100"
+func parseIntList(value string) ([]int, error) {
+	var values []int
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid value: %s", part)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
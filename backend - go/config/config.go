@@ -0,0 +1,470 @@
+// Package config loads the server configuration from defaults, an optional YAML or TOML
+// file, environment variables and command-line flags, in increasing order of precedence.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the typed configuration of the server and its CLI commands
+type Config struct {
+	Server      Server      `yaml:"server" toml:"server"`
+	Database    Database    `yaml:"database" toml:"database"`
+	MinIO       MinIO       `yaml:"minio" toml:"minio"`
+	PythonAPI   PythonAPI   `yaml:"python_api" toml:"python_api"`
+	Embeddings  Embeddings  `yaml:"embeddings" toml:"embeddings"`
+	VectorIndex VectorIndex `yaml:"vector_index" toml:"vector_index"`
+	Languages   Languages   `yaml:"languages" toml:"languages"`
+	Quiz        Quiz        `yaml:"quiz" toml:"quiz"`
+	Admin       Admin       `yaml:"admin" toml:"admin"`
+
+	// File is the configuration file that was loaded, if any
+	File string `yaml:"-" toml:"-"`
+}
+
+// Server configures the HTTP listener
+type Server struct {
+	Address     string   `yaml:"address" toml:"address"`           // LISTEN_ADDRESS
+	CORSOrigins []string `yaml:"cors_origins" toml:"cors_origins"` // CORS_ORIGINS, comma-separated
+
+	// ShutdownTimeout bounds how long a stopping server waits for in-flight requests and
+	// background jobs (SHUTDOWN_TIMEOUT, e.g. "30s")
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+}
+
+// Database configures the Postgres connection
+type Database struct {
+	URL string `yaml:"url" toml:"url"` // DATABASE_URL, a URL or key=value DSN
+
+	// StatementTimeout makes Postgres cancel any statement of the server that runs longer
+	// (DATABASE_STATEMENT_TIMEOUT); migrations and CLI commands are not limited
+	StatementTimeout time.Duration `yaml:"statement_timeout" toml:"statement_timeout"`
+}
+
+// MinIO configures the object storage for uploaded files
+type MinIO struct {
+	Endpoint  string `yaml:"endpoint" toml:"endpoint"`     // MINIO_ENDPOINT, host:port
+	AccessKey string `yaml:"access_key" toml:"access_key"` // MINIO_ACCESS_KEY
+	SecretKey string `yaml:"secret_key" toml:"secret_key"` // MINIO_SECRET_KEY
+	UseSSL    bool   `yaml:"use_ssl" toml:"use_ssl"`       // MINIO_USE_SSL
+	Bucket    string `yaml:"bucket" toml:"bucket"`         // MINIO_BUCKET
+
+	// Timeout bounds each storage operation, including streaming a download (MINIO_TIMEOUT)
+	Timeout time.Duration `yaml:"timeout" toml:"timeout"`
+}
+
+// PythonAPI configures the Python AI service
+type PythonAPI struct {
+	URL string `yaml:"url" toml:"url"` // PYTHON_API_URL
+
+	// Timeout bounds each attempt of a call, from connecting to reading the whole response
+	// (PYTHON_API_TIMEOUT)
+	Timeout time.Duration `yaml:"timeout" toml:"timeout"`
+
+	// MaxRetries is how often a rate-limited or unavailable call is retried (PYTHON_API_MAX_RETRIES)
+	MaxRetries int `yaml:"max_retries" toml:"max_retries"`
+
+	// After BreakerThreshold failed calls in a row to one endpoint, calls to it fail fast for
+	// BreakerCooldown before a trial call is let through (PYTHON_API_BREAKER_THRESHOLD,
+	// PYTHON_API_BREAKER_COOLDOWN)
+	BreakerThreshold int           `yaml:"breaker_threshold" toml:"breaker_threshold"`
+	BreakerCooldown  time.Duration `yaml:"breaker_cooldown" toml:"breaker_cooldown"`
+}
+
+// Embeddings configures the cache of text embeddings, which saves AI quota on repeated text
+type Embeddings struct {
+	// Model names the embedding model behind the Python API; cached embeddings are only
+	// reused for the same model, so change it when the model changes (EMBEDDING_MODEL)
+	Model string `yaml:"model" toml:"model"`
+
+	CacheSize    int  `yaml:"cache_size" toml:"cache_size"`       // EMBEDDING_CACHE_SIZE, entries kept in memory; 0 keeps none
+	PersistCache bool `yaml:"persist_cache" toml:"persist_cache"` // EMBEDDING_CACHE_PERSIST, also keep them in the embedding_cache table
+}
+
+// VectorIndex configures the approximate nearest-neighbour indexes the migration builds on
+// vector columns, and how accurately they are searched
+type VectorIndex struct {
+	Type string `yaml:"type" toml:"type"` // VECTOR_INDEX_TYPE, "hnsw" or "ivfflat"
+
+	HNSWM              int `yaml:"hnsw_m" toml:"hnsw_m"`                             // HNSW_M, links per node
+	HNSWEfConstruction int `yaml:"hnsw_ef_construction" toml:"hnsw_ef_construction"` // HNSW_EF_CONSTRUCTION, candidate list size while building
+	IVFFlatLists       int `yaml:"ivfflat_lists" toml:"ivfflat_lists"`               // IVFFLAT_LISTS, clusters built
+
+	// Search accuracy, applied per query; higher is slower but finds more of the true nearest
+	// rows, which matters for filtered queries such as chat restricted to some PDFs
+	// (HNSW_EF_SEARCH, IVFFLAT_PROBES)
+	HNSWEfSearch  int `yaml:"hnsw_ef_search" toml:"hnsw_ef_search"`
+	IVFFlatProbes int `yaml:"ivfflat_probes" toml:"ivfflat_probes"`
+}
+
+// Languages configures the languages summaries and other content can be generated in
+type Languages struct {
+	File    string `yaml:"file" toml:"file"`       // LANGUAGES_FILE, JSON array replacing the built-in languages
+	Default string `yaml:"default" toml:"default"` // DEFAULT_LANGUAGE, used when none is requested or detected
+}
+
+// Quiz configures quiz grading
+type Quiz struct {
+	// SimilarityThreshold is the embedding similarity a short answer needs to be graded
+	// correct (QUIZ_SIMILARITY_THRESHOLD)
+	SimilarityThreshold float64 `yaml:"similarity_threshold" toml:"similarity_threshold"`
+}
+
+// Admin configures the /admin endpoints
+type Admin struct {
+	Token string `yaml:"token" toml:"token"` // ADMIN_TOKEN; admin endpoints are disabled when empty
+}
+
+// Default returns the configuration used when nothing is set
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Address:         "0.0.0.0:8080",
+			CORSOrigins:     []string{"http://localhost:3000"},
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Database: Database{
+			URL:              "host=localhost user=postgres password=postgres dbname=ai_pdf_management port=5432 sslmode=disable TimeZone=Asia/Shanghai",
+			StatementTimeout: 30 * time.Second,
+		},
+		MinIO: MinIO{
+			Endpoint:  "localhost:9000",
+			AccessKey: "minioadmin",
+			SecretKey: "minioadmin",
+			Bucket:    "pdf-uploads",
+			Timeout:   time.Minute,
+		},
+		PythonAPI: PythonAPI{
+			// Summarizing a long PDF takes the AI model a while
+			URL:              "http://127.0.0.1:8000",
+			Timeout:          2 * time.Minute,
+			MaxRetries:       3,
+			BreakerThreshold: 5,
+			BreakerCooldown:  30 * time.Second,
+		},
+		Embeddings: Embeddings{
+			Model:     "custom-embedding-api",
+			CacheSize: 10000,
+		},
+		// pgvector's defaults, except ef_search, which is raised so filtered queries still find rows
+		VectorIndex: VectorIndex{
+			Type:               "hnsw",
+			HNSWM:              16,
+			HNSWEfConstruction: 64,
+			IVFFlatLists:       100,
+			HNSWEfSearch:       100,
+			IVFFlatProbes:      10,
+		},
+		Languages: Languages{
+			Default: "en",
+		},
+		Quiz: Quiz{
+			SimilarityThreshold: 0.8,
+		},
+	}
+}
+
+// Load builds the configuration from args (the command line without the program name) and
+// the environment. Flags must come before a subcommand; the remaining arguments are returned.
+// The file is taken from -config or CONFIG_FILE. Load does not validate the result.
+func Load(args []string) (*Config, []string, error) {
+	flags := flag.NewFlagSet("backend-go", flag.ContinueOnError)
+	file := flags.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML configuration file")
+	flags.String("listen", "", "address to listen on (LISTEN_ADDRESS)")
+	flags.String("cors-origins", "", "comma-separated allowed CORS origins (CORS_ORIGINS)")
+	flags.String("database-url", "", "Postgres connection URL or DSN (DATABASE_URL)")
+	flags.String("python-api-url", "", "base URL of the Python AI service (PYTHON_API_URL)")
+	flags.String("minio-endpoint", "", "MinIO host:port (MINIO_ENDPOINT)")
+	flags.String("minio-bucket", "", "MinIO bucket for uploaded files (MINIO_BUCKET)")
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	cfg := Default()
+	if *file != "" {
+		if err := cfg.loadFile(*file); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := cfg.loadEnv(); err != nil {
+		return nil, nil, err
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		value := f.Value.String()
+		switch f.Name {
+		case "listen":
+			cfg.Server.Address = value
+		case "cors-origins":
+			cfg.Server.CORSOrigins = splitList(value)
+		case "database-url":
+			cfg.Database.URL = value
+		case "python-api-url":
+			cfg.PythonAPI.URL = value
+		case "minio-endpoint":
+			cfg.MinIO.Endpoint = value
+		case "minio-bucket":
+			cfg.MinIO.Bucket = value
+		}
+	})
+
+	return cfg, flags.Args(), nil
+}
+
+// loadFile overrides the configuration with the values set in a .yaml, .yml or .toml file.
+// Unknown keys are an error so that typos do not go unnoticed.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+	case ".toml":
+		meta, err := toml.Decode(string(data), c)
+		if err != nil {
+			return fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("invalid config file %s: unknown key %q", path, undecoded[0].String())
+		}
+	default:
+		return fmt.Errorf("config file %s must end in .yaml, .yml or .toml", path)
+	}
+
+	c.File = path
+	return nil
+}
+
+// loadEnv overrides the configuration with the environment variables that are set
+func (c *Config) loadEnv() error {
+	values := map[string]*string{
+		"LISTEN_ADDRESS":    &c.Server.Address,
+		"DATABASE_URL":      &c.Database.URL,
+		"MINIO_ENDPOINT":    &c.MinIO.Endpoint,
+		"MINIO_ACCESS_KEY":  &c.MinIO.AccessKey,
+		"MINIO_SECRET_KEY":  &c.MinIO.SecretKey,
+		"MINIO_BUCKET":      &c.MinIO.Bucket,
+		"PYTHON_API_URL":    &c.PythonAPI.URL,
+		"EMBEDDING_MODEL":   &c.Embeddings.Model,
+		"VECTOR_INDEX_TYPE": &c.VectorIndex.Type,
+		"LANGUAGES_FILE":    &c.Languages.File,
+		"DEFAULT_LANGUAGE":  &c.Languages.Default,
+		"ADMIN_TOKEN":       &c.Admin.Token,
+	}
+	for name, field := range values {
+		if value := os.Getenv(name); value != "" {
+			*field = value
+		}
+	}
+
+	if value := os.Getenv("CORS_ORIGINS"); value != "" {
+		c.Server.CORSOrigins = splitList(value)
+	}
+	durations := map[string]*time.Duration{
+		"SHUTDOWN_TIMEOUT":            &c.Server.ShutdownTimeout,
+		"DATABASE_STATEMENT_TIMEOUT":  &c.Database.StatementTimeout,
+		"MINIO_TIMEOUT":               &c.MinIO.Timeout,
+		"PYTHON_API_TIMEOUT":          &c.PythonAPI.Timeout,
+		"PYTHON_API_BREAKER_COOLDOWN": &c.PythonAPI.BreakerCooldown,
+	}
+	for name, field := range durations {
+		if value := os.Getenv(name); value != "" {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("%s must be a duration such as 30s, got %q", name, value)
+			}
+			*field = duration
+		}
+	}
+
+	ints := map[string]*int{
+		"PYTHON_API_MAX_RETRIES":       &c.PythonAPI.MaxRetries,
+		"PYTHON_API_BREAKER_THRESHOLD": &c.PythonAPI.BreakerThreshold,
+		"EMBEDDING_CACHE_SIZE":         &c.Embeddings.CacheSize,
+		"HNSW_M":                       &c.VectorIndex.HNSWM,
+		"HNSW_EF_CONSTRUCTION":         &c.VectorIndex.HNSWEfConstruction,
+		"HNSW_EF_SEARCH":               &c.VectorIndex.HNSWEfSearch,
+		"IVFFLAT_LISTS":                &c.VectorIndex.IVFFlatLists,
+		"IVFFLAT_PROBES":               &c.VectorIndex.IVFFlatProbes,
+	}
+	for name, field := range ints {
+		if value := os.Getenv(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("%s must be a whole number, got %q", name, value)
+			}
+			*field = n
+		}
+	}
+
+	floats := map[string]*float64{
+		"QUIZ_SIMILARITY_THRESHOLD": &c.Quiz.SimilarityThreshold,
+	}
+	for name, field := range floats {
+		if value := os.Getenv(name); value != "" {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("%s must be a number, got %q", name, value)
+			}
+			*field = f
+		}
+	}
+
+	bools := map[string]*bool{
+		"MINIO_USE_SSL":           &c.MinIO.UseSSL,
+		"EMBEDDING_CACHE_PERSIST": &c.Embeddings.PersistCache,
+	}
+	for name, field := range bools {
+		if value := os.Getenv(name); value != "" {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%s must be true or false, got %q", name, value)
+			}
+			*field = b
+		}
+	}
+	return nil
+}
+
+// bucketName matches the S3 bucket naming rules
+var bucketName = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// Validate reports every invalid setting, one per line, named by its key in the config file
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(key, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if _, port, err := net.SplitHostPort(c.Server.Address); err != nil {
+		invalid("server.address", "must be host:port, got %q", c.Server.Address)
+	} else if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		invalid("server.address", "invalid port %q", port)
+	}
+	if len(c.Server.CORSOrigins) == 0 {
+		invalid("server.cors_origins", "at least one origin is required")
+	}
+	// Credentials are allowed, so browsers reject the * wildcard; origins must be listed
+	for _, origin := range c.Server.CORSOrigins {
+		if u, err := url.Parse(origin); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.Trim(u.Path, "/") != "" {
+			invalid("server.cors_origins", "%q must be an http(s) origin such as http://localhost:3000", origin)
+		}
+	}
+
+	if strings.TrimSpace(c.Database.URL) == "" {
+		invalid("database.url", "is required")
+	}
+
+	for _, timeout := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+		{"database.statement_timeout", c.Database.StatementTimeout},
+		{"minio.timeout", c.MinIO.Timeout},
+		{"python_api.timeout", c.PythonAPI.Timeout},
+		{"python_api.breaker_cooldown", c.PythonAPI.BreakerCooldown},
+	} {
+		if timeout.value <= 0 {
+			invalid(timeout.key, "must be positive, got %s", timeout.value)
+		}
+	}
+
+	if c.MinIO.Endpoint == "" {
+		invalid("minio.endpoint", "is required")
+	} else if strings.Contains(c.MinIO.Endpoint, "://") {
+		invalid("minio.endpoint", "must be host:port without a scheme, got %q; use minio.use_ssl for HTTPS", c.MinIO.Endpoint)
+	}
+	if c.MinIO.AccessKey == "" {
+		invalid("minio.access_key", "is required")
+	}
+	if c.MinIO.SecretKey == "" {
+		invalid("minio.secret_key", "is required")
+	}
+	if !bucketName.MatchString(c.MinIO.Bucket) {
+		invalid("minio.bucket", "%q must be 3-63 lowercase letters, digits, dots or hyphens", c.MinIO.Bucket)
+	}
+
+	if u, err := url.Parse(c.PythonAPI.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		invalid("python_api.url", "must be an http(s) URL, got %q", c.PythonAPI.URL)
+	}
+	if c.PythonAPI.MaxRetries < 0 || c.PythonAPI.MaxRetries > 10 {
+		invalid("python_api.max_retries", "must be between 0 and 10, got %d", c.PythonAPI.MaxRetries)
+	}
+	if c.PythonAPI.BreakerThreshold < 1 {
+		invalid("python_api.breaker_threshold", "must be at least 1, got %d", c.PythonAPI.BreakerThreshold)
+	}
+
+	if strings.TrimSpace(c.Embeddings.Model) == "" {
+		invalid("embeddings.model", "is required")
+	}
+	if c.Embeddings.CacheSize < 0 {
+		invalid("embeddings.cache_size", "must not be negative, got %d", c.Embeddings.CacheSize)
+	}
+
+	if c.VectorIndex.Type != "hnsw" && c.VectorIndex.Type != "ivfflat" {
+		invalid("vector_index.type", "must be hnsw or ivfflat, got %q", c.VectorIndex.Type)
+	}
+	for _, setting := range []struct {
+		key   string
+		value int
+	}{
+		{"vector_index.hnsw_m", c.VectorIndex.HNSWM},
+		{"vector_index.hnsw_ef_construction", c.VectorIndex.HNSWEfConstruction},
+		{"vector_index.ivfflat_lists", c.VectorIndex.IVFFlatLists},
+		{"vector_index.hnsw_ef_search", c.VectorIndex.HNSWEfSearch},
+		{"vector_index.ivfflat_probes", c.VectorIndex.IVFFlatProbes},
+	} {
+		if setting.value < 1 {
+			invalid(setting.key, "must be at least 1, got %d", setting.value)
+		}
+	}
+
+	if c.Languages.File != "" {
+		if _, err := os.Stat(c.Languages.File); err != nil {
+			invalid("languages.file", "cannot be read: %v", err)
+		}
+	}
+	if strings.TrimSpace(c.Languages.Default) == "" {
+		invalid("languages.default", "is required")
+	}
+
+	if c.Quiz.SimilarityThreshold <= 0 || c.Quiz.SimilarityThreshold > 1 {
+		invalid("quiz.similarity_threshold", "must be above 0 and at most 1, got %g", c.Quiz.SimilarityThreshold)
+	}
+
+	return errors.Join(errs...)
+}
+
+// splitList splits a comma-separated list, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
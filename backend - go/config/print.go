@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted replaces secret values in printed configuration
+const redacted = "********"
+
+// dsnPassword matches the password of a key=value DSN
+var dsnPassword = regexp.MustCompile(`(password=)('[^']*'|\S+)`)
+
+// Redacted returns a copy of the configuration with passwords, keys and tokens masked
+func (c *Config) Redacted() *Config {
+	r := *c
+	r.Server.CORSOrigins = append([]string(nil), c.Server.CORSOrigins...)
+	r.Database.URL = redactDatabaseURL(c.Database.URL)
+	r.MinIO.SecretKey = redact(c.MinIO.SecretKey)
+	r.Admin.Token = redact(c.Admin.Token)
+	return &r
+}
+
+// Print writes the redacted configuration as YAML, in the format accepted by a config file
+func (c *Config) Print(w io.Writer) error {
+	if c.File != "" {
+		fmt.Fprintf(w, "# Loaded from %s, environment variables and flags\n", c.File)
+	} else {
+		fmt.Fprintln(w, "# Loaded from defaults, environment variables and flags")
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.Redacted()); err != nil {
+		return fmt.Errorf("failed to print config: %w", err)
+	}
+	return encoder.Close()
+}
+
+// redact masks a secret, leaving unset secrets empty so they can be told apart
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redacted
+}
+
+// redactDatabaseURL masks the password of a postgres:// URL or key=value DSN
+func redactDatabaseURL(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		if _, ok := u.User.Password(); ok {
+			// Redacted masks with xxxxx; use the same mask as the other secrets
+			return strings.Replace(u.Redacted(), ":xxxxx@", ":"+redacted+"@", 1)
+		}
+		return dsn
+	}
+	return dsnPassword.ReplaceAllString(dsn, "${1}"+redacted)
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// connectDatabase opens the database at url, given as a URL or key=value DSN. With a positive
+// statementTimeout Postgres cancels any statement that runs longer on these connections.
+func connectDatabase(url string, statementTimeout time.Duration) (*gorm.DB, error) {
+	connConfig, err := pgx.ParseConfig(url)
+	if err != nil {
+		return nil, err
+	}
+	if statementTimeout > 0 {
+		connConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(statementTimeout.Milliseconds(), 10)
+	}
+
+	return gorm.Open(postgres.New(postgres.Config{Conn: stdlib.OpenDB(*connConfig)}), &gorm.Config{})
+}
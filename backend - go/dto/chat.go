@@ -6,9 +6,10 @@ type ChatMessage struct {
 }
 
 type ChatRequest struct {
-	Message string        `json:"message" binding:"required"`
-	History []ChatMessage `json:"history"`
-	PDFIDs  []uint        `json:"pdf_ids"` // Array of PDF IDs for context
+	Message        string        `json:"message" binding:"required"`
+	History        []ChatMessage `json:"history"`
+	PDFIDs         []uint        `json:"pdf_ids"`         // Array of PDF IDs for context
+	ExcludeFlagged bool          `json:"exclude_flagged"` // Skip summaries flagged by feedback
 }
 
 type ChatResponse struct {
@@ -16,3 +17,10 @@ type ChatResponse struct {
 	ProcessingTime float64 `json:"processing_time"`
 	Status         string  `json:"status"`
 }
+
+// PythonChatRequest is sent to the Python backend's /chat endpoint
+type PythonChatRequest struct {
+	Message string        `json:"message"`
+	History []ChatMessage `json:"history"`
+	Context string        `json:"context,omitempty"` // Most relevant summary of the selected PDFs
+}
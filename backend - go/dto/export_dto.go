@@ -0,0 +1,10 @@
+package dto
+
+import "time"
+
+type ExportDocument struct {
+	Title      string            `json:"title"`
+	PDF        PDFBasicInfo      `json:"pdf"`
+	Summaries  []SummaryResponse `json:"summaries"`
+	ExportedAt time.Time         `json:"exported_at"`
+}
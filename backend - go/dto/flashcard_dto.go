@@ -0,0 +1,49 @@
+package dto
+
+import "time"
+
+type FlashcardGenerateRequest struct {
+	Source    string `json:"source"`     // "document" (default) or "summary"
+	SummaryID *uint  `json:"summary_id"` // Summary to use when source is "summary"; defaults to the primary summary
+	Count     int    `json:"count"`
+	Language  string `json:"language"`
+}
+
+type FlashcardUpdateRequest struct {
+	Question   *string `json:"question"`
+	Answer     *string `json:"answer"`
+	SourcePage *int    `json:"source_page"`
+}
+
+type FlashcardResponse struct {
+	ID            uint      `json:"id"`
+	PDFID         uint      `json:"pdf_id"`
+	SummaryID     *uint     `json:"summary_id,omitempty"`
+	Question      string    `json:"question"`
+	Answer        string    `json:"answer"`
+	SourcePage    *int      `json:"source_page"`
+	Language      string    `json:"language"`
+	ModelName     string    `json:"model_name"`
+	PromptVersion string    `json:"prompt_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type FlashcardListResponse struct {
+	Data  []FlashcardResponse `json:"data"`
+	Total int                 `json:"total"`
+}
+
+type PythonFlashcard struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+	Page     *int   `json:"page"`
+}
+
+type PythonFlashcardsResponse struct {
+	Flashcards     []PythonFlashcard `json:"flashcards"`
+	Language       string            `json:"language"`
+	ProcessingTime float64           `json:"processing_time"`
+	Model          string            `json:"model"`
+	PromptVersion  string            `json:"prompt_version"`
+}
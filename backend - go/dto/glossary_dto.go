@@ -0,0 +1,43 @@
+package dto
+
+import "time"
+
+type GlossaryExtractRequest struct {
+	MaxTerms int    `json:"max_terms"`
+	Language string `json:"language"`
+}
+
+type GlossaryTermResponse struct {
+	ID         uint          `json:"id"`
+	PDFID      uint          `json:"pdf_id"`
+	Term       string        `json:"term"`
+	Definition string        `json:"definition"`
+	Page       *int          `json:"page"`
+	Language   string        `json:"language"`
+	ModelName  string        `json:"model_name"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	PDF        *PDFBasicInfo `json:"pdf,omitempty"`
+}
+
+type GlossaryListResponse struct {
+	Data         []GlossaryTermResponse `json:"data"`
+	Page         int                    `json:"page"`
+	ItemsPerPage int                    `json:"itemsPerPage"`
+	TotalPages   int                    `json:"totalPages"`
+	TotalItems   int64                  `json:"totalItems"`
+}
+
+type PythonGlossaryTerm struct {
+	Term       string `json:"term"`
+	Definition string `json:"definition"`
+	Page       *int   `json:"page"`
+}
+
+type PythonGlossaryResponse struct {
+	Terms          []PythonGlossaryTerm `json:"terms"`
+	Language       string               `json:"language"`
+	ProcessingTime float64              `json:"processing_time"`
+	Model          string               `json:"model"`
+	PromptVersion  string               `json:"prompt_version"`
+}
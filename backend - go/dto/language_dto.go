@@ -0,0 +1,12 @@
+package dto
+
+type LanguageResponse struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	NativeName string `json:"native_name"`
+}
+
+type LanguageListResponse struct {
+	Data    []LanguageResponse `json:"data"`
+	Default string             `json:"default"`
+}
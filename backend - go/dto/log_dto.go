@@ -0,0 +1,16 @@
+package dto
+
+// LogStatsResponse summarizes the request log
+type LogStatsResponse struct {
+	TotalRequests    int64              `json:"total_requests"`
+	AvgDuration      float64            `json:"avg_duration_ms"`
+	ByStatusCode     map[string]int64   `json:"by_status_code"`
+	ByMethod         map[string]int64   `json:"by_method"`
+	SlowestEndpoints []EndpointDuration `json:"slowest_endpoints"`
+}
+
+// EndpointDuration is the average duration of requests to one path
+type EndpointDuration struct {
+	Path        string  `json:"path"`
+	AvgDuration float64 `json:"avg_duration_ms"`
+}
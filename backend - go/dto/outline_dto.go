@@ -0,0 +1,31 @@
+package dto
+
+import "time"
+
+type OutlineSection struct {
+	Heading  string           `json:"heading"`
+	Level    int              `json:"level"`
+	Page     *int             `json:"page"`
+	Gist     string           `json:"gist"`
+	Children []OutlineSection `json:"children"`
+}
+
+type OutlineResponse struct {
+	ID        uint             `json:"id"`
+	PDFID     uint             `json:"pdf_id"`
+	Source    string           `json:"source"`
+	Sections  []OutlineSection `json:"sections"`
+	Language  string           `json:"language"`
+	ModelName string           `json:"model_name"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+type PythonOutlineResponse struct {
+	Source         string           `json:"source"`
+	Sections       []OutlineSection `json:"sections"`
+	Language       string           `json:"language"`
+	ProcessingTime float64          `json:"processing_time"`
+	Model          string           `json:"model"`
+	PromptVersion  string           `json:"prompt_version"`
+}
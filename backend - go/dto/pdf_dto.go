@@ -10,19 +10,34 @@ type PDFCreateRequest struct {
 }
 
 type PDFResponse struct {
-	ID             uint              `json:"id"`
-	Filename       string            `json:"filename"`
-	FileSize       int64             `json:"file_size"`
-	Title          string            `json:"title"`
-	PageCount      int               `json:"page_count"`
-	Summary        string            `json:"summary"`
-	Style          string            `json:"style"`
-	Language       string            `json:"language"`
-	SummaryTime    float64           `json:"summary_time"`
-	SummaryVersion int               `json:"summary_version"`
-	CreatedAt      time.Time         `json:"created_at"`
-	UpdatedAt      time.Time         `json:"updated_at"`
-	Summaries      []SummaryResponse `json:"summaries"`
+	ID               uint              `json:"id"`
+	Filename         string            `json:"filename"`
+	FileSize         int64             `json:"file_size"`
+	Title            string            `json:"title"`
+	PageCount        int               `json:"page_count"`
+	Summary          string            `json:"summary"`
+	Style            string            `json:"style"`
+	Language         string            `json:"language"`
+	SummaryTime      float64           `json:"summary_time"`
+	SummaryVersion   int               `json:"summary_version"`
+	DetectedLanguage string            `json:"detected_language"`
+	PinnedSummaryID  *uint             `json:"pinned_summary_id"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+	Summaries        []SummaryResponse `json:"summaries"`
+}
+
+// PDFDetailResponse is a single PDF with the library's most similar other PDFs
+type PDFDetailResponse struct {
+	PDFResponse
+	Related []SimilarPDFResponse `json:"related"`
+}
+
+// SimilarPDFResponse is a PDF ranked by the similarity of its summaries to another PDF
+type SimilarPDFResponse struct {
+	PDF          PDFBasicInfo `json:"pdf"`
+	Score        float64      `json:"score"`         // Cosine similarity of the averaged summary embeddings
+	SummaryCount int          `json:"summary_count"` // Summaries with embeddings behind the score
 }
 
 type PDFListResponse struct {
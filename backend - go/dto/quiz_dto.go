@@ -0,0 +1,83 @@
+package dto
+
+import "time"
+
+type QuizGenerateRequest struct {
+	Count         int      `json:"count"`
+	QuestionTypes []string `json:"question_types"` // "multiple_choice" and/or "short_answer"; defaults to both
+	Language      string   `json:"language"`
+}
+
+type QuizQuestionResponse struct {
+	ID            uint     `json:"id"`
+	Position      int      `json:"position"`
+	Type          string   `json:"type"`
+	Question      string   `json:"question"`
+	Options       []string `json:"options"`
+	SourcePage    *int     `json:"source_page"`
+	CorrectOption *int     `json:"correct_option,omitempty"`
+	Answer        string   `json:"answer,omitempty"`
+	Explanation   string   `json:"explanation,omitempty"`
+}
+
+type QuizResponse struct {
+	ID            uint                   `json:"id"`
+	PDFID         uint                   `json:"pdf_id"`
+	Title         string                 `json:"title"`
+	Language      string                 `json:"language"`
+	ModelName     string                 `json:"model_name"`
+	PromptVersion string                 `json:"prompt_version"`
+	QuestionCount int                    `json:"question_count"`
+	Questions     []QuizQuestionResponse `json:"questions,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
+}
+
+type QuizAnswerRequest struct {
+	QuestionID     uint   `json:"question_id"`
+	SelectedOption *int   `json:"selected_option"`
+	Response       string `json:"response"`
+}
+
+type QuizAttemptRequest struct {
+	Answers []QuizAnswerRequest `json:"answers"`
+}
+
+type QuizAnswerResult struct {
+	QuestionID     uint     `json:"question_id"`
+	SelectedOption *int     `json:"selected_option,omitempty"`
+	Response       string   `json:"response,omitempty"`
+	Correct        bool     `json:"correct"`
+	Similarity     *float64 `json:"similarity,omitempty"`
+	CorrectOption  *int     `json:"correct_option,omitempty"`
+	Answer         string   `json:"answer"`
+	Explanation    string   `json:"explanation,omitempty"`
+}
+
+type QuizAttemptResponse struct {
+	ID           uint               `json:"id"`
+	QuizID       uint               `json:"quiz_id"`
+	UserID       string             `json:"user_id"`
+	CorrectCount int                `json:"correct_count"`
+	Total        int                `json:"total"`
+	Score        float64            `json:"score"`
+	Answers      []QuizAnswerResult `json:"answers"`
+	CreatedAt    time.Time          `json:"created_at"`
+}
+
+type PythonQuizQuestion struct {
+	Type          string   `json:"type"`
+	Question      string   `json:"question"`
+	Options       []string `json:"options"`
+	CorrectOption *int     `json:"correct_option"`
+	Answer        string   `json:"answer"`
+	Explanation   string   `json:"explanation"`
+	Page          *int     `json:"page"`
+}
+
+type PythonQuizResponse struct {
+	Questions      []PythonQuizQuestion `json:"questions"`
+	Language       string               `json:"language"`
+	ProcessingTime float64              `json:"processing_time"`
+	Model          string               `json:"model"`
+	PromptVersion  string               `json:"prompt_version"`
+}
@@ -0,0 +1,59 @@
+package dto
+
+import "time"
+
+type StudyItemResponse struct {
+	ID             uint       `json:"id"`
+	PDFID          uint       `json:"pdf_id"`
+	PDFTitle       string     `json:"pdf_title"`
+	SummaryID      uint       `json:"summary_id"`
+	Position       int        `json:"position"`
+	Content        string     `json:"content"`
+	New            bool       `json:"new"` // Never reviewed by the caller
+	EaseFactor     float64    `json:"ease_factor"`
+	IntervalDays   int        `json:"interval_days"`
+	Repetitions    int        `json:"repetitions"`
+	DueAt          *time.Time `json:"due_at"`
+	LastReviewedAt *time.Time `json:"last_reviewed_at"`
+}
+
+type StudyDueResponse struct {
+	Data     []StudyItemResponse `json:"data"`
+	DueCount int64               `json:"due_count"` // Previously reviewed items due now
+	NewCount int64               `json:"new_count"` // Items never reviewed
+}
+
+type StudyReviewRequest struct {
+	Grade *int `json:"grade"` // 0 (blackout) to 5 (perfect recall)
+}
+
+type StudyReviewResponse struct {
+	ID           uint      `json:"id"`
+	StudyItemID  uint      `json:"study_item_id"`
+	Grade        int       `json:"grade"`
+	EaseFactor   float64   `json:"ease_factor"`
+	IntervalDays int       `json:"interval_days"`
+	Repetitions  int       `json:"repetitions,omitempty"`
+	DueAt        time.Time `json:"due_at"`
+	ReviewedAt   time.Time `json:"reviewed_at"`
+}
+
+type DailyReviewCount struct {
+	Date  string `json:"date"`
+	Count int64  `json:"count"`
+}
+
+type StudyStatsResponse struct {
+	UserID        string             `json:"user_id"`
+	WorkspaceID   string             `json:"workspace_id"`
+	TotalItems    int64              `json:"total_items"`
+	NewItems      int64              `json:"new_items"`
+	DueItems      int64              `json:"due_items"`
+	LearnedItems  int64              `json:"learned_items"` // Last review passed
+	MatureItems   int64              `json:"mature_items"`  // Interval of 21 days or more
+	TotalReviews  int64              `json:"total_reviews"`
+	ReviewsToday  int64              `json:"reviews_today"`
+	AverageGrade  float64            `json:"average_grade"`
+	RetentionRate float64            `json:"retention_rate"` // Share of reviews graded 3 or more
+	DailyReviews  []DailyReviewCount `json:"daily_reviews"`  // Last 14 days, oldest first
+}
@@ -18,15 +18,32 @@ type SummaryCreateRequest struct {
 }
 
 type SummaryResponse struct {
-	ID          uint          `json:"id"`
-	Style       string        `json:"style"`
-	Content     string        `json:"content"`
-	PDFID       uint          `json:"pdf_id"`
-	Language    string        `json:"language"`
-	SummaryTime float64       `json:"summary_time"`
-	CreatedAt   time.Time     `json:"created_at"`
-	UpdatedAt   time.Time     `json:"updated_at"`
-	PDF         *PDFBasicInfo `json:"pdf,omitempty"`
+	ID              uint          `json:"id"`
+	Style           string        `json:"style"`
+	Content         string        `json:"content"`
+	PDFID           *uint         `json:"pdf_id"`
+	Language        string        `json:"language"`
+	SummaryTime     float64       `json:"summary_time"`
+	SourceSummaryID *uint         `json:"source_summary_id,omitempty"`
+	OriginalContent string        `json:"original_content,omitempty"`
+	ModelName       string        `json:"model_name"`
+	PromptVersion   string        `json:"prompt_version"`
+	EditedBy        string        `json:"edited_by,omitempty"`
+	EditedAt        *time.Time    `json:"edited_at,omitempty"`
+	Flagged         bool          `json:"flagged"`
+	Focus           string        `json:"focus,omitempty"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+	PDF             *PDFBasicInfo `json:"pdf,omitempty"`
+	Sources         []SourceInfo  `json:"sources,omitempty"`
+}
+
+// SourceInfo is one source PDF of a synthesized summary
+type SourceInfo struct {
+	PDFID            uint          `json:"pdf_id"`
+	Position         int           `json:"position"`
+	ContextSummaryID *uint         `json:"context_summary_id"`
+	PDF              *PDFBasicInfo `json:"pdf,omitempty"`
 }
 
 type PDFBasicInfo struct {
@@ -46,15 +63,17 @@ type SummaryListResponse struct {
 }
 
 type PythonSummaryResponse struct {
-	Title       string                 `json:"title"`
-	Summary     SummaryDetails         `json:"summary"`
-	Embedding   []float32              `json:"embedding"`
-	Language    string                 `json:"language"`
-	Style       string                 `json:"style"`
-	FileInfo    FileInfo               `json:"file_info"`
-	TextStats   map[string]interface{} `json:"text_statistics"`
-	ProcessInfo ProcessingInfo         `json:"processing_info"`
-	Status      string                 `json:"status"`
+	Title         string                 `json:"title"`
+	Summary       SummaryDetails         `json:"summary"`
+	Embedding     []float32              `json:"embedding"`
+	Language      string                 `json:"language"`
+	Style         string                 `json:"style"`
+	FileInfo      FileInfo               `json:"file_info"`
+	TextStats     map[string]interface{} `json:"text_statistics"`
+	ProcessInfo   ProcessingInfo         `json:"processing_info"`
+	Model         string                 `json:"model"`
+	PromptVersion string                 `json:"prompt_version"`
+	Status        string                 `json:"status"`
 }
 
 type SummaryDetails struct {
@@ -76,6 +95,86 @@ type ProcessingInfo struct {
 	EmbeddingDimensions   int     `json:"embedding_dimensions"`
 }
 
+type SynthesizeRequest struct {
+	PDFIDs   []uint `json:"pdf_ids"`
+	Focus    string `json:"focus"`
+	Style    string `json:"style"`
+	Language string `json:"language"`
+}
+
+type PythonSynthesisSource struct {
+	PDFID   uint   `json:"pdf_id"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+type PythonSynthesizeRequest struct {
+	Sources             []PythonSynthesisSource `json:"sources"`
+	Focus               string                  `json:"focus,omitempty"`
+	Style               string                  `json:"style"`
+	Language            string                  `json:"language"`
+	LanguageName        string                  `json:"language_name"`
+	LanguageInstruction string                  `json:"language_instruction"`
+}
+
+type PythonSynthesizeResponse struct {
+	Synthesis      string    `json:"synthesis"`
+	Embedding      []float32 `json:"embedding"`
+	ProcessingTime float64   `json:"processing_time"`
+	Model          string    `json:"model"`
+	PromptVersion  string    `json:"prompt_version"`
+	Status         string    `json:"status"`
+}
+
+type TranslateSummaryRequest struct {
+	Language string `json:"language" binding:"required"`
+}
+
+type SummaryUpdateRequest struct {
+	Content  string `json:"content" binding:"required"`
+	EditedBy string `json:"edited_by"`
+}
+
+type PythonTranslateRequest struct {
+	Text                string `json:"text"`
+	Language            string `json:"language"`
+	LanguageName        string `json:"language_name"`
+	LanguageInstruction string `json:"language_instruction"`
+}
+
+type PythonTranslateResponse struct {
+	Translation    string    `json:"translation"`
+	Embedding      []float32 `json:"embedding"`
+	ProcessingTime float64   `json:"processing_time"`
+	Model          string    `json:"model"`
+	PromptVersion  string    `json:"prompt_version"`
+	Status         string    `json:"status"`
+}
+
+// DiffSegment is one run of unchanged, removed or added text between two summaries.
+// Replace segments pair removed text (A) with the text that took its place (B).
+type DiffSegment struct {
+	Op string `json:"op"` // equal, delete, insert or replace
+	A  string `json:"a,omitempty"`
+	B  string `json:"b,omitempty"`
+}
+
+type DiffStats struct {
+	Unchanged int `json:"unchanged"`
+	Removed   int `json:"removed"`
+	Added     int `json:"added"`
+}
+
+type SummaryCompareResponse struct {
+	A             SummaryResponse `json:"a"`
+	B             SummaryResponse `json:"b"`
+	Similarity    *float64        `json:"similarity"`
+	SentenceDiff  []DiffSegment   `json:"sentence_diff"`
+	SentenceStats DiffStats       `json:"sentence_stats"`
+	WordDiff      []DiffSegment   `json:"word_diff"`
+	WordStats     DiffStats       `json:"word_stats"`
+}
+
 type BulkDeleteRequest struct {
 	IDs []uint `json:"ids" binding:"required"`
 }
@@ -86,9 +185,36 @@ type BulkDeleteResponse struct {
 }
 
 type SummaryStatsResponse struct {
-	TotalSummaries int64            `json:"total_summaries"`
-	ByStyle        map[string]int64 `json:"by_style"`
-	ByLanguage     map[string]int64 `json:"by_language"`
-	AvgSummaryTime float64          `json:"avg_summary_time"`
-	TotalPDFs      int64            `json:"total_pdfs"`
+	TotalSummaries     int64                    `json:"total_summaries"`
+	ByStyle            map[string]int64         `json:"by_style"`
+	ByLanguage         map[string]int64         `json:"by_language"`
+	AvgSummaryTime     float64                  `json:"avg_summary_time"`
+	TotalPDFs          int64                    `json:"total_pdfs"`
+	FeedbackByStyle    map[string]FeedbackStats `json:"feedback_by_style"`
+	FeedbackByLanguage map[string]FeedbackStats `json:"feedback_by_language"`
+}
+
+type FeedbackRequest struct {
+	Vote    int      `json:"vote"`   // 1 thumbs up, -1 thumbs down, 0 no vote
+	Rating  *int     `json:"rating"` // 1-5
+	Comment string   `json:"comment"`
+	Issues  []string `json:"issues"` // e.g. hallucination, inaccurate, incomplete
+}
+
+type FeedbackResponse struct {
+	ID        uint      `json:"id"`
+	SummaryID uint      `json:"summary_id"`
+	Vote      int       `json:"vote"`
+	Rating    *int      `json:"rating"`
+	Comment   string    `json:"comment"`
+	Issues    []string  `json:"issues"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type FeedbackStats struct {
+	FeedbackCount int64   `json:"feedback_count"`
+	AvgRating     float64 `json:"avg_rating"`
+	ThumbsUp      int64   `json:"thumbs_up"`
+	ThumbsDown    int64   `json:"thumbs_down"`
+	FlaggedCount  int64   `json:"flagged_count"`
 }
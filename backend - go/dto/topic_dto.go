@@ -0,0 +1,49 @@
+package dto
+
+import "time"
+
+type TopicRebuildRequest struct {
+	K int `json:"k"` // Number of topics; 0 picks one from the library size
+}
+
+type TopicResponse struct {
+	ID          uint                  `json:"id"`
+	Label       string                `json:"label"`
+	Description string                `json:"description"`
+	Size        int                   `json:"size"`
+	ModelName   string                `json:"model_name"`
+	CreatedAt   time.Time             `json:"created_at"`
+	Members     []TopicMemberResponse `json:"members"`
+}
+
+type TopicMemberResponse struct {
+	PDF   PDFBasicInfo `json:"pdf"`
+	Score float64      `json:"score"`
+}
+
+type PythonTopicDocument struct {
+	Title   string `json:"title"`
+	Excerpt string `json:"excerpt"`
+}
+
+type PythonTopicCluster struct {
+	ID        int                   `json:"id"`
+	Documents []PythonTopicDocument `json:"documents"`
+}
+
+type PythonLabelTopicsRequest struct {
+	Clusters []PythonTopicCluster `json:"clusters"`
+}
+
+type PythonTopicLabel struct {
+	ID          int    `json:"id"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+}
+
+type PythonLabelTopicsResponse struct {
+	Topics         []PythonTopicLabel `json:"topics"`
+	ProcessingTime float64            `json:"processing_time"`
+	Model          string             `json:"model"`
+	PromptVersion  string             `json:"prompt_version"`
+}
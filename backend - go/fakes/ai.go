@@ -0,0 +1,86 @@
+package fakes
+
+import (
+	"backend-go/dto"
+	"backend-go/utils"
+	"context"
+	"io"
+)
+
+// SummarizeCall records one AI.Summarize call
+type SummarizeCall struct {
+	Filename string
+	Content  []byte
+	Style    string
+	Language string // Language code
+}
+
+// AI is a scripted services.AI that records what it was asked
+type AI struct {
+	Summary       *dto.PythonSummaryResponse // Returned by Summarize
+	SummarizeErr  error
+	Text          string // Returned by ExtractText
+	ExtractErr    error
+	Embedding     []float32 // Returned by Embed
+	EmbedErr      error
+	Translation   *dto.PythonTranslateResponse // Returned by Translate
+	TranslateErr  error
+	Synthesis     *dto.PythonSynthesizeResponse // Returned by Synthesize
+	SynthesizeErr error
+	Reply         []byte // Returned by Chat
+	ChatErr       error
+
+	SummarizeCalls     []SummarizeCall
+	Embedded           []string
+	TranslateRequests  []dto.PythonTranslateRequest
+	SynthesizeRequests []dto.PythonSynthesizeRequest
+	ChatRequests       []dto.PythonChatRequest
+}
+
+func (a *AI) Summarize(ctx context.Context, filename string, file io.Reader, style string, language utils.Language) (*dto.PythonSummaryResponse, error) {
+	content, _ := io.ReadAll(file)
+	a.SummarizeCalls = append(a.SummarizeCalls, SummarizeCall{Filename: filename, Content: content, Style: style, Language: language.Code})
+	if a.SummarizeErr != nil {
+		return nil, a.SummarizeErr
+	}
+	return a.Summary, nil
+}
+
+func (a *AI) ExtractText(ctx context.Context, filename string, file io.Reader, maxChars int) (string, error) {
+	if a.ExtractErr != nil {
+		return "", a.ExtractErr
+	}
+	return a.Text, nil
+}
+
+func (a *AI) Embed(ctx context.Context, text string) ([]float32, error) {
+	a.Embedded = append(a.Embedded, text)
+	if a.EmbedErr != nil {
+		return nil, a.EmbedErr
+	}
+	return a.Embedding, nil
+}
+
+func (a *AI) Translate(ctx context.Context, request dto.PythonTranslateRequest) (*dto.PythonTranslateResponse, error) {
+	a.TranslateRequests = append(a.TranslateRequests, request)
+	if a.TranslateErr != nil {
+		return nil, a.TranslateErr
+	}
+	return a.Translation, nil
+}
+
+func (a *AI) Synthesize(ctx context.Context, request dto.PythonSynthesizeRequest) (*dto.PythonSynthesizeResponse, error) {
+	a.SynthesizeRequests = append(a.SynthesizeRequests, request)
+	if a.SynthesizeErr != nil {
+		return nil, a.SynthesizeErr
+	}
+	return a.Synthesis, nil
+}
+
+func (a *AI) Chat(ctx context.Context, request dto.PythonChatRequest) ([]byte, error) {
+	a.ChatRequests = append(a.ChatRequests, request)
+	if a.ChatErr != nil {
+		return nil, a.ChatErr
+	}
+	return a.Reply, nil
+}
@@ -0,0 +1,130 @@
+package fakes
+
+import (
+	"backend-go/dto"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// EmbeddingDimensions matches the vector(1024) column summaries are stored in
+const EmbeddingDimensions = 1024
+
+// PythonAPI is an httptest stand-in for the Python AI service's /summarize, /extract-text,
+// /embedding and /chat endpoints. It returns canned answers and records what it was sent.
+type PythonAPI struct {
+	Server *httptest.Server
+
+	Summary string // main_summary returned by /summarize
+	Text    string // Text returned by /extract-text
+	Reply   string // Reply returned by /chat
+
+	EmbeddingStatus int // When set, /embedding fails with this status
+
+	mu           sync.Mutex
+	files        [][]byte
+	chatRequests []dto.PythonChatRequest
+}
+
+// NewPythonAPI starts a PythonAPI server; close it with Close
+func NewPythonAPI() *PythonAPI {
+	p := &PythonAPI{Summary: "A short summary.", Reply: "A grounded answer."}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /summarize", p.summarize)
+	mux.HandleFunc("POST /extract-text", p.extractText)
+	mux.HandleFunc("POST /embedding", p.embedding)
+	mux.HandleFunc("POST /chat", p.chat)
+	p.Server = httptest.NewServer(mux)
+	return p
+}
+
+// URL returns the base URL to use as PYTHON_API_URL
+func (p *PythonAPI) URL() string {
+	return p.Server.URL
+}
+
+// Close shuts the server down
+func (p *PythonAPI) Close() {
+	p.Server.Close()
+}
+
+// SummarizedFiles returns the content of every file sent to /summarize
+func (p *PythonAPI) SummarizedFiles() [][]byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([][]byte(nil), p.files...)
+}
+
+// ChatRequests returns every request sent to /chat
+func (p *PythonAPI) ChatRequests() []dto.PythonChatRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]dto.PythonChatRequest(nil), p.chatRequests...)
+}
+
+func (p *PythonAPI) summarize(w http.ResponseWriter, r *http.Request) {
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, `{"detail":"file is required"}`, http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, `{"detail":"failed to read file"}`, http.StatusBadRequest)
+		return
+	}
+
+	p.mu.Lock()
+	p.files = append(p.files, data)
+	p.mu.Unlock()
+
+	writeJSON(w, dto.PythonSummaryResponse{
+		Summary:   dto.SummaryDetails{MainSummary: p.Summary},
+		Embedding: embedding(),
+		Language:  r.FormValue("language"),
+		Style:     r.FormValue("style"),
+		Model:     "fake-model",
+		Status:    "success",
+	})
+}
+
+func (p *PythonAPI) extractText(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]string{"text": p.Text})
+}
+
+func (p *PythonAPI) embedding(w http.ResponseWriter, r *http.Request) {
+	if p.EmbeddingStatus != 0 {
+		http.Error(w, `{"detail":"embedding failed"}`, p.EmbeddingStatus)
+		return
+	}
+	writeJSON(w, map[string][]float32{"embedding": embedding()})
+}
+
+func (p *PythonAPI) chat(w http.ResponseWriter, r *http.Request) {
+	var request dto.PythonChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, `{"detail":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	p.mu.Lock()
+	p.chatRequests = append(p.chatRequests, request)
+	p.mu.Unlock()
+
+	writeJSON(w, dto.ChatResponse{Reply: p.Reply, Status: "success"})
+}
+
+// embedding returns the same unit vector every time, so every summary matches every message
+func embedding() []float32 {
+	v := make([]float32, EmbeddingDimensions)
+	v[0] = 1
+	return v
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,357 @@
+// Package fakes provides in-memory stand-ins for the repositories, file storage and
+// AI service, for testing services and handlers without Postgres, MinIO or Python.
+package fakes
+
+import (
+	"backend-go/dto"
+	"backend-go/models"
+	"backend-go/repository"
+	"context"
+	"strings"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// page returns the rows of items on page; sorting is not emulated, rows keep insertion order
+func page[T any](items []T, p repository.Page) []T {
+	start := p.Offset()
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + p.Size
+	if end > len(items) {
+		end = len(items)
+	}
+	return append([]T(nil), items[start:end]...)
+}
+
+// PDFRepository is an in-memory repository.PDFRepository
+type PDFRepository struct {
+	PDFs    []models.PDF
+	Related []dto.SimilarPDFResponse // Returned by Similar
+	Err     error                    // Returned by every method when set
+}
+
+func (r *PDFRepository) List(ctx context.Context, filter repository.PDFFilter, sort repository.Sort, p repository.Page) ([]models.PDF, int64, error) {
+	if r.Err != nil {
+		return nil, 0, r.Err
+	}
+	var matches []models.PDF
+	for _, pdf := range r.PDFs {
+		search := strings.ToLower(filter.Search)
+		if search != "" && !strings.Contains(strings.ToLower(pdf.Title), search) && !strings.Contains(strings.ToLower(pdf.Filename), search) {
+			continue
+		}
+		matches = append(matches, pdf)
+	}
+	return page(matches, p), int64(len(matches)), nil
+}
+
+func (r *PDFRepository) Count(ctx context.Context) (int64, error) {
+	return int64(len(r.PDFs)), r.Err
+}
+
+func (r *PDFRepository) Get(ctx context.Context, id uint) (*models.PDF, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	for i := range r.PDFs {
+		if r.PDFs[i].ID == id {
+			pdf := r.PDFs[i]
+			return &pdf, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *PDFRepository) GetWithSummaries(ctx context.Context, id uint) (*models.PDF, error) {
+	return r.Get(ctx, id)
+}
+
+func (r *PDFRepository) FindMany(ctx context.Context, ids []uint) ([]models.PDF, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	var found []models.PDF
+	for _, id := range ids {
+		if pdf, err := r.Get(ctx, id); err == nil {
+			found = append(found, *pdf)
+		}
+	}
+	return found, nil
+}
+
+func (r *PDFRepository) Create(ctx context.Context, pdf *models.PDF) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	pdf.ID = uint(len(r.PDFs) + 1)
+	r.PDFs = append(r.PDFs, *pdf)
+	return nil
+}
+
+func (r *PDFRepository) Delete(ctx context.Context, pdf *models.PDF) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	for i := range r.PDFs {
+		if r.PDFs[i].ID == pdf.ID {
+			r.PDFs = append(r.PDFs[:i], r.PDFs[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *PDFRepository) Similar(ctx context.Context, id uint, limit int) ([]dto.SimilarPDFResponse, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	related := append([]dto.SimilarPDFResponse{}, r.Related...)
+	if limit < len(related) {
+		related = related[:limit]
+	}
+	return related, nil
+}
+
+// SetPinnedSummary sets the pinned summary ID; the PDF's summary fields are not emulated
+func (r *PDFRepository) SetPinnedSummary(ctx context.Context, id uint, summaryID *uint) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	for i := range r.PDFs {
+		if r.PDFs[i].ID == id {
+			r.PDFs[i].PinnedSummaryID = summaryID
+			return nil
+		}
+	}
+	return nil
+}
+
+// SummaryRepository is an in-memory repository.SummaryRepository
+type SummaryRepository struct {
+	Summaries       []models.Summaries
+	Feedbacks       []models.SummaryFeedback
+	StatsData       dto.SummaryStatsResponse       // Returned by Stats
+	Sources         []dto.PythonSynthesisSource    // Returned by SynthesisContexts
+	Err             error                          // Returned by every method when set
+	LastFilter      repository.SummaryListFilter   // Filter of the last List call
+	LastSearch      *repository.SummaryFilter      // Filter of the last Nearest call
+	LastExportQuery repository.SummaryExportFilter // Filter of the last ListForExport call
+}
+
+func (r *SummaryRepository) List(ctx context.Context, filter repository.SummaryListFilter, sort repository.Sort, p repository.Page) ([]models.Summaries, int64, error) {
+	r.LastFilter = filter
+	if r.Err != nil {
+		return nil, 0, r.Err
+	}
+	var matches []models.Summaries
+	for _, summary := range r.Summaries {
+		if filter.PDFID != 0 && (summary.PDFID == nil || *summary.PDFID != filter.PDFID) {
+			continue
+		}
+		matches = append(matches, summary)
+	}
+	return page(matches, p), int64(len(matches)), nil
+}
+
+func (r *SummaryRepository) Count(ctx context.Context) (int64, error) {
+	return int64(len(r.Summaries)), r.Err
+}
+
+func (r *SummaryRepository) Get(ctx context.Context, id uint) (*models.Summaries, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	for i := range r.Summaries {
+		if r.Summaries[i].ID == id {
+			summary := r.Summaries[i]
+			return &summary, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *SummaryRepository) FindMany(ctx context.Context, ids []uint) ([]models.Summaries, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	var found []models.Summaries
+	for _, id := range ids {
+		if summary, err := r.Get(ctx, id); err == nil {
+			found = append(found, *summary)
+		}
+	}
+	return found, nil
+}
+
+// ListForExport returns the summaries of the PDF with matching IDs; style and language are not emulated
+func (r *SummaryRepository) ListForExport(ctx context.Context, filter repository.SummaryExportFilter) ([]models.Summaries, error) {
+	r.LastExportQuery = filter
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	wanted := make(map[uint]bool, len(filter.IDs))
+	for _, id := range filter.IDs {
+		wanted[id] = true
+	}
+	var matches []models.Summaries
+	for _, summary := range r.Summaries {
+		if summary.PDFID == nil || *summary.PDFID != filter.PDFID || (len(wanted) > 0 && !wanted[summary.ID]) {
+			continue
+		}
+		matches = append(matches, summary)
+	}
+	return matches, nil
+}
+
+func (r *SummaryRepository) Create(ctx context.Context, summary *models.Summaries) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	summary.ID = uint(len(r.Summaries) + 1)
+	r.Summaries = append(r.Summaries, *summary)
+	return nil
+}
+
+// Update replaces the stored summary; the PDF's summary fields are not emulated
+func (r *SummaryRepository) Update(ctx context.Context, summary *models.Summaries) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	for i := range r.Summaries {
+		if r.Summaries[i].ID == summary.ID {
+			r.Summaries[i] = *summary
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (r *SummaryRepository) Delete(ctx context.Context, summary *models.Summaries) error {
+	_, err := r.DeleteMany(ctx, []uint{summary.ID})
+	return err
+}
+
+func (r *SummaryRepository) DeleteMany(ctx context.Context, ids []uint) (int64, error) {
+	if r.Err != nil {
+		return 0, r.Err
+	}
+	remove := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		remove[id] = true
+	}
+	kept := r.Summaries[:0]
+	for _, summary := range r.Summaries {
+		if !remove[summary.ID] {
+			kept = append(kept, summary)
+		}
+	}
+	deleted := int64(len(r.Summaries) - len(kept))
+	r.Summaries = kept
+	return deleted, nil
+}
+
+// Nearest returns the first limit summaries with embeddings that match filter; distances are not emulated
+func (r *SummaryRepository) Nearest(ctx context.Context, v pgvector.Vector, filter repository.SummaryFilter, limit int) ([]models.Summaries, error) {
+	r.LastSearch = &filter
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	allowed := make(map[uint]bool, len(filter.PDFIDs))
+	for _, id := range filter.PDFIDs {
+		allowed[id] = true
+	}
+	var matches []models.Summaries
+	for _, summary := range r.Summaries {
+		if len(matches) == limit {
+			break
+		}
+		if len(summary.Embedding.Slice()) == 0 || (filter.ExcludeFlagged && summary.Flagged) {
+			continue
+		}
+		if len(allowed) > 0 && (summary.PDFID == nil || !allowed[*summary.PDFID]) {
+			continue
+		}
+		matches = append(matches, summary)
+	}
+	return matches, nil
+}
+
+func (r *SummaryRepository) AddFeedback(ctx context.Context, feedback *models.SummaryFeedback, flag bool) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	feedback.ID = uint(len(r.Feedbacks) + 1)
+	r.Feedbacks = append(r.Feedbacks, *feedback)
+	if flag {
+		for i := range r.Summaries {
+			if r.Summaries[i].ID == feedback.SummaryID {
+				r.Summaries[i].Flagged = true
+			}
+		}
+	}
+	return nil
+}
+
+// Feedback returns the feedback on a summary in insertion order
+func (r *SummaryRepository) Feedback(ctx context.Context, summaryID uint) ([]models.SummaryFeedback, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	var feedback []models.SummaryFeedback
+	for _, f := range r.Feedbacks {
+		if f.SummaryID == summaryID {
+			feedback = append(feedback, f)
+		}
+	}
+	return feedback, nil
+}
+
+func (r *SummaryRepository) Stats(ctx context.Context) (*dto.SummaryStatsResponse, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	stats := r.StatsData
+	return &stats, nil
+}
+
+// SynthesisContexts returns Sources and no context summaries
+func (r *SummaryRepository) SynthesisContexts(ctx context.Context, pdfs []models.PDF, focus string) ([]dto.PythonSynthesisSource, map[uint]*uint, error) {
+	if r.Err != nil {
+		return nil, nil, r.Err
+	}
+	return r.Sources, map[uint]*uint{}, nil
+}
+
+// LogRepository is an in-memory repository.LogRepository
+type LogRepository struct {
+	Logs      []models.Log
+	StatsData dto.LogStatsResponse // Returned by Stats
+	Err       error                // Returned by every method when set
+}
+
+func (r *LogRepository) List(ctx context.Context, filter repository.LogFilter, sort repository.Sort, p repository.Page) ([]models.Log, int64, error) {
+	if r.Err != nil {
+		return nil, 0, r.Err
+	}
+	var matches []models.Log
+	for _, log := range r.Logs {
+		if filter.Method != "" && log.Method != filter.Method {
+			continue
+		}
+		if filter.StatusCode != 0 && log.StatusCode != filter.StatusCode {
+			continue
+		}
+		matches = append(matches, log)
+	}
+	return page(matches, p), int64(len(matches)), nil
+}
+
+func (r *LogRepository) Stats(ctx context.Context) (*dto.LogStatsResponse, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	stats := r.StatsData
+	return &stats, nil
+}
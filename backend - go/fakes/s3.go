@@ -0,0 +1,197 @@
+package fakes
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// S3 is an in-process S3-compatible server covering what utils/minio.go uses: bucket
+// creation and lookup, and putting, getting, stating and deleting objects. Requests are
+// not authenticated and only path-style addressing is supported.
+type S3 struct {
+	Server *httptest.Server
+
+	mu      sync.Mutex
+	buckets map[string]map[string]s3Object
+}
+
+type s3Object struct {
+	data        []byte
+	contentType string
+	modified    time.Time
+}
+
+// NewS3 starts an S3 server; close it with Close
+func NewS3() *S3 {
+	s := &S3{buckets: make(map[string]map[string]s3Object)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Endpoint returns the host:port to use as MINIO_ENDPOINT
+func (s *S3) Endpoint() string {
+	return strings.TrimPrefix(s.Server.URL, "http://")
+}
+
+// Close shuts the server down
+func (s *S3) Close() {
+	s.Server.Close()
+}
+
+// Object returns the content of an object and whether it exists
+func (s *S3) Object(bucket, key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	object, ok := s.buckets[bucket][key]
+	return object.data, ok
+}
+
+// Objects returns the number of objects in a bucket
+func (s *S3) Objects(bucket string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buckets[bucket])
+}
+
+func (s *S3) serve(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket == "" {
+		s3Error(w, http.StatusNotImplemented, "NotImplemented", "Listing buckets is not supported")
+		return
+	}
+	if key == "" {
+		s.serveBucket(w, r, bucket)
+		return
+	}
+	s.serveObject(w, r, bucket, key)
+}
+
+func (s *S3) serveBucket(w http.ResponseWriter, r *http.Request, bucket string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, exists := s.buckets[bucket]
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Query().Has("location"):
+		if !exists {
+			s3Error(w, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?><LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></LocationConstraint>`)
+	case r.Method == http.MethodHead:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case r.Method == http.MethodPut:
+		if exists {
+			s3Error(w, http.StatusConflict, "BucketAlreadyOwnedByYou", "The bucket already exists")
+			return
+		}
+		s.buckets[bucket] = make(map[string]s3Object)
+	default:
+		s3Error(w, http.StatusNotImplemented, "NotImplemented", "Unsupported bucket operation")
+	}
+}
+
+func (s *S3) serveObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	s.mu.Lock()
+	objects, exists := s.buckets[bucket]
+	s.mu.Unlock()
+	if !exists {
+		s3Error(w, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		data, err := readS3Body(r)
+		if err != nil {
+			s3Error(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+			return
+		}
+		object := s3Object{data: data, contentType: r.Header.Get("Content-Type"), modified: time.Now().UTC()}
+		s.mu.Lock()
+		objects[key] = object
+		s.mu.Unlock()
+		w.Header().Set("ETag", object.etag())
+	case http.MethodGet, http.MethodHead:
+		s.mu.Lock()
+		object, ok := objects[key]
+		s.mu.Unlock()
+		if !ok {
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			s3Error(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist")
+			return
+		}
+		if object.contentType != "" {
+			w.Header().Set("Content-Type", object.contentType)
+		}
+		w.Header().Set("ETag", object.etag())
+		http.ServeContent(w, r, key, object.modified, bytes.NewReader(object.data))
+	case http.MethodDelete:
+		s.mu.Lock()
+		delete(objects, key)
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		s3Error(w, http.StatusNotImplemented, "NotImplemented", "Unsupported object operation")
+	}
+}
+
+func (o s3Object) etag() string {
+	sum := md5.Sum(o.data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// readS3Body returns the object content of a PUT, decoding aws-chunked bodies
+// (used by minio-go for streaming uploads over plain HTTP)
+func readS3Body(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") &&
+		!strings.Contains(r.Header.Get("Content-Encoding"), "aws-chunked") {
+		return io.ReadAll(r.Body)
+	}
+
+	var data bytes.Buffer
+	reader := bufio.NewReader(r.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chunk header: %w", err)
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chunk size %q", sizeHex)
+		}
+		if size == 0 {
+			// Trailing headers (checksums) are not verified
+			return data.Bytes(), nil
+		}
+		if _, err := io.CopyN(&data, reader, size); err != nil {
+			return nil, fmt.Errorf("failed to read chunk: %w", err)
+		}
+		if _, err := reader.Discard(2); err != nil {
+			return nil, fmt.Errorf("failed to read chunk: %w", err)
+		}
+	}
+}
+
+func s3Error(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>%s</Code><Message>%s</Message></Error>`, code, message)
+}
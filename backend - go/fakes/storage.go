@@ -0,0 +1,44 @@
+package fakes
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// Storage is an in-memory repository.Storage
+type Storage struct {
+	Files   map[string][]byte
+	SaveErr error // Returned by Save when set
+}
+
+// NewStorage returns an empty Storage
+func NewStorage() *Storage {
+	return &Storage{Files: make(map[string][]byte)}
+}
+
+func (s *Storage) Open(ctx context.Context, filename string) (io.ReadCloser, int64, error) {
+	data, ok := s.Files[filename]
+	if !ok {
+		return nil, 0, fmt.Errorf("file %s not found", filename)
+	}
+	return io.NopCloser(bytes.NewReader(data)), int64(len(data)), nil
+}
+
+func (s *Storage) Save(ctx context.Context, filename string, reader io.Reader, size int64) error {
+	if s.SaveErr != nil {
+		return s.SaveErr
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	s.Files[filename] = data
+	return nil
+}
+
+func (s *Storage) Delete(ctx context.Context, filename string) error {
+	delete(s.Files, filename)
+	return nil
+}
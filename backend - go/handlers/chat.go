@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"backend-go/dto"
+	"backend-go/services"
+	"backend-go/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ChatHandler serves the chat endpoint
+type ChatHandler struct {
+	chat *services.ChatService
+}
+
+// NewChatHandler returns a ChatHandler using the given service
+func NewChatHandler(chat *services.ChatService) *ChatHandler {
+	return &ChatHandler{chat: chat}
+}
+
+// Chat handles POST /chat, relaying the AI service's reply and status as-is
+func (h *ChatHandler) Chat(c *fiber.Ctx) error {
+	var req dto.ChatRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   "invalid_request",
+			"message": "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	reply, err := h.chat.Chat(c.UserContext(), req)
+	if err != nil {
+		var apiErr *utils.PythonAPIError
+		if errors.As(err, &apiErr) {
+			utils.SetRetryAfter(c, apiErr.RetryAfter)
+			c.Set("Content-Type", "application/json")
+			return c.Status(apiErr.StatusCode).SendString(apiErr.Body)
+		}
+		return sendError(c, err)
+	}
+
+	c.Set("Content-Type", "application/json")
+	return c.Status(200).Send(reply)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"backend-go/fakes"
+	"backend-go/services"
+	"backend-go/utils"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestChat(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		reply      []byte
+		chatErr    error
+		wantStatus int
+		wantBody   string
+		wantRetry  string // Retry-After header
+	}{
+		{
+			name:       "relays the reply",
+			body:       `{"message":"hello"}`,
+			reply:      []byte(`{"reply":"hi","status":"success"}`),
+			wantStatus: 200,
+			wantBody:   `{"reply":"hi","status":"success"}`,
+		},
+		{
+			name:       "relays Python errors with their status",
+			body:       `{"message":"hello"}`,
+			chatErr:    &utils.PythonAPIError{StatusCode: 429, Body: `{"detail":"quota exceeded"}`, RetryAfter: 20 * time.Second},
+			wantStatus: 429,
+			wantBody:   `{"detail":"quota exceeded"}`,
+			wantRetry:  "20",
+		},
+		{
+			name:       "reports a paused AI service",
+			body:       `{"message":"hello"}`,
+			chatErr:    &utils.CircuitOpenError{Endpoint: "chat", RetryAfter: 1500 * time.Millisecond},
+			wantStatus: 503,
+			wantRetry:  "2",
+		},
+		{
+			name:       "reports unreachable AI service",
+			body:       `{"message":"hello"}`,
+			chatErr:    errors.New("connection refused"),
+			wantStatus: 503,
+		},
+		{
+			name:       "rejects malformed requests",
+			body:       `{"message":`,
+			wantStatus: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ai := &fakes.AI{Reply: tt.reply, ChatErr: tt.chatErr}
+			handler := NewChatHandler(services.NewChatService(&fakes.SummaryRepository{}, ai))
+			app := fiber.New()
+			app.Post("/chat", handler.Chat)
+
+			req := httptest.NewRequest("POST", "/chat", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := resp.Header.Get("Retry-After"); got != tt.wantRetry {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetry)
+			}
+			if tt.wantBody != "" {
+				data, _ := io.ReadAll(resp.Body)
+				if string(data) != tt.wantBody {
+					t.Errorf("body = %s, want %s", data, tt.wantBody)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"backend-go/services"
+	"backend-go/utils"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// sendError writes the JSON error response for a failed service call
+func sendError(c *fiber.Ctx, err error) error {
+	var serviceErr *services.Error
+	if errors.As(err, &serviceErr) {
+		response := fiber.Map{
+			"error":   serviceErr.Code,
+			"message": serviceErr.Message,
+		}
+		if serviceErr.Err != nil {
+			response["details"] = serviceErr.Err.Error()
+		}
+		return c.Status(serviceErr.Status).JSON(response)
+	}
+
+	var apiErr *utils.PythonAPIError
+	var openErr *utils.CircuitOpenError
+	if errors.As(err, &apiErr) || errors.As(err, &openErr) {
+		return utils.SendPythonAPIError(c, err)
+	}
+
+	return c.Status(500).JSON(fiber.Map{
+		"error":   "server_error",
+		"message": "Unexpected error",
+		"details": err.Error(),
+	})
+}
+
+// paramID parses the :id route parameter, rejecting anything but a positive number
+func paramID(c *fiber.Ctx) (uint, error) {
+	id, err := utils.ParseID(c.Params("id"))
+	if err != nil {
+		return 0, &services.Error{Status: 400, Code: "invalid_request", Message: err.Error()}
+	}
+	return id, nil
+}
+
+// sendExport sends a rendered export as a file download
+func sendExport(c *fiber.Ctx, export *services.Export) error {
+	c.Set("Content-Type", export.ContentType)
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", export.Filename))
+	return c.Status(200).Send(export.Content)
+}
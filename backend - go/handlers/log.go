@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"backend-go/repository"
+	"backend-go/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LogHandler serves the request log endpoints
+type LogHandler struct {
+	logs *services.LogService
+}
+
+// NewLogHandler returns a LogHandler using the given service
+func NewLogHandler(logs *services.LogService) *LogHandler {
+	return &LogHandler{logs: logs}
+}
+
+// List handles GET /logs
+func (h *LogHandler) List(c *fiber.Ctx) error {
+	page := repository.ParsePage(c.QueryInt("page", 1), c.QueryInt("itemsperpage", 50), 50, 200)
+	sort := repository.ParseSort(c.Query("sort"), c.Query("order"), repository.LogSortColumns, repository.SortCreatedAt)
+	filter := repository.LogFilter{
+		Method:      c.Query("method", ""),
+		Path:        c.Query("path", ""),
+		StatusCode:  c.QueryInt("status", 0),
+		MinDuration: c.QueryInt("min_duration", 0),
+	}
+
+	logs, totalCount, err := h.logs.List(c.UserContext(), filter, sort, page)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"data":           logs,
+		"page":           page.Number,
+		"items_per_page": page.Size,
+		"total_pages":    page.TotalPages(totalCount),
+		"total_items":    totalCount,
+	})
+}
+
+// Stats handles GET /logs/stats
+func (h *LogHandler) Stats(c *fiber.Ctx) error {
+	stats, err := h.logs.Stats(c.UserContext())
+	if err != nil {
+		return sendError(c, err)
+	}
+	return c.Status(200).JSON(stats)
+}
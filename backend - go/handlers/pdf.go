@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"backend-go/dto"
+	"backend-go/repository"
+	"backend-go/services"
+	"backend-go/utils"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PDFHandler serves the PDF endpoints
+type PDFHandler struct {
+	pdfs *services.PDFService
+}
+
+// NewPDFHandler returns a PDFHandler using the given service
+func NewPDFHandler(pdfs *services.PDFService) *PDFHandler {
+	return &PDFHandler{pdfs: pdfs}
+}
+
+// List handles GET /pdf
+func (h *PDFHandler) List(c *fiber.Ctx) error {
+	page := repository.ParsePage(c.QueryInt("page", 1), c.QueryInt("itemsperpage", 10), 10, 100)
+	sort := repository.ParseSort(c.Query("sort"), c.Query("order"), repository.PDFSortColumns, repository.SortCreatedAt)
+	filter := repository.PDFFilter{Search: c.Query("search", "")}
+
+	if topic := c.Query("topic"); topic != "" {
+		topicID, err := strconv.ParseUint(topic, 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "topic must be a topic ID",
+			})
+		}
+		filter.TopicID = uint(topicID)
+	}
+
+	pdfs, totalCount, err := h.pdfs.List(c.UserContext(), filter, sort, page)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.PDFListResponse{
+		Data:         utils.ConvertPDFsToResponse(pdfs),
+		Page:         page.Number,
+		ItemsPerPage: page.Size,
+		TotalPages:   page.TotalPages(totalCount),
+		TotalItems:   totalCount,
+	})
+}
+
+// Count handles GET /pdf/count
+func (h *PDFHandler) Count(c *fiber.Ctx) error {
+	count, err := h.pdfs.Count(c.UserContext())
+	if err != nil {
+		return sendError(c, err)
+	}
+	return c.Status(200).JSON(dto.PDFCountResponse{Count: count})
+}
+
+// Create handles POST /pdf
+func (h *PDFHandler) Create(c *fiber.Ctx) error {
+	var req dto.PDFCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   "invalid_request",
+			"message": "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	pdf, err := h.pdfs.Create(c.UserContext(), req)
+	if err != nil {
+		return sendError(c, err)
+	}
+	return c.Status(201).JSON(utils.ConvertPDFToResponse(*pdf))
+}
+
+// Get handles GET /pdf/:id
+func (h *PDFHandler) Get(c *fiber.Ctx) error {
+	id, err := paramID(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	pdf, related, err := h.pdfs.Detail(c.UserContext(), id)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.PDFDetailResponse{
+		PDFResponse: utils.ConvertPDFToResponse(*pdf),
+		Related:     related,
+	})
+}
+
+// Similar handles GET /pdf/:id/similar, the nearest other PDFs by the similarity of
+// their averaged summary embeddings
+func (h *PDFHandler) Similar(c *fiber.Ctx) error {
+	id, err := paramID(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	similar, err := h.pdfs.Similar(c.UserContext(), id, c.QueryInt("limit", 10))
+	if err != nil {
+		return sendError(c, err)
+	}
+	return c.Status(200).JSON(fiber.Map{
+		"data": similar,
+	})
+}
+
+// Summaries handles GET /pdf/:id/summaries
+func (h *PDFHandler) Summaries(c *fiber.Ctx) error {
+	page := repository.ParsePage(c.QueryInt("page", 1), c.QueryInt("itemsperpage", 10), 10, 100)
+	sort := repository.ParseSort(c.Query("sort"), c.Query("order"), repository.SummarySortColumns, repository.SortCreatedAt)
+
+	id, err := paramID(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	summaries, totalCount, err := h.pdfs.Summaries(c.UserContext(), id, sort, page)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.SummaryListResponse{
+		Data:         utils.ConvertSummariesToResponse(summaries),
+		Page:         page.Number,
+		ItemsPerPage: page.Size,
+		TotalPages:   page.TotalPages(totalCount),
+		TotalItems:   totalCount,
+	})
+}
+
+// Download handles GET /pdf/:id/download
+func (h *PDFHandler) Download(c *fiber.Ctx) error {
+	id, err := paramID(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	pdf, file, size, err := h.pdfs.Open(c.UserContext(), id)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	c.Set("Content-Type", "application/pdf")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", pdf.Title+".pdf"))
+
+	// Fiber closes the stream once it has been sent
+	return c.SendStream(file, int(size))
+}
+
+// Export handles GET /pdf/:id/export. The summaries query parameter (1,2,3) selects
+// specific summaries; style and language filter them.
+func (h *PDFHandler) Export(c *fiber.Ctx) error {
+	summaryIDs, err := utils.ParseIDList(c.Query("summaries"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+	}
+
+	id, err := paramID(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	export, err := h.pdfs.Export(c.UserContext(), id, c.Query("format", "md"), repository.SummaryExportFilter{
+		IDs:      summaryIDs,
+		Style:    c.Query("style", ""),
+		Language: c.Query("language", ""),
+	})
+	if err != nil {
+		return sendError(c, err)
+	}
+	return sendExport(c, export)
+}
+
+// Delete handles DELETE /pdf/:id
+func (h *PDFHandler) Delete(c *fiber.Ctx) error {
+	id, err := paramID(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	if err = h.pdfs.Delete(c.UserContext(), id); err != nil {
+		return sendError(c, err)
+	}
+	return c.Status(200).JSON(fiber.Map{
+		"message": "PDF deleted successfully",
+	})
+}
+
+// Upload handles POST /pdf/upload
+func (h *PDFHandler) Upload(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   "invalid_request",
+			"message": "File is required",
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "server_error",
+			"message": "Failed to open uploaded file",
+			"details": err.Error(),
+		})
+	}
+	defer file.Close()
+
+	pdf, err := h.pdfs.Upload(c.UserContext(), fileHeader.Filename, fileHeader.Size, c.FormValue("title"), file)
+	if err != nil {
+		return sendError(c, err)
+	}
+	return c.Status(201).JSON(utils.ConvertPDFToResponse(*pdf))
+}
+
+// Summarize handles POST /pdf/:id/summarize
+func (h *PDFHandler) Summarize(c *fiber.Ctx) error {
+	var req dto.SummarizeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   "invalid_request",
+			"message": "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	id, err := paramID(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	response, err := h.pdfs.Summarize(c.UserContext(), id, req)
+	if err != nil {
+		return sendError(c, err)
+	}
+	return c.Status(200).JSON(response)
+}
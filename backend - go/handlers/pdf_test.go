@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"backend-go/fakes"
+	"backend-go/models"
+	"backend-go/services"
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type pdfTestApp struct {
+	app     *fiber.App
+	pdfs    *fakes.PDFRepository
+	storage *fakes.Storage
+	ai      *fakes.AI
+}
+
+func newPDFTestApp() *pdfTestApp {
+	pdfs := &fakes.PDFRepository{}
+	storage := fakes.NewStorage()
+	ai := &fakes.AI{}
+	service := services.NewPDFService(pdfs, &fakes.SummaryRepository{}, storage, ai)
+	service.CountPages = func(string) int { return 2 }
+	handler := NewPDFHandler(service)
+
+	app := fiber.New()
+	app.Get("/pdf", handler.List)
+	app.Get("/pdf/:id", handler.Get)
+	app.Get("/pdf/:id/download", handler.Download)
+	app.Get("/pdf/:id/export", handler.Export)
+	app.Delete("/pdf/:id", handler.Delete)
+	app.Post("/pdf/upload", handler.Upload)
+	app.Post("/pdf/:id/summarize", handler.Summarize)
+
+	return &pdfTestApp{app: app, pdfs: pdfs, storage: storage, ai: ai}
+}
+
+// do sends a request and decodes the JSON response body into a map
+func do(t *testing.T, app *fiber.App, req *http.Request) (int, map[string]interface{}) {
+	t.Helper()
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body := map[string]interface{}{}
+	data, _ := io.ReadAll(resp.Body)
+	if len(data) > 0 && strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Fatalf("invalid JSON response %q: %v", data, err)
+		}
+	}
+	return resp.StatusCode, body
+}
+
+func uploadRequest(t *testing.T, filename, title string, content []byte) *http.Request {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if filename != "" {
+		part, err := writer.CreateFormFile("file", filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(content)
+	}
+	if title != "" {
+		writer.WriteField("title", title)
+	}
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/pdf/upload", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestPDFRoutes(t *testing.T) {
+	tests := []struct {
+		name       string
+		seed       bool
+		request    func(t *testing.T) *http.Request
+		wantStatus int
+		wantError  string
+		check      func(t *testing.T, env *pdfTestApp, body map[string]interface{})
+	}{
+		{
+			name:       "lists PDFs",
+			seed:       true,
+			request:    func(t *testing.T) *http.Request { return httptest.NewRequest("GET", "/pdf?page=1&itemsperpage=5", nil) },
+			wantStatus: 200,
+			check: func(t *testing.T, env *pdfTestApp, body map[string]interface{}) {
+				if body["totalItems"] != float64(1) || body["itemsPerPage"] != float64(5) {
+					t.Errorf("unexpected pagination: %v", body)
+				}
+			},
+		},
+		{
+			name:       "rejects invalid topic filters",
+			request:    func(t *testing.T) *http.Request { return httptest.NewRequest("GET", "/pdf?topic=abc", nil) },
+			wantStatus: 400,
+			wantError:  "invalid_request",
+		},
+		{
+			name:       "gets a PDF",
+			seed:       true,
+			request:    func(t *testing.T) *http.Request { return httptest.NewRequest("GET", "/pdf/1", nil) },
+			wantStatus: 200,
+			check: func(t *testing.T, env *pdfTestApp, body map[string]interface{}) {
+				if body["title"] != "Doc" {
+					t.Errorf("title = %v, want Doc", body["title"])
+				}
+				if related, ok := body["related"].([]interface{}); !ok || len(related) != 0 {
+					t.Errorf("related = %v, want an empty list", body["related"])
+				}
+			},
+		},
+		{
+			name:       "reports missing PDFs",
+			request:    func(t *testing.T) *http.Request { return httptest.NewRequest("GET", "/pdf/1", nil) },
+			wantStatus: 404,
+			wantError:  "not_found",
+		},
+		{
+			name:       "rejects invalid IDs",
+			seed:       true,
+			request:    func(t *testing.T) *http.Request { return httptest.NewRequest("GET", "/pdf/abc", nil) },
+			wantStatus: 400,
+			wantError:  "invalid_request",
+		},
+		{
+			name:       "does not pass IDs on as SQL",
+			seed:       true,
+			request:    func(t *testing.T) *http.Request { return httptest.NewRequest("DELETE", "/pdf/id%3E0", nil) },
+			wantStatus: 400,
+			wantError:  "invalid_request",
+		},
+		{
+			name:       "downloads the stored file",
+			seed:       true,
+			request:    func(t *testing.T) *http.Request { return httptest.NewRequest("GET", "/pdf/1/download", nil) },
+			wantStatus: 200,
+		},
+		{
+			name:       "exports the summaries of a PDF",
+			seed:       true,
+			request:    func(t *testing.T) *http.Request { return httptest.NewRequest("GET", "/pdf/1/export?format=json", nil) },
+			wantStatus: 200,
+			check: func(t *testing.T, env *pdfTestApp, body map[string]interface{}) {
+				if body["title"] != "Doc" {
+					t.Errorf("title = %v, want Doc", body["title"])
+				}
+			},
+		},
+		{
+			name:       "rejects unknown export formats",
+			seed:       true,
+			request:    func(t *testing.T) *http.Request { return httptest.NewRequest("GET", "/pdf/1/export?format=pdf", nil) },
+			wantStatus: 400,
+			wantError:  "invalid_format",
+		},
+		{
+			name: "rejects invalid summary selections",
+			seed: true,
+			request: func(t *testing.T) *http.Request {
+				return httptest.NewRequest("GET", "/pdf/1/export?summaries=1,x", nil)
+			},
+			wantStatus: 400,
+			wantError:  "invalid_request",
+		},
+		{
+			name:       "deletes a PDF",
+			seed:       true,
+			request:    func(t *testing.T) *http.Request { return httptest.NewRequest("DELETE", "/pdf/1", nil) },
+			wantStatus: 200,
+			check: func(t *testing.T, env *pdfTestApp, body map[string]interface{}) {
+				if len(env.pdfs.PDFs) != 0 || len(env.storage.Files) != 0 {
+					t.Errorf("expected the record and file to be deleted")
+				}
+			},
+		},
+		{
+			name:       "uploads a PDF",
+			request:    func(t *testing.T) *http.Request { return uploadRequest(t, "paper.pdf", "My paper", []byte("%PDF-1.4")) },
+			wantStatus: 201,
+			check: func(t *testing.T, env *pdfTestApp, body map[string]interface{}) {
+				if body["title"] != "My paper" || body["page_count"] != float64(2) {
+					t.Errorf("unexpected response: %v", body)
+				}
+				if len(env.storage.Files) != 1 {
+					t.Errorf("expected the file to be stored")
+				}
+			},
+		},
+		{
+			name:       "requires a file",
+			request:    func(t *testing.T) *http.Request { return uploadRequest(t, "", "My paper", nil) },
+			wantStatus: 400,
+			wantError:  "invalid_request",
+		},
+		{
+			name:       "rejects non-PDF uploads",
+			request:    func(t *testing.T) *http.Request { return uploadRequest(t, "paper.docx", "", []byte("PK")) },
+			wantStatus: 400,
+			wantError:  "invalid_file",
+		},
+		{
+			name: "rejects malformed summarize requests",
+			seed: true,
+			request: func(t *testing.T) *http.Request {
+				req := httptest.NewRequest("POST", "/pdf/1/summarize", strings.NewReader("{"))
+				req.Header.Set("Content-Type", "application/json")
+				return req
+			},
+			wantStatus: 400,
+			wantError:  "invalid_request",
+		},
+		{
+			name: "validates summary styles",
+			seed: true,
+			request: func(t *testing.T) *http.Request {
+				req := httptest.NewRequest("POST", "/pdf/1/summarize", strings.NewReader(`{"style":"poem","language":"en"}`))
+				req.Header.Set("Content-Type", "application/json")
+				return req
+			},
+			wantStatus: 400,
+			wantError:  "invalid_style",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newPDFTestApp()
+			if tt.seed {
+				env.pdfs.PDFs = []models.PDF{{Filename: "stored.pdf", Title: "Doc", PageCount: 1}}
+				env.pdfs.PDFs[0].ID = 1
+				env.storage.Files["stored.pdf"] = []byte("%PDF")
+			}
+
+			status, body := do(t, env.app, tt.request(t))
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+			}
+			if tt.wantError != "" && body["error"] != tt.wantError {
+				t.Errorf("error = %v, want %s", body["error"], tt.wantError)
+			}
+			if tt.check != nil {
+				tt.check(t, env, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"backend-go/dto"
+	"backend-go/repository"
+	"backend-go/services"
+	"backend-go/utils"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SummaryHandler serves the summary endpoints
+type SummaryHandler struct {
+	summaries *services.SummaryService
+}
+
+// NewSummaryHandler returns a SummaryHandler using the given service
+func NewSummaryHandler(summaries *services.SummaryService) *SummaryHandler {
+	return &SummaryHandler{summaries: summaries}
+}
+
+// List handles GET /summaries
+func (h *SummaryHandler) List(c *fiber.Ctx) error {
+	page := repository.ParsePage(c.QueryInt("page", 1), c.QueryInt("itemsperpage", 10), 10, 100)
+	sort := repository.ParseSort(c.Query("sort"), c.Query("order"), repository.SummarySortColumns, repository.SortCreatedAt)
+	query := services.SummaryQuery{
+		Search:   c.Query("search", ""),
+		PDFID:    uint(max(c.QueryInt("pdf", 0), 0)),
+		Style:    c.Query("style", ""),
+		Language: c.Query("language", ""),
+	}
+
+	// Synthesized summaries have no single PDF
+	switch c.Query("synthesized") {
+	case "true":
+		synthesized := true
+		query.Synthesized = &synthesized
+	case "false":
+		synthesized := false
+		query.Synthesized = &synthesized
+	}
+
+	summaries, totalCount, err := h.summaries.List(c.UserContext(), query, sort, page)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.SummaryListResponse{
+		Data:         utils.ConvertSummariesToResponse(summaries),
+		Page:         page.Number,
+		ItemsPerPage: page.Size,
+		TotalPages:   page.TotalPages(totalCount),
+		TotalItems:   totalCount,
+	})
+}
+
+// Count handles GET /summaries/count
+func (h *SummaryHandler) Count(c *fiber.Ctx) error {
+	count, err := h.summaries.Count(c.UserContext())
+	if err != nil {
+		return sendError(c, err)
+	}
+	return c.Status(200).JSON(dto.SummaryCountResponse{Count: count})
+}
+
+// Stats handles GET /summaries/stats
+func (h *SummaryHandler) Stats(c *fiber.Ctx) error {
+	stats, err := h.summaries.Stats(c.UserContext())
+	if err != nil {
+		return sendError(c, err)
+	}
+	return c.Status(200).JSON(stats)
+}
+
+// Compare handles GET /summaries/compare?a=1&b=2
+func (h *SummaryHandler) Compare(c *fiber.Ctx) error {
+	idA := uint(max(c.QueryInt("a", 0), 0))
+	idB := uint(max(c.QueryInt("b", 0), 0))
+
+	response, err := h.summaries.Compare(c.UserContext(), idA, idB)
+	if err != nil {
+		return sendError(c, err)
+	}
+	return c.Status(200).JSON(response)
+}
+
+// Synthesize handles POST /summaries/synthesize
+func (h *SummaryHandler) Synthesize(c *fiber.Ctx) error {
+	var req dto.SynthesizeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   "invalid_request",
+			"message": "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	summary, err := h.summaries.Synthesize(c.UserContext(), req)
+	if err != nil {
+		return sendError(c, err)
+	}
+	return c.Status(201).JSON(utils.ConvertSummaryToResponse(*summary))
+}
+
+// Get handles GET /summaries/:id
+func (h *SummaryHandler) Get(c *fiber.Ctx) error {
+	id, err := paramID(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	summary, err := h.summaries.Get(c.UserContext(), id)
+	if err != nil {
+		return sendError(c, err)
+	}
+	return c.Status(200).JSON(utils.ConvertSummaryToResponse(*summary))
+}
+
+// Translate handles POST /summaries/:id/translate
+func (h *SummaryHandler) Translate(c *fiber.Ctx) error {
+	var req dto.TranslateSummaryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   "invalid_request",
+			"message": "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	id, err := paramID(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	summary, err := h.summaries.Translate(c.UserContext(), id, req.Language)
+	if err != nil {
+		return sendError(c, err)
+	}
+	return c.Status(201).JSON(utils.ConvertSummaryToResponse(*summary))
+}
+
+// Update handles PATCH /summaries/:id
+func (h *SummaryHandler) Update(c *fiber.Ctx) error {
+	var req dto.SummaryUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   "invalid_request",
+			"message": "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	id, err := paramID(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	summary, err := h.summaries.Update(c.UserContext(), id, req)
+	if err != nil {
+		return sendError(c, err)
+	}
+	return c.Status(200).JSON(utils.ConvertSummaryToResponse(*summary))
+}
+
+// Pin handles POST /summaries/:id/pin
+func (h *SummaryHandler) Pin(c *fiber.Ctx) error {
+	id, err := paramID(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	pdf, err := h.summaries.Pin(c.UserContext(), id)
+	if err != nil {
+		return sendError(c, err)
+	}
+	return c.Status(200).JSON(utils.ConvertPDFToResponse(*pdf))
+}
+
+// Unpin handles DELETE /summaries/:id/pin
+func (h *SummaryHandler) Unpin(c *fiber.Ctx) error {
+	id, err := paramID(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	pdf, err := h.summaries.Unpin(c.UserContext(), id)
+	if err != nil {
+		return sendError(c, err)
+	}
+	return c.Status(200).JSON(utils.ConvertPDFToResponse(*pdf))
+}
+
+// AddFeedback handles POST /summaries/:id/feedback
+func (h *SummaryHandler) AddFeedback(c *fiber.Ctx) error {
+	var req dto.FeedbackRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   "invalid_request",
+			"message": "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	id, err := paramID(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	feedback, err := h.summaries.AddFeedback(c.UserContext(), id, req)
+	if err != nil {
+		return sendError(c, err)
+	}
+	return c.Status(201).JSON(utils.ConvertFeedbackToResponse(*feedback))
+}
+
+// Feedback handles GET /summaries/:id/feedback
+func (h *SummaryHandler) Feedback(c *fiber.Ctx) error {
+	id, err := paramID(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	feedback, err := h.summaries.Feedback(c.UserContext(), id)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	data := make([]dto.FeedbackResponse, len(feedback))
+	for i, f := range feedback {
+		data[i] = utils.ConvertFeedbackToResponse(f)
+	}
+	return c.Status(200).JSON(fiber.Map{
+		"data": data,
+	})
+}
+
+// Export handles GET /summaries/:id/export
+func (h *SummaryHandler) Export(c *fiber.Ctx) error {
+	id, err := paramID(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	export, err := h.summaries.Export(c.UserContext(), id, c.Query("format", "md"))
+	if err != nil {
+		return sendError(c, err)
+	}
+	return sendExport(c, export)
+}
+
+// Delete handles DELETE /summaries/:id
+func (h *SummaryHandler) Delete(c *fiber.Ctx) error {
+	id, err := paramID(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	if err = h.summaries.Delete(c.UserContext(), id); err != nil {
+		return sendError(c, err)
+	}
+	return c.Status(200).JSON(fiber.Map{
+		"message": "Summary deleted successfully",
+	})
+}
+
+// DeleteMany handles DELETE /summaries/bulk
+func (h *SummaryHandler) DeleteMany(c *fiber.Ctx) error {
+	var req struct {
+		IDs []uint `json:"ids"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   "invalid_request",
+			"message": "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	deleted, err := h.summaries.DeleteMany(c.UserContext(), req.IDs)
+	if err != nil {
+		return sendError(c, err)
+	}
+	return c.Status(200).JSON(fiber.Map{
+		"message":       fmt.Sprintf("Successfully deleted %d summaries", deleted),
+		"deleted_count": deleted,
+	})
+}
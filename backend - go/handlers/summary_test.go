@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"backend-go/dto"
+	"backend-go/fakes"
+	"backend-go/models"
+	"backend-go/services"
+	"backend-go/utils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pgvector/pgvector-go"
+)
+
+type summaryTestApp struct {
+	app       *fiber.App
+	summaries *fakes.SummaryRepository
+	pdfs      *fakes.PDFRepository
+	ai        *fakes.AI
+}
+
+// newSummaryTestApp serves the summary routes over two PDFs, two summaries of the
+// first PDF and a synthesis of both
+func newSummaryTestApp() *summaryTestApp {
+	pdfID := uint(1)
+	pdfs := &fakes.PDFRepository{PDFs: []models.PDF{{Title: "Doc"}, {Title: "Other"}}}
+	summaries := &fakes.SummaryRepository{Summaries: []models.Summaries{
+		{Content: "Revenue grew. Costs fell.", PDFID: &pdfID, Style: "general", Language: "en", Embedding: pgvector.NewVector([]float32{1, 0})},
+		{Content: "Revenue grew. Costs rose.", PDFID: &pdfID, Style: "general", Language: "en", Embedding: pgvector.NewVector([]float32{1, 0})},
+		{Content: "Both documents cover revenue.", Style: "general", Language: "en", Sources: []models.SummarySource{{PDFID: 1}, {PDFID: 2, Position: 1}}},
+	}}
+	for i := range pdfs.PDFs {
+		pdfs.PDFs[i].ID = uint(i + 1)
+	}
+	for i := range summaries.Summaries {
+		summaries.Summaries[i].ID = uint(i + 1)
+	}
+	ai := &fakes.AI{}
+	handler := NewSummaryHandler(services.NewSummaryService(summaries, pdfs, ai))
+
+	app := fiber.New()
+	app.Get("/summaries/stats", handler.Stats)
+	app.Get("/summaries/compare", handler.Compare)
+	app.Post("/summaries/synthesize", handler.Synthesize)
+	app.Post("/summaries/:id/translate", handler.Translate)
+	app.Patch("/summaries/:id", handler.Update)
+	app.Post("/summaries/:id/pin", handler.Pin)
+	app.Delete("/summaries/:id/pin", handler.Unpin)
+	app.Post("/summaries/:id/feedback", handler.AddFeedback)
+	app.Get("/summaries/:id/feedback", handler.Feedback)
+	app.Get("/summaries/:id/export", handler.Export)
+
+	return &summaryTestApp{app: app, summaries: summaries, pdfs: pdfs, ai: ai}
+}
+
+func jsonRequest(method, target, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestSummaryRoutes(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(env *summaryTestApp)
+		request    *http.Request
+		wantStatus int
+		wantError  string
+		check      func(t *testing.T, env *summaryTestApp, body map[string]interface{})
+	}{
+		{
+			name:       "reports statistics",
+			setup:      func(env *summaryTestApp) { env.summaries.StatsData.TotalSummaries = 3 },
+			request:    httptest.NewRequest("GET", "/summaries/stats", nil),
+			wantStatus: 200,
+			check: func(t *testing.T, env *summaryTestApp, body map[string]interface{}) {
+				if body["total_summaries"] != float64(3) {
+					t.Errorf("total_summaries = %v, want 3", body["total_summaries"])
+				}
+			},
+		},
+		{
+			name:       "compares two summaries",
+			request:    httptest.NewRequest("GET", "/summaries/compare?a=1&b=2", nil),
+			wantStatus: 200,
+			check: func(t *testing.T, env *summaryTestApp, body map[string]interface{}) {
+				if body["similarity"] != float64(1) {
+					t.Errorf("similarity = %v, want 1", body["similarity"])
+				}
+				if diff, ok := body["sentence_diff"].([]interface{}); !ok || len(diff) == 0 {
+					t.Errorf("expected a sentence diff, got %v", body["sentence_diff"])
+				}
+			},
+		},
+		{
+			name:       "requires both summaries to compare",
+			request:    httptest.NewRequest("GET", "/summaries/compare?a=1", nil),
+			wantStatus: 400,
+			wantError:  "invalid_request",
+		},
+		{
+			name:       "reports missing summaries to compare",
+			request:    httptest.NewRequest("GET", "/summaries/compare?a=1&b=9", nil),
+			wantStatus: 404,
+			wantError:  "not_found",
+		},
+		{
+			name: "synthesizes PDFs in the requested order",
+			setup: func(env *summaryTestApp) {
+				env.ai.Synthesis = &dto.PythonSynthesizeResponse{Synthesis: "Both grew.", Embedding: []float32{0, 1}}
+			},
+			request:    jsonRequest("POST", "/summaries/synthesize", `{"pdf_ids":[2,1],"language":"en"}`),
+			wantStatus: 201,
+			check: func(t *testing.T, env *summaryTestApp, body map[string]interface{}) {
+				if body["content"] != "Both grew." || body["style"] != "general" {
+					t.Errorf("unexpected synthesis: %v", body)
+				}
+				sources, _ := body["sources"].([]interface{})
+				if len(sources) != 2 || sources[0].(map[string]interface{})["pdf_id"] != float64(2) {
+					t.Errorf("sources = %v, want PDF 2 first", body["sources"])
+				}
+			},
+		},
+		{
+			name:       "reports missing PDFs to synthesize",
+			request:    jsonRequest("POST", "/summaries/synthesize", `{"pdf_ids":[1,9]}`),
+			wantStatus: 404,
+			wantError:  "not_found",
+		},
+		{
+			name: "translates a summary",
+			setup: func(env *summaryTestApp) {
+				env.ai.Translation = &dto.PythonTranslateResponse{Translation: "Der Umsatz wuchs."}
+			},
+			request:    jsonRequest("POST", "/summaries/1/translate", `{"language":"de"}`),
+			wantStatus: 201,
+			check: func(t *testing.T, env *summaryTestApp, body map[string]interface{}) {
+				if body["language"] != "de" || body["source_summary_id"] != float64(1) {
+					t.Errorf("unexpected translation: %v", body)
+				}
+				if got := env.ai.TranslateRequests[0].Text; got != "Revenue grew. Costs fell." {
+					t.Errorf("translated %q, want the summary text", got)
+				}
+			},
+		},
+		{
+			name:       "rejects translations into the same language",
+			request:    jsonRequest("POST", "/summaries/1/translate", `{"language":"English"}`),
+			wantStatus: 400,
+			wantError:  "invalid_language",
+		},
+		{
+			name: "relays Python errors when translating",
+			setup: func(env *summaryTestApp) {
+				env.ai.TranslateErr = &utils.PythonAPIError{StatusCode: 429, Body: `{"detail":"quota exceeded"}`}
+			},
+			request:    jsonRequest("POST", "/summaries/1/translate", `{"language":"de"}`),
+			wantStatus: 429,
+			wantError:  "backend_error",
+		},
+		{
+			name:       "edits a summary",
+			setup:      func(env *summaryTestApp) { env.ai.Embedding = []float32{0, 1} },
+			request:    jsonRequest("PATCH", "/summaries/1", `{"content":"Revenue doubled.","edited_by":"ana"}`),
+			wantStatus: 200,
+			check: func(t *testing.T, env *summaryTestApp, body map[string]interface{}) {
+				if body["content"] != "Revenue doubled." || body["original_content"] != "Revenue grew. Costs fell." {
+					t.Errorf("unexpected edit: %v", body)
+				}
+				if got := env.summaries.Summaries[0].Embedding.Slice(); len(got) != 2 || got[1] != 1 {
+					t.Errorf("embedding = %v, want the edit's embedding", got)
+				}
+			},
+		},
+		{
+			name:       "keeps the stored embedding when re-embedding fails",
+			setup:      func(env *summaryTestApp) { env.ai.EmbedErr = &utils.PythonAPIError{StatusCode: 500} },
+			request:    jsonRequest("PATCH", "/summaries/1", `{"content":"Revenue doubled."}`),
+			wantStatus: 200,
+			check: func(t *testing.T, env *summaryTestApp, body map[string]interface{}) {
+				if got := env.summaries.Summaries[0].Embedding.Slice(); len(got) != 2 || got[0] != 1 {
+					t.Errorf("embedding = %v, want the stored one", got)
+				}
+			},
+		},
+		{
+			name:       "rejects empty edits",
+			request:    jsonRequest("PATCH", "/summaries/1", `{"content":"  "}`),
+			wantStatus: 400,
+			wantError:  "invalid_request",
+		},
+		{
+			name:       "pins a summary",
+			request:    httptest.NewRequest("POST", "/summaries/2/pin", nil),
+			wantStatus: 200,
+			check: func(t *testing.T, env *summaryTestApp, body map[string]interface{}) {
+				if pinned := env.pdfs.PDFs[0].PinnedSummaryID; pinned == nil || *pinned != 2 {
+					t.Errorf("pinned summary = %v, want 2", pinned)
+				}
+			},
+		},
+		{
+			name:       "does not pin syntheses",
+			request:    httptest.NewRequest("POST", "/summaries/3/pin", nil),
+			wantStatus: 400,
+			wantError:  "invalid_request",
+		},
+		{
+			name: "unpins a pinned summary",
+			setup: func(env *summaryTestApp) {
+				pinned := uint(2)
+				env.pdfs.PDFs[0].PinnedSummaryID = &pinned
+			},
+			request:    httptest.NewRequest("DELETE", "/summaries/2/pin", nil),
+			wantStatus: 200,
+			check: func(t *testing.T, env *summaryTestApp, body map[string]interface{}) {
+				if pinned := env.pdfs.PDFs[0].PinnedSummaryID; pinned != nil {
+					t.Errorf("pinned summary = %v, want none", *pinned)
+				}
+			},
+		},
+		{
+			name:       "does not unpin summaries that are not pinned",
+			request:    httptest.NewRequest("DELETE", "/summaries/1/pin", nil),
+			wantStatus: 400,
+			wantError:  "invalid_request",
+		},
+		{
+			name:       "flags summaries with reported issues",
+			request:    jsonRequest("POST", "/summaries/1/feedback", `{"vote":-1,"issues":["Inaccurate"]}`),
+			wantStatus: 201,
+			check: func(t *testing.T, env *summaryTestApp, body map[string]interface{}) {
+				if body["summary_id"] != float64(1) {
+					t.Errorf("summary_id = %v, want 1", body["summary_id"])
+				}
+				if !env.summaries.Summaries[0].Flagged {
+					t.Errorf("expected the summary to be flagged")
+				}
+			},
+		},
+		{
+			name:       "validates feedback",
+			request:    jsonRequest("POST", "/summaries/1/feedback", `{"vote":2}`),
+			wantStatus: 400,
+			wantError:  "invalid_feedback",
+		},
+		{
+			name: "lists feedback",
+			setup: func(env *summaryTestApp) {
+				env.summaries.Feedbacks = []models.SummaryFeedback{{SummaryID: 1, Vote: 1}, {SummaryID: 2, Vote: -1}}
+			},
+			request:    httptest.NewRequest("GET", "/summaries/1/feedback", nil),
+			wantStatus: 200,
+			check: func(t *testing.T, env *summaryTestApp, body map[string]interface{}) {
+				if data, _ := body["data"].([]interface{}); len(data) != 1 {
+					t.Errorf("data = %v, want the feedback of summary 1", body["data"])
+				}
+			},
+		},
+		{
+			name:       "reports feedback on missing summaries",
+			request:    httptest.NewRequest("GET", "/summaries/9/feedback", nil),
+			wantStatus: 404,
+			wantError:  "not_found",
+		},
+		{
+			name:       "exports a summary",
+			request:    httptest.NewRequest("GET", "/summaries/1/export?format=HTML", nil),
+			wantStatus: 200,
+		},
+		{
+			name:       "rejects unknown export formats",
+			request:    httptest.NewRequest("GET", "/summaries/1/export?format=pdf", nil),
+			wantStatus: 400,
+			wantError:  "invalid_format",
+		},
+		{
+			name:       "rejects invalid IDs",
+			request:    httptest.NewRequest("POST", "/summaries/abc/pin", nil),
+			wantStatus: 400,
+			wantError:  "invalid_request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newSummaryTestApp()
+			if tt.setup != nil {
+				tt.setup(env)
+			}
+
+			status, body := do(t, env.app, tt.request)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+			}
+			if tt.wantError != "" && body["error"] != tt.wantError {
+				t.Errorf("error = %v, want %s", body["error"], tt.wantError)
+			}
+			if tt.check != nil {
+				tt.check(t, env, body)
+			}
+		})
+	}
+}
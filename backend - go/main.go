@@ -1,60 +1,142 @@
 package main
 
 import (
+	"backend-go/backup"
+	"backend-go/config"
 	"backend-go/dto"
+	"backend-go/handlers"
 	"backend-go/models"
+	"backend-go/repository"
+	"backend-go/services"
+	"backend-go/topics"
 	"backend-go/utils"
-	"bytes"
-	"database/sql"
-	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
-	"mime/multipart"
-	"net/http"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
-	"github.com/extemporalgenome/npdfpages"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/google/uuid"
-	"github.com/pgvector/pgvector-go"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func main() {
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn == "" {
-		dsn = "host=localhost user=postgres password=postgres dbname=ai_pdf_management port=5432 sslmode=disable TimeZone=Asia/Shanghai"
+	cfg, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// "config print" needs neither the database nor storage, and also shows invalid configurations
+	if len(args) > 0 && args[0] == "config" {
+		if err := runConfig(cfg, args[1:]); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Printf("Invalid configuration:\n%v\n", err)
+		os.Exit(1)
+	}
+	utils.SetPythonAPI(cfg.PythonAPI)
+	utils.SetVectorIndex(cfg.VectorIndex)
+	utils.SetQuiz(cfg.Quiz)
+	if err := utils.SetLanguages(cfg.Languages); err != nil {
+		fmt.Printf("Invalid configuration:\n%v\n", err)
+		os.Exit(1)
 	}
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+
+	// Only the server limits statement time; backups and restores may run long
+	statementTimeout := cfg.Database.StatementTimeout
+	if isCommand(args) {
+		statementTimeout = 0
+	}
+	db, err := connectDatabase(cfg.Database.URL, statementTimeout)
 
 	if err != nil {
 		panic("failed to connect database")
 	}
 
 	// Initialize MinIO
-	if err := utils.InitMinIO(); err != nil {
+	if err := utils.InitMinIO(cfg.MinIO); err != nil {
 		fmt.Printf("Warning: Failed to initialize MinIO: %v\n", err)
 		fmt.Println("Continuing without MinIO support...")
 	} else {
 		fmt.Println("MinIO initialized successfully")
 	}
 
+	// Embeddings of repeated text are served from the cache instead of spending AI quota
+	var cacheDB *gorm.DB
+	if cfg.Embeddings.PersistCache {
+		cacheDB = db
+	}
+	utils.SetEmbeddingCache(utils.NewEmbeddingCache(cfg.Embeddings.Model, cfg.Embeddings.CacheSize, cacheDB))
+
+	// CLI subcommands (backup, restore) run instead of the server
+	if ran, err := runCommand(db, args); ran {
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	jobs := &utils.Jobs{}
+	app := newApp(db, cfg, jobs)
+
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- app.Listen(cfg.Server.Address)
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-listenErr:
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	case sig := <-signals:
+		fmt.Printf("Received %s, shutting down (waiting up to %s)...\n", sig, cfg.Server.ShutdownTimeout)
+	}
+
+	if err := shutdown(app, jobs, db, cfg.Server.ShutdownTimeout); err != nil {
+		fmt.Printf("Error: Shutdown did not complete cleanly: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("Server stopped")
+}
+
+// newApp builds the HTTP server: middleware, services and every route.
+// Background work is started through jobs so shutdown can wait for it.
+func newApp(db *gorm.DB, cfg *config.Config, jobs *utils.Jobs) *fiber.App {
 	app := fiber.New(fiber.Config{
 		ErrorHandler: utils.ErrorHandler,
 	})
 
 	// Apply middleware
 	app.Use(cors.New(cors.Config{
-		AllowOrigins:     "http://localhost:3000",
-		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization",
+		AllowOrigins:     strings.Join(cfg.Server.CORSOrigins, ","),
+		AllowMethods:     "GET,POST,PUT,PATCH,DELETE,OPTIONS",
+		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,X-User-ID,X-Workspace-ID",
 		AllowCredentials: true,
 	}))
 
+	// Cancel a request's database, storage and AI calls when its client disconnects
+	app.Use(utils.RequestContextMiddleware())
+
 	// Enhanced logging middleware with database support
 	app.Use(utils.NewLoggingMiddleware(utils.LoggingConfig{
 		DB:                db,
@@ -63,10 +145,20 @@ func main() {
 		LogResponseBody:   false, // Set to true if you want to log response bodies (increases DB size)
 		MaxBodySize:       10000, // 10KB
 		SensitivePatterns: []string{`"password"\s*:\s*"[^"]*"`, `"api_key"\s*:\s*"[^"]*"`},
+		Jobs:              jobs,
 	}))
 
 	app.Use(utils.RateLimitMiddleware())
 
+	pdfRepository := repository.NewPDFRepository(db)
+	summaryRepository := repository.NewSummaryRepository(db)
+	ai := services.NewPythonAI()
+
+	pdfHandler := handlers.NewPDFHandler(services.NewPDFService(pdfRepository, summaryRepository, repository.NewObjectStorage(), ai))
+	summaryHandler := handlers.NewSummaryHandler(services.NewSummaryService(summaryRepository, pdfRepository, ai))
+	logHandler := handlers.NewLogHandler(services.NewLogService(repository.NewLogRepository(db)))
+	chatHandler := handlers.NewChatHandler(services.NewChatService(summaryRepository, ai))
+
 	app.Get("/ping", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
 			"message": "pong",
@@ -75,6 +167,7 @@ func main() {
 
 	// Enhanced health check
 	app.Get("/health", func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
 		// Check database connection
 		sqlDB, err := db.DB()
 		if err != nil {
@@ -85,7 +178,7 @@ func main() {
 			})
 		}
 
-		if err := sqlDB.Ping(); err != nil {
+		if err := sqlDB.PingContext(c.UserContext()); err != nil {
 			return c.Status(503).JSON(fiber.Map{
 				"status":   "unhealthy",
 				"database": "unreachable",
@@ -98,495 +191,322 @@ func main() {
 		db.Model(&models.PDF{}).Count(&pdfCount)
 		db.Model(&models.Summaries{}).Count(&summaryCount)
 
+		// An AI endpoint paused by its circuit breaker degrades the service without taking it down
+		status := "healthy"
+		aiService := utils.AIServiceStatus()
+		for _, breaker := range aiService {
+			if breaker.State != utils.BreakerClosed {
+				status = "degraded"
+			}
+		}
+
 		return c.JSON(fiber.Map{
-			"status":          "healthy",
+			"status":          status,
 			"database":        "connected",
+			"ai_service":      aiService,
+			"embedding_cache": utils.GetEmbeddingCache().Stats(),
 			"total_pdfs":      pdfCount,
 			"total_summaries": summaryCount,
 			"version":         "1.0.0",
 		})
 	})
 
-	app.Get("/pdf", func(c *fiber.Ctx) error {
-		var pdfs []models.PDF
-
-		// Pagination parameters with validation
-		page := c.QueryInt("page", 1)
-		itemsPerPage := c.QueryInt("itemsperpage", 10)
-
-		if page < 1 {
-			page = 1
-		}
-		if itemsPerPage < 1 || itemsPerPage > 100 {
-			itemsPerPage = 10
-		}
-
-		// Calculate offset from page and itemsPerPage
-		offset := (page - 1) * itemsPerPage
-		limit := itemsPerPage
-
-		// Other query parameters
-		sortBy := c.Query("sort", "created_at")
-		order := c.Query("order", "desc")
-		search := c.Query("search", "")
-
-		// Validate sort parameters
-		validSortFields := map[string]bool{
-			"created_at": true,
-			"updated_at": true,
-			"title":      true,
-			"file_size":  true,
-			"page_count": true,
-		}
-		if !validSortFields[sortBy] {
-			sortBy = "created_at"
-		}
-		if order != "asc" && order != "desc" {
-			order = "desc"
-		}
-
-		query := db.Model(&models.PDF{})
-
-		if search != "" {
-			query = query.Where("title ILIKE ? OR filename ILIKE ?", "%"+search+"%", "%"+search+"%")
-		}
-
-		// Get total count for pagination
-		var totalCount int64
-		if err := query.Count(&totalCount).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"error":   "database_error",
-				"message": "Failed to count PDFs",
-				"details": err.Error(),
-			})
-		}
-
-		// Calculate total pages
-		totalPages := int((totalCount + int64(itemsPerPage) - 1) / int64(itemsPerPage))
-
-		if err := query.Preload("Summaries").Order(fmt.Sprintf("%s %s", sortBy, order)).Limit(limit).Offset(offset).Find(&pdfs).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"error":   "database_error",
-				"message": "Failed to fetch PDFs",
-				"details": err.Error(),
-			})
-		}
+	app.Get("/languages", func(c *fiber.Ctx) error {
+		languages := utils.GetLanguages()
 
-		response := dto.PDFListResponse{
-			Data:         utils.ConvertPDFsToResponse(pdfs),
-			Page:         page,
-			ItemsPerPage: itemsPerPage,
-			TotalPages:   totalPages,
-			TotalItems:   totalCount,
+		data := make([]dto.LanguageResponse, len(languages))
+		for i, lang := range languages {
+			data[i] = dto.LanguageResponse{
+				Code:       lang.Code,
+				Name:       lang.Name,
+				NativeName: lang.NativeName,
+			}
 		}
 
-		return c.Status(200).JSON(response)
+		return c.Status(200).JSON(dto.LanguageListResponse{
+			Data:    data,
+			Default: utils.GetDefaultLanguage().Code,
+		})
 	})
 
-	app.Get("/pdf/count", func(c *fiber.Ctx) error {
-		var count int64
-
-		if err := db.Model(&models.PDF{}).Count(&count).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"message": "Failed to count PDFs: " + err.Error(),
+	app.Get("/pdf", pdfHandler.List)
+	app.Get("/pdf/count", pdfHandler.Count)
+	app.Post("/pdf", pdfHandler.Create)
+	app.Get("/pdf/:id", pdfHandler.Get)
+	app.Get("/pdf/:id/similar", pdfHandler.Similar)
+	app.Get("/pdf/:id/summaries", pdfHandler.Summaries)
+	app.Get("/pdf/:id/download", pdfHandler.Download)
+	app.Get("/pdf/:id/export", pdfHandler.Export)
+
+	// Anki deck of question/answer pairs derived from the PDF's summaries.
+	// Pairs are generated once per summary and stored as flashcards, so later
+	// exports (and edits made through /flashcards) are reused.
+	app.Get("/pdf/:id/anki", func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		format := strings.ToLower(c.Query("format", "apkg"))
+		ankiFormat, ok := utils.AnkiFormats[format]
+		if !ok {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_format",
+				"message": "Format must be one of apkg, csv or tsv",
 			})
 		}
 
-		response := dto.PDFCountResponse{
-			Count: count,
-		}
-
-		return c.Status(200).JSON(response)
-	})
-
-	app.Post("/pdf", func(c *fiber.Ctx) error {
-		var req dto.PDFCreateRequest
-
-		if err := c.BodyParser(&req); err != nil {
+		summaryIDs, err := utils.ParseIDList(c.Query("summaries"))
+		if err != nil {
 			return c.Status(400).JSON(fiber.Map{
-				"message": "Invalid request body: " + err.Error(),
+				"error":   "invalid_request",
+				"message": err.Error(),
 			})
 		}
 
-		// Convert DTO to model
-		pdf := models.PDF{
-			Filename:  req.Filename,
-			FileSize:  req.FileSize,
-			Title:     req.Title,
-			PageCount: req.PageCount,
-		}
-
-		if err := db.Create(&pdf).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"message": "Failed to create PDF record: " + err.Error(),
+		id, err := utils.ParseID(c.Params("id"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": err.Error(),
 			})
 		}
 
-		// Convert model to response DTO
-		response := utils.ConvertPDFToResponse(pdf)
-		return c.Status(201).JSON(response)
-	})
-
-	app.Get("/pdf/:id", func(c *fiber.Ctx) error {
 		var pdf models.PDF
-
-		if err := db.Preload("Summaries").First(&pdf, c.Params("id")).Error; err != nil {
+		if err := db.First(&pdf, id).Error; err != nil {
 			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
 				"message": "PDF not found",
 			})
 		}
 
-		response := utils.ConvertPDFToResponse(pdf)
-		return c.Status(200).JSON(response)
-	})
-
-	app.Get("/pdf/:id/summaries", func(c *fiber.Ctx) error {
-		id := c.Params("id")
-		var pdf models.PDF
-
-		// Check if PDF exists
-		if err := db.First(&pdf, id).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				return c.Status(404).JSON(fiber.Map{
-					"error":   "not_found",
-					"message": "PDF not found",
-				})
-			}
-			return c.Status(500).JSON(fiber.Map{
-				"error":   "database_error",
-				"message": "Failed to find PDF",
-				"details": err.Error(),
-			})
-		}
-
-		var summaries []models.Summaries
-
-		// Pagination parameters
-		page := c.QueryInt("page", 1)
-		itemsPerPage := c.QueryInt("itemsperpage", 10)
-
-		// Validate pagination parameters
-		if page < 1 {
-			page = 1
-		}
-		if itemsPerPage < 1 || itemsPerPage > 100 {
-			itemsPerPage = 10
-		}
-
-		// Calculate offset from page and itemsPerPage
-		offset := (page - 1) * itemsPerPage
-		limit := itemsPerPage
-
-		// Other query parameters
-		sortBy := c.Query("sort", "created_at")
-		order := c.Query("order", "desc")
-
-		// Validate sort parameters
-		validSortFields := map[string]bool{
-			"created_at":   true,
-			"updated_at":   true,
-			"style":        true,
-			"language":     true,
-			"summary_time": true,
+		// Optional filters, as for /pdf/:id/export
+		query := db.Omit("embedding").Where("pdf_id = ?", pdf.ID)
+		if len(summaryIDs) > 0 {
+			query = query.Where("id IN ?", summaryIDs)
 		}
-		if !validSortFields[sortBy] {
-			sortBy = "created_at"
+		if style := c.Query("style", ""); style != "" {
+			query = query.Where("style = ?", style)
 		}
-		if order != "asc" && order != "desc" {
-			order = "desc"
-		}
-
-		query := db.Model(&models.Summaries{}).Where("pdf_id = ?", pdf.ID)
-
-		// Get total count for pagination
-		var totalCount int64
-		if err := query.Count(&totalCount).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"error":   "database_error",
-				"message": "Failed to count summaries",
-				"details": err.Error(),
-			})
+		if language := c.Query("language", ""); language != "" {
+			query = query.Where("language = ?", language)
 		}
 
-		// Calculate total pages
-		totalPages := int((totalCount + int64(itemsPerPage) - 1) / int64(itemsPerPage))
-
-		if err := query.Order(fmt.Sprintf("%s %s", sortBy, order)).Limit(limit).Offset(offset).Find(&summaries).Error; err != nil {
+		var summaries []models.Summaries
+		if err := query.Order("created_at DESC").Find(&summaries).Error; err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "database_error",
 				"message": "Failed to fetch summaries",
 				"details": err.Error(),
 			})
 		}
-
-		response := dto.SummaryListResponse{
-			Data:         utils.ConvertSummariesToResponse(summaries),
-			Page:         page,
-			ItemsPerPage: itemsPerPage,
-			TotalPages:   totalPages,
-			TotalItems:   totalCount,
-		}
-
-		return c.Status(200).JSON(response)
-	})
-
-	app.Get("/pdf/:id/download", func(c *fiber.Ctx) error {
-		var pdf models.PDF
-
-		if err := db.First(&pdf, c.Params("id")).Error; err != nil {
+		if len(summaries) == 0 {
 			return c.Status(404).JSON(fiber.Map{
 				"error":   "not_found",
-				"message": "PDF not found",
+				"message": "No summaries found for this PDF",
 			})
 		}
 
-		// Check if MinIO is available
-		if utils.IsMinIOAvailable() {
-			// Download from MinIO
-			object, err := utils.DownloadFromMinIO(pdf.Filename)
-			if err != nil {
-				return c.Status(404).JSON(fiber.Map{
-					"error":   "file_not_found",
-					"message": "PDF file not found in storage",
+		// Exports only read cards; they are generated with POST /pdf/:id/flashcards
+		var cards []utils.AnkiCard
+		for _, summary := range summaries {
+			var flashcards []models.Flashcard
+			if err := db.Where("summary_id = ?", summary.ID).Order("id ASC").Find(&flashcards).Error; err != nil {
+				return c.Status(500).JSON(fiber.Map{
+					"error":   "database_error",
+					"message": "Failed to fetch flashcards",
 					"details": err.Error(),
 				})
 			}
-			defer object.Close()
-
-			// Set appropriate headers for file download
-			c.Set("Content-Type", "application/pdf")
-			c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", pdf.Title+".pdf"))
 
-			// Stream the file
-			return c.SendStream(object)
-		} else {
-			// Fallback to local storage
-			filePath := filepath.Join("uploads", pdf.Filename)
-
-			// Check if file exists
-			if _, err := os.Stat(filePath); os.IsNotExist(err) {
-				return c.Status(404).JSON(fiber.Map{
-					"error":   "file_not_found",
-					"message": "PDF file not found on server",
-				})
+			tags := []string{utils.AnkiTag(pdf.Title), utils.AnkiTag(summary.Style), utils.AnkiTag(summary.Language)}
+			for _, card := range flashcards {
+				cards = append(cards, utils.AnkiCard{ID: card.ID, Front: card.Question, Back: card.Answer, Tags: tags})
 			}
-
-			// Set appropriate headers for file download
-			c.Set("Content-Type", "application/pdf")
-			c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", pdf.Title+".pdf"))
-
-			return c.SendFile(filePath)
 		}
-	})
-
-	app.Delete("/pdf/:id", func(c *fiber.Ctx) error {
-		id := c.Params("id")
 
-		var pdf models.PDF
-
-		db.First(&pdf, id)
-
-		if pdf.ID == 0 {
+		if len(cards) == 0 {
 			return c.Status(404).JSON(fiber.Map{
-				"message": "PDF not found",
+				"error":   "not_found",
+				"message": "No flashcards found for these summaries; generate them with POST /pdf/:id/flashcards first",
 			})
 		}
 
-		// Delete from storage
-		if utils.IsMinIOAvailable() {
-			// Delete from MinIO
-			if err := utils.DeleteFromMinIO(pdf.Filename); err != nil {
-				fmt.Printf("Warning: Failed to delete file from MinIO: %v\n", err)
-				// Continue with database deletion even if MinIO deletion fails
-			}
-		} else {
-			// Delete from local storage
-			filePath := filepath.Join("uploads", pdf.Filename)
-			if _, err := os.Stat(filePath); err == nil {
-				if err := os.Remove(filePath); err != nil {
-					fmt.Printf("Warning: Failed to delete local file: %v\n", err)
-				}
-			}
+		content, err := utils.RenderAnkiDeck(c.UserContext(), pdf.Title, cards, format)
+		if err != nil {
+			return utils.SendPythonAPIError(c, err)
 		}
 
-		db.Unscoped().Delete(&pdf)
-
-		return c.Status(200).JSON(fiber.Map{
-			"message": "PDF deleted successfully",
-		})
+		c.Set("Content-Type", ankiFormat.ContentType)
+		c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", utils.ExportFilename(pdf.Title, ankiFormat.Extension)))
+		return c.Status(200).Send(content)
 	})
 
-	app.Post("/pdf/upload", func(c *fiber.Ctx) error {
-		file, err := c.FormFile("file")
-		if err != nil {
+	// Generate flashcards from the document text or one of its summaries
+	app.Post("/pdf/:id/flashcards", func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		var req dto.FlashcardGenerateRequest
+
+		if err := c.BodyParser(&req); err != nil {
 			return c.Status(400).JSON(fiber.Map{
 				"error":   "invalid_request",
-				"message": "File is required",
+				"message": "Invalid request body",
+				"details": err.Error(),
 			})
 		}
 
-		// Validate file extension
-		if err := utils.ValidateFileExtension(file.Filename); err != nil {
+		source, count, err := utils.ValidateFlashcardRequest(req.Source, req.Count)
+		if err != nil {
 			return c.Status(400).JSON(fiber.Map{
-				"error":   "invalid_file",
+				"error":   "invalid_request",
 				"message": err.Error(),
 			})
 		}
 
-		// Validate file size
-		if err := utils.ValidateFileSize(file.Size); err != nil {
+		id, err := utils.ParseID(c.Params("id"))
+		if err != nil {
 			return c.Status(400).JSON(fiber.Map{
-				"error":   "invalid_file",
+				"error":   "invalid_request",
 				"message": err.Error(),
 			})
 		}
 
-		// Get title from form data, fallback to filename without extension
-		title := c.FormValue("title")
-		if title == "" {
-			ext := filepath.Ext(file.Filename)
-			title = strings.TrimSuffix(file.Filename, ext)
+		var pdf models.PDF
+		if err := db.First(&pdf, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return c.Status(404).JSON(fiber.Map{
+					"error":   "not_found",
+					"message": "PDF not found",
+				})
+			}
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to find PDF",
+				"details": err.Error(),
+			})
 		}
 
-		// Validate title
-		if err := utils.ValidateTitle(title); err != nil {
+		language, err := utils.ResolveLanguage(req.Language, pdf.DetectedLanguage)
+		if err != nil {
 			return c.Status(400).JSON(fiber.Map{
-				"error":   "invalid_title",
+				"error":   "invalid_language",
 				"message": err.Error(),
 			})
 		}
 
-		// Generate unique filename
-		ext := filepath.Ext(file.Filename)
-		filename := uuid.New().String() + ext
-
-		var pageCount int
+		var cards []models.Flashcard
 
-		// Check if MinIO is available
-		if utils.IsMinIOAvailable() {
-			// Use MinIO storage
-			fileReader, err := file.Open()
-			if err != nil {
-				return c.Status(500).JSON(fiber.Map{
-					"error":   "server_error",
-					"message": "Failed to open uploaded file",
-					"details": err.Error(),
-				})
+		if source == "summary" {
+			// Use the requested summary, else the pinned one, else the latest
+			query := db.Where("pdf_id = ?", pdf.ID)
+			switch {
+			case req.SummaryID != nil:
+				query = query.Where("id = ?", *req.SummaryID)
+			case pdf.PinnedSummaryID != nil:
+				query = query.Where("id = ?", *pdf.PinnedSummaryID)
+			default:
+				query = query.Order("created_at DESC")
 			}
-			defer fileReader.Close()
 
-			// Upload to MinIO
-			if err := utils.UploadToMinIO(filename, fileReader, file.Size, "application/pdf"); err != nil {
+			var summary models.Summaries
+			if err := query.Omit("embedding").First(&summary).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return c.Status(404).JSON(fiber.Map{
+						"error":   "not_found",
+						"message": "No summary found for this PDF",
+					})
+				}
 				return c.Status(500).JSON(fiber.Map{
-					"error":   "storage_error",
-					"message": "Failed to upload file to storage",
+					"error":   "database_error",
+					"message": "Failed to find summary",
 					"details": err.Error(),
 				})
 			}
 
-			// Download from MinIO to get page count (temporary)
-			object, err := utils.DownloadFromMinIO(filename)
-			if err != nil {
-				utils.DeleteFromMinIO(filename)
+			cards, err = utils.GenerateFlashcards(c.UserContext(), pdf.ID, &summary.ID, "", nil, summary.Content, language, count)
+		} else {
+			file, _, openErr := utils.OpenStoredFile(c.UserContext(), pdf.Filename)
+			if openErr != nil {
 				return c.Status(500).JSON(fiber.Map{
 					"error":   "storage_error",
-					"message": "Failed to retrieve file from storage",
-					"details": err.Error(),
-				})
-			}
-			defer object.Close()
-
-			// Save to temporary file for page count
-			tempFile, err := os.CreateTemp("", "pdf-*.pdf")
-			if err != nil {
-				utils.DeleteFromMinIO(filename)
-				return c.Status(500).JSON(fiber.Map{
-					"error":   "server_error",
-					"message": "Failed to create temporary file",
-					"details": err.Error(),
+					"message": "Failed to retrieve PDF from storage",
+					"details": openErr.Error(),
 				})
 			}
-			tempPath := tempFile.Name()
-			defer os.Remove(tempPath)
+			defer file.Close()
 
-			_, err = io.Copy(tempFile, object)
-			tempFile.Close()
-			if err != nil {
-				utils.DeleteFromMinIO(filename)
-				return c.Status(500).JSON(fiber.Map{
-					"error":   "server_error",
-					"message": "Failed to process file",
-					"details": err.Error(),
-				})
-			}
+			cards, err = utils.GenerateFlashcards(c.UserContext(), pdf.ID, nil, pdf.Filename, file, "", language, count)
+		}
 
-			// Get page count
-			pageCount = npdfpages.PagesAtPath(tempPath)
-			if pageCount <= 0 {
-				utils.DeleteFromMinIO(filename)
-				return c.Status(400).JSON(fiber.Map{
-					"error":   "invalid_file",
-					"message": "Invalid PDF file or unable to read page count",
-				})
-			}
-		} else {
-			// Fallback to local storage
-			fmt.Println("MinIO not available, using local storage")
+		if err == utils.ErrNoFlashcards {
+			return c.Status(502).JSON(fiber.Map{
+				"error":   "backend_error",
+				"message": "No flashcards were generated",
+			})
+		} else if err != nil {
+			return utils.SendPythonAPIError(c, err)
+		}
 
-			if err := os.MkdirAll("./uploads", os.ModePerm); err != nil {
-				return c.Status(500).JSON(fiber.Map{
-					"error":   "server_error",
-					"message": "Failed to create upload directory",
-					"details": err.Error(),
-				})
-			}
+		if err := db.Omit(clause.Associations).Create(&cards).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to save flashcards",
+				"details": err.Error(),
+			})
+		}
+		fmt.Printf("✓ Generated %d flashcards for PDF %d\n", len(cards), pdf.ID)
 
-			if err := c.SaveFile(file, "./uploads/"+filename); err != nil {
-				return c.Status(500).JSON(fiber.Map{
-					"error":   "server_error",
-					"message": "Failed to save file",
-					"details": err.Error(),
-				})
-			}
+		return c.Status(201).JSON(dto.FlashcardListResponse{
+			Data:  utils.ConvertFlashcardsToResponse(cards),
+			Total: len(cards),
+		})
+	})
 
-			// Get page count
-			pageCount = npdfpages.PagesAtPath(filepath.Join("uploads", filename))
-			if pageCount <= 0 {
-				os.Remove(filepath.Join("uploads", filename))
-				return c.Status(400).JSON(fiber.Map{
-					"error":   "invalid_file",
-					"message": "Invalid PDF file or unable to read page count",
-				})
-			}
+	app.Get("/pdf/:id/flashcards", func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		id, err := utils.ParseID(c.Params("id"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
 		}
 
-		pdf := models.PDF{
-			Filename:  filename,
-			FileSize:  file.Size,
-			Title:     title,
-			PageCount: pageCount,
+		var pdf models.PDF
+		if err := db.First(&pdf, id).Error; err != nil {
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "PDF not found",
+			})
 		}
 
-		if err := db.Create(&pdf).Error; err != nil {
-			// Clean up the uploaded file if database save fails
-			if utils.IsMinIOAvailable() {
-				utils.DeleteFromMinIO(filename)
-			} else {
-				os.Remove(filepath.Join("uploads", filename))
+		query := db.Where("pdf_id = ?", pdf.ID)
+		if language := c.Query("language"); language != "" {
+			if lang, ok := utils.LookupLanguage(language); ok {
+				language = lang.Code
 			}
+			query = query.Where("language = ?", language)
+		}
+
+		var cards []models.Flashcard
+		if err := query.Order("source_page ASC NULLS LAST, id ASC").Find(&cards).Error; err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "database_error",
-				"message": "Failed to create PDF record",
+				"message": "Failed to fetch flashcards",
 				"details": err.Error(),
 			})
 		}
 
-		response := utils.ConvertPDFToResponse(pdf)
-		return c.Status(201).JSON(response)
+		return c.Status(200).JSON(dto.FlashcardListResponse{
+			Data:  utils.ConvertFlashcardsToResponse(cards),
+			Total: len(cards),
+		})
 	})
 
-	app.Post("/pdf/:id/summarize", func(c *fiber.Ctx) error {
-		var req dto.SummarizeRequest
+	// Study and quiz data is scoped to the caller's identity
+	identityMiddleware := utils.IdentityMiddleware(utils.HeaderIdentityResolver{})
+
+	// Generate a quiz grounded in the document text
+	app.Post("/pdf/:id/quiz", identityMiddleware, func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		identity := utils.GetIdentity(c)
+		var req dto.QuizGenerateRequest
 
 		if err := c.BodyParser(&req); err != nil {
 			return c.Status(400).JSON(fiber.Map{
@@ -596,24 +516,23 @@ func main() {
 			})
 		}
 
-		// Validate style and language
-		if err := utils.ValidateSummaryStyle(req.Style); err != nil {
+		count, questionTypes, err := utils.ValidateQuizRequest(req.Count, req.QuestionTypes)
+		if err != nil {
 			return c.Status(400).JSON(fiber.Map{
-				"error":   "invalid_style",
+				"error":   "invalid_request",
 				"message": err.Error(),
 			})
 		}
 
-		if err := utils.ValidateLanguage(req.Language); err != nil {
+		id, err := utils.ParseID(c.Params("id"))
+		if err != nil {
 			return c.Status(400).JSON(fiber.Map{
-				"error":   "invalid_language",
+				"error":   "invalid_request",
 				"message": err.Error(),
 			})
 		}
 
-		id := c.Params("id")
 		var pdf models.PDF
-
 		if err := db.First(&pdf, id).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
 				return c.Status(404).JSON(fiber.Map{
@@ -628,283 +547,673 @@ func main() {
 			})
 		}
 
-		body := &bytes.Buffer{}
-		writer := multipart.NewWriter(body)
+		language, err := utils.ResolveLanguage(req.Language, pdf.DetectedLanguage)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_language",
+				"message": err.Error(),
+			})
+		}
 
-		var fileReader io.Reader
+		file, _, err := utils.OpenStoredFile(c.UserContext(), pdf.Filename)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "storage_error",
+				"message": "Failed to retrieve PDF from storage",
+				"details": err.Error(),
+			})
+		}
+		defer file.Close()
 
-		// Check if MinIO is available
-		if utils.IsMinIOAvailable() {
-			// Download from MinIO
-			object, err := utils.DownloadFromMinIO(pdf.Filename)
-			if err != nil {
-				return c.Status(500).JSON(fiber.Map{
-					"error":   "storage_error",
-					"message": "Failed to retrieve PDF from storage",
-					"details": err.Error(),
-				})
-			}
-			defer object.Close()
-			fileReader = object
-		} else {
-			// Read from local storage
-			filePath := filepath.Join("uploads", pdf.Filename)
-			file, err := os.Open(filePath)
-			if err != nil {
-				return c.Status(500).JSON(fiber.Map{
-					"error":   "file_error",
-					"message": "Failed to open PDF file",
-					"details": err.Error(),
-				})
-			}
-			defer file.Close()
-			fileReader = file
+		var result dto.PythonQuizResponse
+		if err := utils.PostFileToPythonAPI(c.UserContext(), "/quiz", pdf.Filename, file, map[string]string{
+			"count":                strconv.Itoa(count),
+			"question_types":       strings.Join(questionTypes, ","),
+			"language":             language.Code,
+			"language_name":        language.Name,
+			"language_instruction": language.Instruction,
+		}, &result); err != nil {
+			return utils.SendPythonAPIError(c, err)
+		}
+
+		if len(result.Questions) == 0 {
+			return c.Status(502).JSON(fiber.Map{
+				"error":   "backend_error",
+				"message": "No quiz questions were generated",
+			})
+		}
+
+		quiz := models.Quiz{
+			PDFID:         pdf.ID,
+			WorkspaceID:   identity.WorkspaceID,
+			Title:         pdf.Title + " quiz",
+			Language:      language.Code,
+			ModelName:     result.Model,
+			PromptVersion: result.PromptVersion,
+		}
+		for i, q := range result.Questions {
+			quiz.Questions = append(quiz.Questions, models.QuizQuestion{
+				Position:      i + 1,
+				Type:          q.Type,
+				Question:      q.Question,
+				Options:       q.Options,
+				CorrectOption: q.CorrectOption,
+				Answer:        q.Answer,
+				Explanation:   q.Explanation,
+				SourcePage:    q.Page,
+			})
+		}
+
+		if err := db.Create(&quiz).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to save quiz",
+				"details": err.Error(),
+			})
 		}
+		fmt.Printf("✓ Generated quiz %d with %d questions for PDF %d\n", quiz.ID, len(quiz.Questions), pdf.ID)
 
-		filePart, err := writer.CreateFormFile("file", pdf.Filename)
+		return c.Status(201).JSON(utils.ConvertQuizToResponse(quiz, false))
+	})
+
+	app.Get("/pdf/:id/quizzes", identityMiddleware, func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		identity := utils.GetIdentity(c)
+		id, err := utils.ParseID(c.Params("id"))
 		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
+		}
+
+		var pdf models.PDF
+		if err := db.First(&pdf, id).Error; err != nil {
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "PDF not found",
+			})
+		}
+
+		var quizzes []models.Quiz
+		if err := db.Preload("Questions").Where("pdf_id = ? AND workspace_id = ?", pdf.ID, identity.WorkspaceID).
+			Order("created_at DESC").Find(&quizzes).Error; err != nil {
 			return c.Status(500).JSON(fiber.Map{
-				"error":   "server_error",
-				"message": "Failed to create form file",
+				"error":   "database_error",
+				"message": "Failed to fetch quizzes",
 				"details": err.Error(),
 			})
 		}
-		io.Copy(filePart, fileReader)
 
-		writer.WriteField("style", req.Style)
-		writer.WriteField("language", req.Language)
-		writer.Close()
+		data := make([]dto.QuizResponse, len(quizzes))
+		for i, quiz := range quizzes {
+			data[i] = utils.ConvertQuizToResponse(quiz, false)
+			data[i].Questions = nil
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"data": data,
+		})
+	})
+
+	// Extract key terms with definitions; re-running updates existing terms
+	app.Post("/pdf/:id/glossary", func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		var req dto.GlossaryExtractRequest
 
-		pythonAPIURL := os.Getenv("PYTHON_API_URL")
-		if pythonAPIURL == "" {
-			pythonAPIURL = "http://127.0.0.1:8000"
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Invalid request body",
+				"details": err.Error(),
+			})
 		}
 
-		httpReq, _ := http.NewRequest(
-			"POST",
-			fmt.Sprintf("%s/summarize", pythonAPIURL),
-			body,
-		)
-		httpReq.Header.Set("Content-Type", writer.FormDataContentType())
+		maxTerms, err := utils.ValidateGlossaryMaxTerms(req.MaxTerms)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
+		}
 
-		client := &http.Client{}
-		resp, err := client.Do(httpReq)
+		id, err := utils.ParseID(c.Params("id"))
 		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
+		}
+
+		var pdf models.PDF
+		if err := db.First(&pdf, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return c.Status(404).JSON(fiber.Map{
+					"error":   "not_found",
+					"message": "PDF not found",
+				})
+			}
 			return c.Status(500).JSON(fiber.Map{
-				"error":   "backend_error",
-				"message": "Failed to connect to Python backend",
+				"error":   "database_error",
+				"message": "Failed to find PDF",
 				"details": err.Error(),
 			})
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode != 200 {
-			bodyBytes, _ := io.ReadAll(resp.Body)
-			return c.Status(resp.StatusCode).JSON(fiber.Map{
-				"error":   "backend_error",
-				"message": "Python backend error",
-				"details": string(bodyBytes),
+		language, err := utils.ResolveLanguage(req.Language, pdf.DetectedLanguage)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_language",
+				"message": err.Error(),
 			})
 		}
 
-		// Parse response into PythonSummaryResponse struct
-		var pythonResponse dto.PythonSummaryResponse
-		if err := json.NewDecoder(resp.Body).Decode(&pythonResponse); err != nil {
+		file, _, err := utils.OpenStoredFile(c.UserContext(), pdf.Filename)
+		if err != nil {
 			return c.Status(500).JSON(fiber.Map{
-				"error":   "parse_error",
-				"message": "Failed to parse response",
+				"error":   "storage_error",
+				"message": "Failed to retrieve PDF from storage",
 				"details": err.Error(),
 			})
 		}
+		defer file.Close()
 
-		// Save summary to database
-		summary := models.Summaries{
-			Style:       pythonResponse.Style,
-			Content:     pythonResponse.Summary.MainSummary,
-			PDFID:       pdf.ID,
-			Language:    pythonResponse.Language,
-			SummaryTime: pythonResponse.ProcessInfo.ProcessingTimeSeconds,
+		var result dto.PythonGlossaryResponse
+		if err := utils.PostFileToPythonAPI(c.UserContext(), "/glossary", pdf.Filename, file, map[string]string{
+			"max_terms":            strconv.Itoa(maxTerms),
+			"language":             language.Code,
+			"language_name":        language.Name,
+			"language_instruction": language.Instruction,
+		}, &result); err != nil {
+			return utils.SendPythonAPIError(c, err)
 		}
 
-		// Only set embedding if it's not empty
-		if len(pythonResponse.Embedding) > 0 {
-			summary.Embedding = pgvector.NewVector(pythonResponse.Embedding)
-			fmt.Printf("✓ Embedding saved with %d dimensions\n", len(pythonResponse.Embedding))
-		} else {
-			fmt.Println("Warning: No embedding generated for summary")
+		var terms []models.GlossaryTerm
+		seen := make(map[string]bool)
+		for _, t := range result.Terms {
+			normalized := utils.NormalizeTerm(t.Term)
+			if normalized == "" || seen[normalized] {
+				continue
+			}
+			seen[normalized] = true
+			terms = append(terms, models.GlossaryTerm{
+				PDFID:          pdf.ID,
+				Term:           strings.TrimSpace(t.Term),
+				NormalizedTerm: normalized,
+				Definition:     strings.TrimSpace(t.Definition),
+				Page:           t.Page,
+				Language:       language.Code,
+				ModelName:      result.Model,
+				PromptVersion:  result.PromptVersion,
+			})
 		}
 
-		if err := db.Create(&summary).Error; err != nil {
-			fmt.Printf("Failed to save summary: %v\n", err)
-			// Don't return error here as the summary was generated successfully
-		} else {
-			fmt.Printf("✓ Summary saved successfully (ID: %d)\n", summary.ID)
+		if len(terms) == 0 {
+			return c.Status(502).JSON(fiber.Map{
+				"error":   "backend_error",
+				"message": "No glossary terms were extracted",
+			})
+		}
+
+		if err := db.Omit(clause.Associations).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "pdf_id"}, {Name: "normalized_term"}},
+			DoUpdates: clause.AssignmentColumns([]string{"term", "definition", "page", "language", "model_name", "prompt_version", "updated_at", "deleted_at"}),
+		}).Create(&terms).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to save glossary terms",
+				"details": err.Error(),
+			})
 		}
+		fmt.Printf("✓ Extracted %d glossary terms for PDF %d\n", len(terms), pdf.ID)
+
+		var saved []models.GlossaryTerm
+		db.Where("pdf_id = ?", pdf.ID).Order("normalized_term ASC").Find(&saved)
 
-		return c.Status(200).JSON(pythonResponse)
+		return c.Status(201).JSON(fiber.Map{
+			"data":      utils.ConvertGlossaryTermsToResponse(saved),
+			"extracted": len(terms),
+		})
 	})
 
-	app.Get("/summaries", func(c *fiber.Ctx) error {
-		var summaries []models.Summaries
+	app.Get("/pdf/:id/glossary", func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		id, err := utils.ParseID(c.Params("id"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
+		}
 
-		// Pagination parameters
-		page := c.QueryInt("page", 1)
-		itemsPerPage := c.QueryInt("itemsperpage", 10)
+		var pdf models.PDF
+		if err := db.First(&pdf, id).Error; err != nil {
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "PDF not found",
+			})
+		}
 
-		// Validate pagination parameters
-		if page < 1 {
-			page = 1
+		var terms []models.GlossaryTerm
+		if err := db.Where("pdf_id = ?", pdf.ID).Order("normalized_term ASC").Find(&terms).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to fetch glossary",
+				"details": err.Error(),
+			})
 		}
-		if itemsPerPage < 1 || itemsPerPage > 100 {
-			itemsPerPage = 10
+
+		return c.Status(200).JSON(fiber.Map{
+			"data": utils.ConvertGlossaryTermsToResponse(terms),
+		})
+	})
+
+	// Hierarchical outline; generated on first request and cached, refresh=true regenerates it
+	app.Get("/pdf/:id/outline", func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		id, err := utils.ParseID(c.Params("id"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
 		}
 
-		// Calculate offset from page and itemsPerPage
-		offset := (page - 1) * itemsPerPage
-		limit := itemsPerPage
+		var pdf models.PDF
+		if err := db.First(&pdf, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return c.Status(404).JSON(fiber.Map{
+					"error":   "not_found",
+					"message": "PDF not found",
+				})
+			}
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to find PDF",
+				"details": err.Error(),
+			})
+		}
 
-		// Other query parameters
-		sortBy := c.Query("sort", "created_at")
-		order := c.Query("order", "desc")
-		search := c.Query("search", "")
-		pdfId := c.QueryInt("pdf", 0)
-		style := c.Query("style", "")
-		language := c.Query("language", "")
+		refresh := c.QueryBool("refresh", false)
 
-		// Validate sort parameters
-		validSortFields := map[string]bool{
-			"created_at":   true,
-			"updated_at":   true,
-			"style":        true,
-			"language":     true,
-			"summary_time": true,
+		var outline models.DocumentOutline
+		err = db.Where("pdf_id = ?", pdf.ID).First(&outline).Error
+		if err == nil && !refresh {
+			return c.Status(200).JSON(fiber.Map{
+				"data": utils.ConvertOutlineToResponse(outline),
+			})
 		}
-		if !validSortFields[sortBy] {
-			sortBy = "created_at"
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to fetch outline",
+				"details": err.Error(),
+			})
 		}
-		if order != "asc" && order != "desc" {
-			order = "desc"
+
+		language, err := utils.ResolveLanguage(c.Query("language"), pdf.DetectedLanguage)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_language",
+				"message": err.Error(),
+			})
+		}
+
+		file, _, err := utils.OpenStoredFile(c.UserContext(), pdf.Filename)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "storage_error",
+				"message": "Failed to retrieve PDF from storage",
+				"details": err.Error(),
+			})
 		}
+		defer file.Close()
 
-		query := db.Model(&models.Summaries{})
+		var result dto.PythonOutlineResponse
+		if err := utils.PostFileToPythonAPI(c.UserContext(), "/outline", pdf.Filename, file, map[string]string{
+			"language":             language.Code,
+			"language_name":        language.Name,
+			"language_instruction": language.Instruction,
+		}, &result); err != nil {
+			return utils.SendPythonAPIError(c, err)
+		}
 
-		// Apply filters
-		if search != "" {
-			query = query.Where("content ILIKE ? OR style ILIKE ?", "%"+search+"%", "%"+search+"%")
+		if len(result.Sections) == 0 {
+			return c.Status(502).JSON(fiber.Map{
+				"error":   "backend_error",
+				"message": "No outline sections were found",
+			})
 		}
 
-		if pdfId != 0 {
-			query = query.Where("pdf_id = ?", pdfId)
+		outline = models.DocumentOutline{
+			PDFID:         pdf.ID,
+			Source:        result.Source,
+			Sections:      utils.ConvertPythonOutlineSections(result.Sections),
+			Language:      language.Code,
+			ModelName:     result.Model,
+			PromptVersion: result.PromptVersion,
 		}
+		if err := db.Omit(clause.Associations).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "pdf_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"source", "sections", "language", "model_name", "prompt_version", "updated_at", "deleted_at"}),
+		}).Create(&outline).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to save outline",
+				"details": err.Error(),
+			})
+		}
+		fmt.Printf("✓ Generated %s outline for PDF %d\n", result.Source, pdf.ID)
+
+		db.Where("pdf_id = ?", pdf.ID).First(&outline)
+
+		return c.Status(200).JSON(fiber.Map{
+			"data": utils.ConvertOutlineToResponse(outline),
+		})
+	})
 
-		if style != "" {
-			query = query.Where("style ILIKE ?", "%"+style+"%")
+	app.Delete("/pdf/:id", pdfHandler.Delete)
+	app.Post("/pdf/upload", pdfHandler.Upload)
+	app.Post("/pdf/:id/summarize", pdfHandler.Summarize)
+
+	app.Get("/summaries", summaryHandler.List)
+	app.Get("/summaries/count", summaryHandler.Count)
+	// Registered before /summaries/:id so "stats" and "compare" are not taken as IDs
+	app.Get("/summaries/stats", summaryHandler.Stats)
+	app.Get("/summaries/compare", summaryHandler.Compare)
+	app.Post("/summaries/synthesize", summaryHandler.Synthesize)
+	app.Get("/summaries/:id", summaryHandler.Get)
+	app.Post("/summaries/:id/translate", summaryHandler.Translate)
+	app.Patch("/summaries/:id", summaryHandler.Update)
+	app.Post("/summaries/:id/pin", summaryHandler.Pin)
+	app.Delete("/summaries/:id/pin", summaryHandler.Unpin)
+	app.Post("/summaries/:id/feedback", summaryHandler.AddFeedback)
+	app.Get("/summaries/:id/feedback", summaryHandler.Feedback)
+	app.Get("/summaries/:id/export", summaryHandler.Export)
+
+	// Registered before /summaries/:id so "bulk" is not taken as an ID
+	app.Delete("/summaries/bulk", summaryHandler.DeleteMany)
+	app.Delete("/summaries/:id", summaryHandler.Delete)
+
+	// Topics found by the clustering job, largest first, with member PDFs closest to the centroid first
+	app.Get("/topics", func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		var topicList []models.Topic
+		if err := db.Preload("Members", func(db *gorm.DB) *gorm.DB {
+			return db.Order("score DESC")
+		}).Preload("Members.PDF").Order("size DESC, id ASC").Find(&topicList).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to fetch topics",
+				"details": err.Error(),
+			})
 		}
 
-		if language != "" {
-			query = query.Where("language ILIKE ?", "%"+language+"%")
+		return c.Status(200).JSON(fiber.Map{
+			"data": utils.ConvertTopicsToResponse(topicList),
+		})
+	})
+
+	// Search glossary terms across all documents
+	app.Get("/glossary", func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		page, itemsPerPage := utils.ValidatePaginationParams(c.QueryInt("page", 1), c.QueryInt("itemsperpage", 20))
+		search := utils.NormalizeTerm(c.Query("q", ""))
+
+		query := db.Model(&models.GlossaryTerm{})
+		if search != "" {
+			pattern := "%" + utils.EscapeLike(search) + "%"
+			query = query.Where("normalized_term LIKE ? OR definition ILIKE ?", pattern, pattern)
+		}
+		if pdfID := c.QueryInt("pdf_id"); pdfID > 0 {
+			query = query.Where("pdf_id = ?", pdfID)
 		}
 
-		// Get total count for pagination
 		var totalCount int64
 		if err := query.Count(&totalCount).Error; err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "database_error",
-				"message": "Failed to count summaries",
+				"message": "Failed to count glossary terms",
 				"details": err.Error(),
 			})
 		}
 
-		// Calculate total pages
-		totalPages := int((totalCount + int64(itemsPerPage) - 1) / int64(itemsPerPage))
+		// Exact term matches first, then terms starting with the query, then the rest
+		if search != "" {
+			query = query.Order(clause.OrderBy{Expression: clause.Expr{
+				SQL:                "normalized_term = ? DESC, normalized_term LIKE ? DESC",
+				Vars:               []interface{}{search, utils.EscapeLike(search) + "%"},
+				WithoutParentheses: true,
+			}})
+		}
 
-		// Preload PDF data as recommended in compatibility fixes
-		if err := query.Preload("PDF").Order(fmt.Sprintf("%s %s", sortBy, order)).Limit(limit).Offset(offset).Find(&summaries).Error; err != nil {
+		var terms []models.GlossaryTerm
+		if err := query.Preload("PDF").Order("normalized_term ASC, pdf_id ASC").
+			Limit(itemsPerPage).Offset((page - 1) * itemsPerPage).Find(&terms).Error; err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "database_error",
-				"message": "Failed to fetch summaries",
+				"message": "Failed to search glossary",
 				"details": err.Error(),
 			})
 		}
 
-		response := dto.SummaryListResponse{
-			Data:         utils.ConvertSummariesToResponse(summaries),
+		return c.Status(200).JSON(dto.GlossaryListResponse{
+			Data:         utils.ConvertGlossaryTermsToResponse(terms),
 			Page:         page,
 			ItemsPerPage: itemsPerPage,
-			TotalPages:   totalPages,
+			TotalPages:   int((totalCount + int64(itemsPerPage) - 1) / int64(itemsPerPage)),
 			TotalItems:   totalCount,
-		}
-
-		return c.Status(200).JSON(response)
+		})
 	})
 
-	app.Get("/summaries/count", func(c *fiber.Ctx) error {
-		var count int64
+	// Flashcard endpoints
+	app.Patch("/flashcards/:id", func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		var req dto.FlashcardUpdateRequest
 
-		if err := db.Model(&models.Summaries{}).Count(&count).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"message": "Failed to count summaries: " + err.Error(),
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Invalid request body",
+				"details": err.Error(),
 			})
 		}
 
-		response := dto.SummaryCountResponse{
-			Count: count,
+		id, err := utils.ParseID(c.Params("id"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
 		}
 
-		return c.Status(200).JSON(response)
-	})
+		var card models.Flashcard
+		if err := db.First(&card, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return c.Status(404).JSON(fiber.Map{
+					"error":   "not_found",
+					"message": "Flashcard not found",
+				})
+			}
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to find flashcard",
+				"details": err.Error(),
+			})
+		}
 
-	app.Get("/summaries/:id", func(c *fiber.Ctx) error {
-		var summary models.Summaries
+		if req.Question != nil {
+			if err := utils.ValidateFlashcardText("question", *req.Question); err != nil {
+				return c.Status(400).JSON(fiber.Map{
+					"error":   "invalid_request",
+					"message": err.Error(),
+				})
+			}
+			card.Question = strings.TrimSpace(*req.Question)
+		}
+		if req.Answer != nil {
+			if err := utils.ValidateFlashcardText("answer", *req.Answer); err != nil {
+				return c.Status(400).JSON(fiber.Map{
+					"error":   "invalid_request",
+					"message": err.Error(),
+				})
+			}
+			card.Answer = strings.TrimSpace(*req.Answer)
+		}
+		if req.SourcePage != nil {
+			card.SourcePage = req.SourcePage
+		}
 
-		if err := db.Preload("PDF").First(&summary, c.Params("id")).Error; err != nil {
-			return c.Status(404).JSON(fiber.Map{
-				"message": "Summary not found",
+		if err := db.Omit(clause.Associations).Save(&card).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to update flashcard",
+				"details": err.Error(),
 			})
 		}
 
-		response := utils.ConvertSummaryToResponse(summary)
-		return c.Status(200).JSON(response)
+		return c.Status(200).JSON(utils.ConvertFlashcardToResponse(card))
 	})
 
-	app.Delete("/summaries/:id", func(c *fiber.Ctx) error {
-		id := c.Params("id")
-
-		var summary models.Summaries
+	app.Delete("/flashcards/:id", func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		id, err := utils.ParseID(c.Params("id"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
+		}
 
-		if err := db.First(&summary, id).Error; err != nil {
+		var card models.Flashcard
+		if err := db.First(&card, id).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
 				return c.Status(404).JSON(fiber.Map{
 					"error":   "not_found",
-					"message": "Summary not found",
+					"message": "Flashcard not found",
 				})
 			}
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "database_error",
-				"message": "Failed to find summary",
+				"message": "Failed to find flashcard",
 				"details": err.Error(),
 			})
 		}
 
-		if err := db.Unscoped().Delete(&summary).Error; err != nil {
+		if err := db.Unscoped().Delete(&card).Error; err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "database_error",
-				"message": "Failed to delete summary",
+				"message": "Failed to delete flashcard",
 				"details": err.Error(),
 			})
 		}
 
 		return c.Status(200).JSON(fiber.Map{
-			"message": "Summary deleted successfully",
+			"message": "Flashcard deleted successfully",
 		})
 	})
 
-	// Bulk delete summaries
-	app.Delete("/summaries/bulk", func(c *fiber.Ctx) error {
-		var req struct {
-			IDs []uint `json:"ids" binding:"required"`
+	study := app.Group("/study", identityMiddleware)
+
+	// Items due for review across the selected PDFs (all PDFs when pdf_ids is omitted).
+	// Key points are extracted from each PDF's primary summary the first time it is studied.
+	study.Get("/due", func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		identity := utils.GetIdentity(c)
+
+		pdfIDs, err := utils.ParseIDList(c.Query("pdf_ids"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
+		}
+
+		limit := c.QueryInt("limit", 20)
+		if limit < 1 || limit > 100 {
+			limit = 20
+		}
+		includeNew := c.QueryBool("include_new", true)
+
+		summaryIDs, err := utils.PrimarySummaryIDs(db, pdfIDs)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to find summaries",
+				"details": err.Error(),
+			})
+		}
+
+		response := dto.StudyDueResponse{Data: []dto.StudyItemResponse{}}
+		if len(summaryIDs) == 0 {
+			return c.Status(200).JSON(response)
+		}
+
+		if err := utils.SyncStudyItems(db, identity.WorkspaceID, summaryIDs); err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to prepare study items",
+				"details": err.Error(),
+			})
+		}
+
+		now := time.Now()
+		dueQuery := func() *gorm.DB {
+			return utils.StudyItemsQuery(db, identity, summaryIDs).Where("ss.id IS NOT NULL AND ss.due_at <= ?", now)
+		}
+		newQuery := func() *gorm.DB {
+			return utils.StudyItemsQuery(db, identity, summaryIDs).Where("ss.id IS NULL")
+		}
+
+		if err := dueQuery().Count(&response.DueCount).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to count due items",
+				"details": err.Error(),
+			})
+		}
+		if err := newQuery().Count(&response.NewCount).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to count new items",
+				"details": err.Error(),
+			})
+		}
+
+		// Overdue reviews come first, then new items fill the remaining slots
+		var rows []utils.StudyItemRow
+		if err := dueQuery().Order("ss.due_at ASC").Limit(limit).Scan(&rows).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to fetch due items",
+				"details": err.Error(),
+			})
 		}
 
+		if includeNew && len(rows) < limit {
+			var newRows []utils.StudyItemRow
+			if err := newQuery().Order("si.pdf_id ASC, si.position ASC").Limit(limit - len(rows)).Scan(&newRows).Error; err != nil {
+				return c.Status(500).JSON(fiber.Map{
+					"error":   "database_error",
+					"message": "Failed to fetch new items",
+					"details": err.Error(),
+				})
+			}
+			rows = append(rows, newRows...)
+		}
+
+		for _, row := range rows {
+			response.Data = append(response.Data, utils.ConvertStudyItemRowToResponse(row))
+		}
+
+		return c.Status(200).JSON(response)
+	})
+
+	study.Post("/items/:id/review", func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		identity := utils.GetIdentity(c)
+		var req dto.StudyReviewRequest
+
 		if err := c.BodyParser(&req); err != nil {
 			return c.Status(400).JSON(fiber.Map{
 				"error":   "invalid_request",
@@ -913,384 +1222,551 @@ func main() {
 			})
 		}
 
-		if len(req.IDs) == 0 {
+		if err := utils.ValidateReviewGrade(req.Grade); err != nil {
 			return c.Status(400).JSON(fiber.Map{
-				"error":   "invalid_request",
-				"message": "No IDs provided",
+				"error":   "invalid_grade",
+				"message": err.Error(),
 			})
 		}
 
-		if len(req.IDs) > 100 {
+		id, err := utils.ParseID(c.Params("id"))
+		if err != nil {
 			return c.Status(400).JSON(fiber.Map{
 				"error":   "invalid_request",
-				"message": "Too many IDs provided (max 100)",
+				"message": err.Error(),
 			})
 		}
 
-		result := db.Where("id IN ?", req.IDs).Delete(&models.Summaries{})
-		if result.Error != nil {
+		var item models.StudyItem
+		if err := db.Where("workspace_id = ?", identity.WorkspaceID).First(&item, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return c.Status(404).JSON(fiber.Map{
+					"error":   "not_found",
+					"message": "Study item not found",
+				})
+			}
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "database_error",
-				"message": "Failed to delete summaries",
-				"details": result.Error.Error(),
+				"message": "Failed to find study item",
+				"details": err.Error(),
 			})
 		}
 
-		return c.Status(200).JSON(fiber.Map{
-			"message":       fmt.Sprintf("Successfully deleted %d summaries", result.RowsAffected),
-			"deleted_count": result.RowsAffected,
-		})
-	})
+		now := time.Now()
+		var review models.StudyReview
+		var state utils.SM2State
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			// Create the schedule of a first review unless it exists, so that concurrent first
+			// reviews queue on the same row instead of violating idx_study_schedules_user_item
+			initial := utils.NewSM2State()
+			if err := tx.Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(&models.StudySchedule{
+				StudyItemID:  item.ID,
+				UserID:       identity.UserID,
+				EaseFactor:   initial.EaseFactor,
+				IntervalDays: initial.IntervalDays,
+				Repetitions:  initial.Repetitions,
+				DueAt:        now,
+			}).Error; err != nil {
+				return err
+			}
 
-	// Get summary statistics
-	app.Get("/summaries/stats", func(c *fiber.Ctx) error {
-		var stats struct {
-			TotalSummaries int64            `json:"total_summaries"`
-			ByStyle        map[string]int64 `json:"by_style"`
-			ByLanguage     map[string]int64 `json:"by_language"`
-			AvgSummaryTime float64          `json:"avg_summary_time"`
-			TotalPDFs      int64            `json:"total_pdfs"`
-		}
+			var schedule models.StudySchedule
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("study_item_id = ? AND user_id = ?", item.ID, identity.UserID).
+				First(&schedule).Error; err != nil {
+				return err
+			}
+
+			state = utils.ScheduleSM2(utils.SM2State{
+				EaseFactor:   schedule.EaseFactor,
+				IntervalDays: schedule.IntervalDays,
+				Repetitions:  schedule.Repetitions,
+			}, *req.Grade)
+
+			schedule.EaseFactor = state.EaseFactor
+			schedule.IntervalDays = state.IntervalDays
+			schedule.Repetitions = state.Repetitions
+			schedule.DueAt = state.NextReview(now)
+			schedule.LastReviewedAt = &now
+			if err := tx.Omit(clause.Associations).Save(&schedule).Error; err != nil {
+				return err
+			}
 
-		// Get total summaries
-		if err := db.Model(&models.Summaries{}).Count(&stats.TotalSummaries).Error; err != nil {
+			review = models.StudyReview{
+				StudyItemID:  item.ID,
+				UserID:       identity.UserID,
+				WorkspaceID:  identity.WorkspaceID,
+				Grade:        *req.Grade,
+				EaseFactor:   state.EaseFactor,
+				IntervalDays: state.IntervalDays,
+				DueAt:        schedule.DueAt,
+			}
+			return tx.Omit(clause.Associations).Create(&review).Error
+		})
+		if err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "database_error",
-				"message": "Failed to get summary statistics",
+				"message": "Failed to record review",
 				"details": err.Error(),
 			})
 		}
 
-		// Get summaries by style
-		var styleStats []struct {
-			Style string `json:"style"`
-			Count int64  `json:"count"`
+		response := utils.ConvertStudyReviewToResponse(review)
+		response.Repetitions = state.Repetitions
+		return c.Status(201).JSON(response)
+	})
+
+	// The caller's review history, newest first
+	study.Get("/history", func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		identity := utils.GetIdentity(c)
+
+		limit := c.QueryInt("limit", 50)
+		if limit < 1 || limit > 500 {
+			limit = 50
 		}
-		if err := db.Model(&models.Summaries{}).Select("style, COUNT(*) as count").Group("style").Find(&styleStats).Error; err != nil {
+
+		query := db.Where("user_id = ? AND workspace_id = ?", identity.UserID, identity.WorkspaceID)
+		if itemID := c.QueryInt("item_id"); itemID > 0 {
+			query = query.Where("study_item_id = ?", itemID)
+		}
+
+		var reviews []models.StudyReview
+		if err := query.Order("created_at DESC").Limit(limit).Find(&reviews).Error; err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "database_error",
-				"message": "Failed to get style statistics",
+				"message": "Failed to fetch review history",
 				"details": err.Error(),
 			})
 		}
 
-		stats.ByStyle = make(map[string]int64)
-		for _, stat := range styleStats {
-			stats.ByStyle[stat.Style] = stat.Count
+		data := make([]dto.StudyReviewResponse, len(reviews))
+		for i, review := range reviews {
+			data[i] = utils.ConvertStudyReviewToResponse(review)
 		}
 
-		// Get summaries by language
-		var languageStats []struct {
-			Language string `json:"language"`
-			Count    int64  `json:"count"`
+		return c.Status(200).JSON(fiber.Map{
+			"data": data,
+		})
+	})
+
+	// Progress stats for the caller, optionally limited to pdf_ids
+	study.Get("/stats", func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		identity := utils.GetIdentity(c)
+
+		pdfIDs, err := utils.ParseIDList(c.Query("pdf_ids"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
 		}
-		if err := db.Model(&models.Summaries{}).Select("language, COUNT(*) as count").Group("language").Find(&languageStats).Error; err != nil {
+
+		summaryIDs, err := utils.PrimarySummaryIDs(db, pdfIDs)
+		if err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "database_error",
-				"message": "Failed to get language statistics",
+				"message": "Failed to find summaries",
 				"details": err.Error(),
 			})
 		}
 
-		stats.ByLanguage = make(map[string]int64)
-		for _, stat := range languageStats {
-			stats.ByLanguage[stat.Language] = stat.Count
+		now := time.Now()
+		stats := dto.StudyStatsResponse{
+			UserID:       identity.UserID,
+			WorkspaceID:  identity.WorkspaceID,
+			DailyReviews: []dto.DailyReviewCount{},
+		}
+
+		if len(summaryIDs) > 0 {
+			items := func() *gorm.DB { return utils.StudyItemsQuery(db, identity, summaryIDs) }
+			counts := []struct {
+				query *gorm.DB
+				count *int64
+			}{
+				{items(), &stats.TotalItems},
+				{items().Where("ss.id IS NULL"), &stats.NewItems},
+				{items().Where("ss.id IS NOT NULL AND ss.due_at <= ?", now), &stats.DueItems},
+				{items().Where("ss.repetitions > 0"), &stats.LearnedItems},
+				{items().Where("ss.interval_days >= 21"), &stats.MatureItems},
+			}
+			for _, count := range counts {
+				if err := count.query.Count(count.count).Error; err != nil {
+					return c.Status(500).JSON(fiber.Map{
+						"error":   "database_error",
+						"message": "Failed to count study items",
+						"details": err.Error(),
+					})
+				}
+			}
+		}
+
+		reviews := func() *gorm.DB {
+			query := db.Table("study_reviews sr").
+				Where("sr.deleted_at IS NULL AND sr.user_id = ? AND sr.workspace_id = ?", identity.UserID, identity.WorkspaceID)
+			if len(pdfIDs) > 0 {
+				query = query.Joins("JOIN study_items si ON si.id = sr.study_item_id").Where("si.pdf_id IN ?", pdfIDs)
+			}
+			return query
 		}
 
-		// Get average summary time
-		var avgTime sql.NullFloat64
-		if err := db.Model(&models.Summaries{}).Select("AVG(summary_time)").Scan(&avgTime).Error; err != nil {
+		var totals struct {
+			Total         int64
+			AverageGrade  float64
+			RetentionRate float64
+		}
+		if err := reviews().Select(`COUNT(*) AS total,
+			COALESCE(AVG(sr.grade), 0) AS average_grade,
+			COALESCE(AVG(CASE WHEN sr.grade >= ? THEN 1.0 ELSE 0.0 END), 0) AS retention_rate`, utils.PassingGrade).
+			Scan(&totals).Error; err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "database_error",
-				"message": "Failed to get average summary time",
+				"message": "Failed to fetch review stats",
 				"details": err.Error(),
 			})
 		}
-		if avgTime.Valid {
-			stats.AvgSummaryTime = avgTime.Float64
-		}
+		stats.TotalReviews = totals.Total
+		stats.AverageGrade = totals.AverageGrade
+		stats.RetentionRate = totals.RetentionRate
 
-		// Get total PDFs
-		if err := db.Model(&models.PDF{}).Count(&stats.TotalPDFs).Error; err != nil {
+		// Daily review counts for the last 14 days, including days without reviews
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		since := today.AddDate(0, 0, -13)
+		var daily []struct {
+			Day   time.Time
+			Count int64
+		}
+		if err := reviews().Select("DATE_TRUNC('day', sr.created_at) AS day, COUNT(*) AS count").
+			Where("sr.created_at >= ?", since).Group("day").Scan(&daily).Error; err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "database_error",
-				"message": "Failed to get PDF count",
+				"message": "Failed to fetch daily reviews",
 				"details": err.Error(),
 			})
 		}
 
+		counts := make(map[string]int64, len(daily))
+		for _, d := range daily {
+			counts[d.Day.Format("2006-01-02")] = d.Count
+		}
+		for day := since; !day.After(today); day = day.AddDate(0, 0, 1) {
+			date := day.Format("2006-01-02")
+			stats.DailyReviews = append(stats.DailyReviews, dto.DailyReviewCount{Date: date, Count: counts[date]})
+		}
+		stats.ReviewsToday = counts[today.Format("2006-01-02")]
+
 		return c.Status(200).JSON(stats)
 	})
 
-	// Log endpoints
-	app.Get("/logs", func(c *fiber.Ctx) error {
-		var logs []models.Log
+	// Quiz endpoints; attempts are recorded for the caller's identity
+	quizzes := app.Group("/quizzes", identityMiddleware)
 
-		// Pagination parameters
-		page := c.QueryInt("page", 1)
-		itemsPerPage := c.QueryInt("itemsperpage", 50)
-
-		if page < 1 {
-			page = 1
-		}
-		if itemsPerPage < 1 || itemsPerPage > 200 {
-			itemsPerPage = 50
+	// A quiz's questions; answers are only revealed to callers who have attempted it
+	quizzes.Get("/:id", func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		identity := utils.GetIdentity(c)
+		id, err := utils.ParseID(c.Params("id"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
 		}
 
-		offset := (page - 1) * itemsPerPage
-		limit := itemsPerPage
+		var quiz models.Quiz
+		if err := db.Preload("Questions", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("position ASC")
+		}).Where("workspace_id = ?", identity.WorkspaceID).First(&quiz, id).Error; err != nil {
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "Quiz not found",
+			})
+		}
 
-		// Filters
-		method := c.Query("method", "")
-		path := c.Query("path", "")
-		statusCode := c.QueryInt("status", 0)
-		minDuration := c.QueryInt("min_duration", 0)
+		includeAnswers := c.QueryBool("include_answers", false)
+		if includeAnswers {
+			var attempts int64
+			if err := db.Model(&models.QuizAttempt{}).
+				Where("quiz_id = ? AND user_id = ? AND workspace_id = ?", quiz.ID, identity.UserID, identity.WorkspaceID).
+				Count(&attempts).Error; err != nil {
+				return c.Status(500).JSON(fiber.Map{
+					"error":   "database_error",
+					"message": "Failed to check attempts",
+					"details": err.Error(),
+				})
+			}
+			if attempts == 0 {
+				return c.Status(403).JSON(fiber.Map{
+					"error":   "forbidden",
+					"message": "Answers are only shown after the quiz has been attempted",
+				})
+			}
+		}
 
-		query := db.Model(&models.Log{})
+		return c.Status(200).JSON(utils.ConvertQuizToResponse(quiz, includeAnswers))
+	})
 
-		if method != "" {
-			query = query.Where("method = ?", method)
-		}
-		if path != "" {
-			query = query.Where("path ILIKE ?", "%"+path+"%")
-		}
-		if statusCode != 0 {
-			query = query.Where("status_code = ?", statusCode)
-		}
-		if minDuration > 0 {
-			query = query.Where("duration >= ?", minDuration)
+	quizzes.Delete("/:id", func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		identity := utils.GetIdentity(c)
+		id, err := utils.ParseID(c.Params("id"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
 		}
 
-		// Get total count
-		var totalCount int64
-		if err := query.Count(&totalCount).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"error":   "database_error",
-				"message": "Failed to count logs",
+		var quiz models.Quiz
+		if err := db.Where("workspace_id = ?", identity.WorkspaceID).First(&quiz, id).Error; err != nil {
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "Quiz not found",
 			})
 		}
 
-		totalPages := int((totalCount + int64(itemsPerPage) - 1) / int64(itemsPerPage))
-
-		if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&logs).Error; err != nil {
+		if err := db.Unscoped().Delete(&quiz).Error; err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "database_error",
-				"message": "Failed to fetch logs",
+				"message": "Failed to delete quiz",
+				"details": err.Error(),
 			})
 		}
 
 		return c.Status(200).JSON(fiber.Map{
-			"data":           logs,
-			"page":           page,
-			"items_per_page": itemsPerPage,
-			"total_pages":    totalPages,
-			"total_items":    totalCount,
+			"message": "Quiz deleted successfully",
 		})
 	})
 
-	app.Get("/logs/stats", func(c *fiber.Ctx) error {
-		var stats struct {
-			TotalRequests    int64            `json:"total_requests"`
-			AvgDuration      float64          `json:"avg_duration_ms"`
-			ByStatusCode     map[string]int64 `json:"by_status_code"`
-			ByMethod         map[string]int64 `json:"by_method"`
-			SlowestEndpoints []struct {
-				Path        string  `json:"path"`
-				AvgDuration float64 `json:"avg_duration_ms"`
-			} `json:"slowest_endpoints"`
-		}
+	// Submit answers; multiple-choice answers are checked directly and short answers
+	// are graded by embedding similarity to the reference answer
+	quizzes.Post("/:id/attempts", func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		identity := utils.GetIdentity(c)
+		var req dto.QuizAttemptRequest
 
-		// Total requests
-		db.Model(&models.Log{}).Count(&stats.TotalRequests)
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Invalid request body",
+				"details": err.Error(),
+			})
+		}
 
-		// Average duration
-		var avgDuration sql.NullFloat64
-		db.Model(&models.Log{}).Select("AVG(duration)").Scan(&avgDuration)
-		if avgDuration.Valid {
-			stats.AvgDuration = avgDuration.Float64
+		id, err := utils.ParseID(c.Params("id"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
 		}
 
-		// By status code
-		var statusCounts []struct {
-			StatusCode int   `json:"status_code"`
-			Count      int64 `json:"count"`
+		var quiz models.Quiz
+		if err := db.Preload("Questions", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("position ASC")
+		}).Where("workspace_id = ?", identity.WorkspaceID).First(&quiz, id).Error; err != nil {
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "Quiz not found",
+			})
 		}
-		db.Model(&models.Log{}).Select("status_code, COUNT(*) as count").Group("status_code").Find(&statusCounts)
-		stats.ByStatusCode = make(map[string]int64)
-		for _, sc := range statusCounts {
-			stats.ByStatusCode[fmt.Sprintf("%d", sc.StatusCode)] = sc.Count
+
+		questions := make(map[uint]models.QuizQuestion, len(quiz.Questions))
+		for _, question := range quiz.Questions {
+			questions[question.ID] = question
 		}
 
-		// By method
-		var methodCounts []struct {
-			Method string `json:"method"`
-			Count  int64  `json:"count"`
+		submitted := make(map[uint]dto.QuizAnswerRequest, len(req.Answers))
+		for _, answer := range req.Answers {
+			if _, ok := questions[answer.QuestionID]; !ok {
+				return c.Status(400).JSON(fiber.Map{
+					"error":   "invalid_request",
+					"message": fmt.Sprintf("Question %d is not part of this quiz", answer.QuestionID),
+				})
+			}
+			submitted[answer.QuestionID] = answer
+		}
+
+		// Unanswered questions are recorded as incorrect
+		attempt := models.QuizAttempt{
+			QuizID:      quiz.ID,
+			UserID:      identity.UserID,
+			WorkspaceID: identity.WorkspaceID,
+			Total:       len(quiz.Questions),
+		}
+		for _, question := range quiz.Questions {
+			answer := models.QuizAnswer{QuestionID: question.ID}
+
+			if submission, ok := submitted[question.ID]; ok {
+				switch question.Type {
+				case utils.QuestionMultipleChoice:
+					answer.SelectedOption = submission.SelectedOption
+					answer.Correct = submission.SelectedOption != nil && question.CorrectOption != nil &&
+						*submission.SelectedOption == *question.CorrectOption
+				case utils.QuestionShortAnswer:
+					answer.Response = strings.TrimSpace(submission.Response)
+					correct, similarity, err := utils.GradeShortAnswer(c.UserContext(), answer.Response, question.Answer)
+					if err != nil {
+						return utils.SendPythonAPIError(c, err)
+					}
+					answer.Correct = correct
+					answer.Similarity = similarity
+				}
+			}
+
+			if answer.Correct {
+				attempt.CorrectCount++
+			}
+			attempt.Answers = append(attempt.Answers, answer)
 		}
-		db.Model(&models.Log{}).Select("method, COUNT(*) as count").Group("method").Find(&methodCounts)
-		stats.ByMethod = make(map[string]int64)
-		for _, mc := range methodCounts {
-			stats.ByMethod[mc.Method] = mc.Count
+		if attempt.Total > 0 {
+			attempt.Score = float64(attempt.CorrectCount) / float64(attempt.Total)
 		}
 
-		// Slowest endpoints
-		db.Model(&models.Log{}).
-			Select("path, AVG(duration) as avg_duration").
-			Group("path").
-			Order("avg_duration DESC").
-			Limit(10).
-			Find(&stats.SlowestEndpoints)
+		if err := db.Omit("Quiz", "Answers.Question").Create(&attempt).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to save attempt",
+				"details": err.Error(),
+			})
+		}
 
-		return c.Status(200).JSON(stats)
+		return c.Status(201).JSON(utils.ConvertQuizAttemptToResponse(attempt, questions))
 	})
 
-	// Chat endpoint - proxy to Python backend with RAG
-	app.Post("/chat", func(c *fiber.Ctx) error {
-		var req dto.ChatRequest
+	// The caller's attempt history for a quiz, newest first
+	quizzes.Get("/:id/attempts", func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		identity := utils.GetIdentity(c)
 
-		if err := c.BodyParser(&req); err != nil {
+		id, err := utils.ParseID(c.Params("id"))
+		if err != nil {
 			return c.Status(400).JSON(fiber.Map{
-				"error": "Invalid request body",
+				"error":   "invalid_request",
+				"message": err.Error(),
 			})
 		}
 
-		var ragContext string
-
-		// If PDF IDs are provided, find the most relevant summary
-		if len(req.PDFIDs) > 0 {
-			fmt.Printf("DEBUG: Processing RAG for %d PDF IDs: %v\n", len(req.PDFIDs), req.PDFIDs)
-
-			// Get Python API URL from environment
-			pythonAPIURL := os.Getenv("PYTHON_API_URL")
-			if pythonAPIURL == "" {
-				pythonAPIURL = "http://127.0.0.1:8000"
-			}
+		var quiz models.Quiz
+		if err := db.Preload("Questions").Where("workspace_id = ?", identity.WorkspaceID).First(&quiz, id).Error; err != nil {
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "Quiz not found",
+			})
+		}
 
-			// Generate embedding for user's message using Python backend
-			embeddingReq := map[string]interface{}{
-				"text": req.Message,
-			}
+		questions := make(map[uint]models.QuizQuestion, len(quiz.Questions))
+		for _, question := range quiz.Questions {
+			questions[question.ID] = question
+		}
 
-			jsonData, err := json.Marshal(embeddingReq)
-			if err != nil {
-				fmt.Printf("Warning: Failed to prepare embedding request: %v\n", err)
-			} else {
-				fmt.Printf("DEBUG: Calling embedding service at %s/embedding\n", pythonAPIURL)
-				resp, err := http.Post(
-					pythonAPIURL+"/embedding",
-					"application/json",
-					bytes.NewBuffer(jsonData),
-				)
-				if err != nil {
-					fmt.Printf("Warning: Failed to connect to embedding service: %v\n", err)
-				} else {
-					defer resp.Body.Close()
-
-					if resp.StatusCode == 200 {
-						body, err := io.ReadAll(resp.Body)
-						if err != nil {
-							fmt.Printf("Warning: Failed to read embedding response: %v\n", err)
-						} else {
-							var embeddingResp map[string]interface{}
-							if err := json.Unmarshal(body, &embeddingResp); err != nil {
-								fmt.Printf("Warning: Failed to parse embedding response: %v\n", err)
-							} else if embedding, ok := embeddingResp["embedding"].([]interface{}); ok {
-								fmt.Printf("DEBUG: Received embedding with %d dimensions\n", len(embedding))
-
-								// Convert interface{} slice to float32 slice (pgvector requires float32)
-								embeddingValues := make([]float32, len(embedding))
-								for i, v := range embedding {
-									if f, ok := v.(float64); ok {
-										embeddingValues[i] = float32(f)
-									}
-								}
-
-								// Convert embedding to pgvector format
-								queryEmbedding := pgvector.NewVector(embeddingValues)
-								fmt.Printf("DEBUG: Searching for similar summaries in PDFs: %v\n", req.PDFIDs)
-
-								// Find the most similar summary from the selected PDFs
-								var bestSummary models.Summaries
-								err := db.Model(&models.Summaries{}).
-									Where("pdf_id IN ? AND embedding IS NOT NULL", req.PDFIDs).
-									Order(fmt.Sprintf("embedding <=> '%s'", queryEmbedding.String())).
-									Limit(1).
-									First(&bestSummary).Error
-
-								if err == nil {
-									ragContext = bestSummary.Content
-									fmt.Printf("✓ Found relevant summary (ID: %d, PDF ID: %d) for chat context\n", bestSummary.ID, bestSummary.PDFID)
-									fmt.Printf("DEBUG: Context length: %d characters\n", len(ragContext))
-								} else if err != gorm.ErrRecordNotFound {
-									fmt.Printf("Warning: Failed to find similar summary: %v\n", err)
-								} else {
-									fmt.Printf("Warning: No summaries found with embeddings for PDF IDs: %v\n", req.PDFIDs)
-								}
-							} else {
-								fmt.Printf("Warning: Failed to extract embedding from response\n")
-							}
-						}
-					} else if resp.StatusCode == 429 {
-						// Rate limit error
-						bodyBytes, _ := io.ReadAll(resp.Body)
-						fmt.Printf("⚠️  Rate limit exceeded: %s\n", string(bodyBytes))
-						fmt.Println("Tip: Wait a minute before trying again, or consider upgrading your Gemini API quota")
-					} else {
-						bodyBytes, _ := io.ReadAll(resp.Body)
-						fmt.Printf("Warning: Embedding service returned status %d: %s\n", resp.StatusCode, string(bodyBytes))
-					}
-				}
-			}
+		var attempts []models.QuizAttempt
+		if err := db.Preload("Answers").
+			Where("quiz_id = ? AND user_id = ? AND workspace_id = ?", quiz.ID, identity.UserID, identity.WorkspaceID).
+			Order("created_at DESC").Find(&attempts).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to fetch attempts",
+				"details": err.Error(),
+			})
 		}
 
-		// Get Python API URL from environment
-		pythonAPIURL := os.Getenv("PYTHON_API_URL")
-		if pythonAPIURL == "" {
-			pythonAPIURL = "http://127.0.0.1:8000"
+		data := make([]dto.QuizAttemptResponse, len(attempts))
+		for i, attempt := range attempts {
+			data[i] = utils.ConvertQuizAttemptToResponse(attempt, questions)
 		}
 
-		// Prepare request with context
-		chatReq := map[string]interface{}{
-			"message": req.Message,
-			"history": req.History,
+		return c.Status(200).JSON(fiber.Map{
+			"data": data,
+		})
+	})
+
+	// Admin endpoints
+	admin := app.Group("/admin", utils.AdminAuthMiddleware(cfg.Admin.Token))
+
+	admin.Get("/export", func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		tempFile, err := os.CreateTemp("", "backup-*.tar.gz")
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "server_error",
+				"message": "Failed to create temporary file",
+				"details": err.Error(),
+			})
 		}
-		if ragContext != "" {
-			chatReq["context"] = ragContext
+		// Unlink now; the open handle keeps the data readable while streaming
+		os.Remove(tempFile.Name())
+
+		if _, err := backup.Export(db, tempFile); err != nil {
+			tempFile.Close()
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "export_error",
+				"message": "Failed to create backup archive",
+				"details": err.Error(),
+			})
 		}
 
-		// Marshal request to JSON
-		jsonData, err := json.Marshal(chatReq)
-		if err != nil {
+		if _, err := tempFile.Seek(0, io.SeekStart); err != nil {
+			tempFile.Close()
 			return c.Status(500).JSON(fiber.Map{
-				"error": "Failed to prepare request",
+				"error":   "server_error",
+				"message": "Failed to read backup archive",
+				"details": err.Error(),
 			})
 		}
 
-		// Forward request to Python backend
-		resp, err := http.Post(
-			pythonAPIURL+"/chat",
-			"application/json",
-			bytes.NewBuffer(jsonData),
-		)
-		if err != nil {
-			return c.Status(503).JSON(fiber.Map{
-				"error": "Failed to connect to AI service",
+		c.Set("Content-Type", "application/gzip")
+		c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"backup-%s.tar.gz\"", time.Now().Format("20060102-150405")))
+		return c.Status(200).SendStream(tempFile)
+	})
+
+	// Re-cluster the library into topics, replacing the stored ones
+	admin.Post("/topics/rebuild", func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		var req dto.TopicRebuildRequest
+
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&req); err != nil {
+				return c.Status(400).JSON(fiber.Map{
+					"error":   "invalid_request",
+					"message": "Invalid request body",
+					"details": err.Error(),
+				})
+			}
+		}
+		if req.K < 0 {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "k cannot be negative",
 			})
 		}
-		defer resp.Body.Close()
 
-		// Read response
-		body, err := io.ReadAll(resp.Body)
+		result, err := topics.Rebuild(db, req.K)
+		if errors.Is(err, topics.ErrCannotCluster) {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
+		}
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{
-				"error": "Failed to read AI response",
+				"error":   "clustering_error",
+				"message": "Failed to rebuild topics",
+				"details": err.Error(),
 			})
 		}
+		fmt.Printf("✓ Clustered %d PDFs into %d topics\n", result.Documents, result.Topics)
 
-		// Forward response status and body
-		c.Status(resp.StatusCode)
-		c.Set("Content-Type", "application/json")
-		return c.Send(body)
+		return c.Status(200).JSON(result)
 	})
 
-	app.Listen("0.0.0.0:8080")
+	app.Get("/logs", logHandler.List)
+	app.Get("/logs/stats", logHandler.Stats)
+
+	// Chat endpoint - proxy to Python backend with RAG
+	app.Post("/chat", chatHandler.Chat)
+
+	return app
 }
@@ -0,0 +1,609 @@
+//go:build integration
+
+package main
+
+import (
+	"backend-go/backup"
+	"backend-go/config"
+	"backend-go/fakes"
+	"backend-go/models"
+	"backend-go/repository"
+	"backend-go/schema"
+	"backend-go/utils"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const testBucket = "integration-test"
+
+// testPDF is a minimal one-page PDF; only the page count is read from it
+var testPDF = []byte("%PDF-1.4\n1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj\n" +
+	"2 0 obj << /Type /Pages /Kids [3 0 R] /Count 1 >> endobj\n" +
+	"3 0 obj << /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >> endobj\n" +
+	"trailer << /Root 1 0 R >>\n%%EOF\n")
+
+// testDatabase returns a fresh, migrated database that is dropped when the test ends.
+// It connects to TEST_DATABASE_URL (a server with pgvector available) or, when that is
+// not set, starts a throwaway pgvector container with docker. Without either the test is skipped.
+func testDatabase(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	serverURL := os.Getenv("TEST_DATABASE_URL")
+	if serverURL == "" {
+		serverURL = startPostgresContainer(t)
+	}
+
+	admin, err := openDatabase(serverURL)
+	if err != nil {
+		t.Fatalf("failed to connect to %s: %v", serverURL, err)
+	}
+
+	name := fmt.Sprintf("integration_%d", time.Now().UnixNano())
+	if err := admin.Exec("CREATE DATABASE " + name).Error; err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+
+	db, err := openDatabase(databaseURL(serverURL, name))
+	if err != nil {
+		t.Fatalf("failed to connect to %s: %v", name, err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		admin.Exec("DROP DATABASE IF EXISTS " + name + " WITH (FORCE)")
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if err := schema.Migrate(db); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+// startPostgresContainer runs pgvector/pgvector:pg16 on a random local port and returns its URL
+func startPostgresContainer(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("set TEST_DATABASE_URL or install docker to run integration tests")
+	}
+
+	out, err := exec.Command("docker", "run", "-d", "--rm",
+		"-e", "POSTGRES_PASSWORD=postgres",
+		"-p", "127.0.0.1::5432",
+		"pgvector/pgvector:pg16").Output()
+	if err != nil {
+		t.Skipf("failed to start a Postgres container: %v", err)
+	}
+	container := strings.TrimSpace(string(out))
+	t.Cleanup(func() {
+		exec.Command("docker", "stop", container).Run()
+	})
+
+	out, err = exec.Command("docker", "port", container, "5432/tcp").Output()
+	if err != nil {
+		t.Fatalf("failed to read the container port: %v", err)
+	}
+	address := strings.TrimSpace(strings.Split(string(out), "\n")[0])
+	serverURL := "postgres://postgres:postgres@" + address + "/postgres?sslmode=disable"
+
+	// The server restarts once during initialization, so wait until it answers queries
+	deadline := time.Now().Add(60 * time.Second)
+	for {
+		db, err := openDatabase(serverURL)
+		if err == nil {
+			err = db.Exec("SELECT 1").Error
+			if sqlDB, dbErr := db.DB(); dbErr == nil {
+				sqlDB.Close()
+			}
+			if err == nil {
+				return serverURL
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Postgres did not start: %v", err)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+func openDatabase(url string) (*gorm.DB, error) {
+	return gorm.Open(postgres.Open(url), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+}
+
+// databaseURL returns serverURL pointing at database name
+func databaseURL(serverURL, name string) string {
+	base, query, _ := strings.Cut(serverURL, "?")
+	base = base[:strings.LastIndex(base, "/")+1] + name
+	if query != "" {
+		return base + "?" + query
+	}
+	return base
+}
+
+// integrationApp is the real HTTP server wired to a fresh database, the S3 stub and the fake AI service
+type integrationApp struct {
+	app    *fiber.App
+	db     *gorm.DB
+	s3     *fakes.S3
+	python *fakes.PythonAPI
+}
+
+func newIntegrationApp(t *testing.T) *integrationApp {
+	t.Helper()
+	db := testDatabase(t)
+
+	s3 := fakes.NewS3()
+	t.Cleanup(s3.Close)
+	t.Setenv("MINIO_ENDPOINT", s3.Endpoint())
+	t.Setenv("MINIO_ACCESS_KEY", "test")
+	t.Setenv("MINIO_SECRET_KEY", "test-secret")
+	t.Setenv("MINIO_USE_SSL", "false")
+	t.Setenv("MINIO_BUCKET", testBucket)
+
+	python := fakes.NewPythonAPI()
+	t.Cleanup(python.Close)
+	t.Setenv("PYTHON_API_URL", python.URL())
+
+	cfg, _, err := config.Load(nil)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("invalid config: %v", err)
+	}
+	if err := utils.InitMinIO(cfg.MinIO); err != nil {
+		t.Fatalf("failed to initialize MinIO: %v", err)
+	}
+	utils.SetPythonAPI(cfg.PythonAPI)
+	utils.SetVectorIndex(cfg.VectorIndex)
+	utils.SetQuiz(cfg.Quiz)
+	if err := utils.SetLanguages(cfg.Languages); err != nil {
+		t.Fatalf("failed to load languages: %v", err)
+	}
+
+	// Log writes must finish before the database is dropped
+	jobs := &utils.Jobs{}
+	t.Cleanup(func() { jobs.Wait(context.Background()) })
+
+	return &integrationApp{app: newApp(db, cfg, jobs), db: db, s3: s3, python: python}
+}
+
+// send performs a request against the app and returns the status and raw body
+func (a *integrationApp) send(t *testing.T, req *http.Request) (int, []byte) {
+	t.Helper()
+	resp, err := a.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", req.Method, req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	return resp.StatusCode, body
+}
+
+// sendJSON performs a request, checks the status and decodes the JSON response into out
+func (a *integrationApp) sendJSON(t *testing.T, req *http.Request, status int, out interface{}) {
+	t.Helper()
+	got, body := a.send(t, req)
+	if got != status {
+		t.Fatalf("%s %s = %d, want %d: %s", req.Method, req.URL.Path, got, status, body)
+	}
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			t.Fatalf("invalid JSON from %s %s: %v (%s)", req.Method, req.URL.Path, err, body)
+		}
+	}
+}
+
+func jsonRequest(method, path string, body interface{}) *http.Request {
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func uploadRequest(filename, title string, content []byte) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", filename)
+	part.Write(content)
+	writer.WriteField("title", title)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/pdf/upload", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+// TestUploadSummarizeChat drives a document through the whole workflow: upload, summarize,
+// read back, chat about it, download and delete
+func TestUploadSummarizeChat(t *testing.T) {
+	a := newIntegrationApp(t)
+
+	var uploaded struct {
+		ID        uint   `json:"id"`
+		Filename  string `json:"filename"`
+		Title     string `json:"title"`
+		PageCount int    `json:"page_count"`
+	}
+	a.sendJSON(t, uploadRequest("report.pdf", "Quarterly report", testPDF), 201, &uploaded)
+	if uploaded.Title != "Quarterly report" || uploaded.PageCount != 1 {
+		t.Fatalf("uploaded PDF = %+v", uploaded)
+	}
+	if stored, ok := a.s3.Object(testBucket, uploaded.Filename); !ok || !bytes.Equal(stored, testPDF) {
+		t.Fatalf("expected %s to be stored in the bucket", uploaded.Filename)
+	}
+
+	pdfPath := fmt.Sprintf("/pdf/%d", uploaded.ID)
+	a.sendJSON(t, jsonRequest(http.MethodPost, pdfPath+"/summarize", map[string]string{"style": "short", "language": "en"}), 200, nil)
+	files := a.python.SummarizedFiles()
+	if len(files) != 1 || !bytes.Equal(files[0], testPDF) {
+		t.Fatalf("expected the stored PDF to be sent to /summarize, got %d files", len(files))
+	}
+
+	var detail struct {
+		Summary  string `json:"summary"`
+		Style    string `json:"style"`
+		Language string `json:"language"`
+	}
+	a.sendJSON(t, httptest.NewRequest(http.MethodGet, pdfPath, nil), 200, &detail)
+	if detail.Summary != a.python.Summary || detail.Style != "short" || detail.Language != "en" {
+		t.Fatalf("PDF detail = %+v, want the new summary", detail)
+	}
+
+	var reply struct {
+		Reply string `json:"reply"`
+	}
+	a.sendJSON(t, jsonRequest(http.MethodPost, "/chat", map[string]interface{}{
+		"message": "What does the report say?",
+		"pdf_ids": []uint{uploaded.ID},
+	}), 200, &reply)
+	if reply.Reply != a.python.Reply {
+		t.Fatalf("chat reply = %q, want %q", reply.Reply, a.python.Reply)
+	}
+	chats := a.python.ChatRequests()
+	if len(chats) != 1 || chats[0].Context != a.python.Summary {
+		t.Fatalf("expected the summary to be sent as chat context, got %+v", chats)
+	}
+
+	status, body := a.send(t, httptest.NewRequest(http.MethodGet, pdfPath+"/download", nil))
+	if status != 200 || !bytes.Equal(body, testPDF) {
+		t.Fatalf("download = %d with %d bytes, want the uploaded file", status, len(body))
+	}
+
+	a.sendJSON(t, httptest.NewRequest(http.MethodDelete, pdfPath, nil), 200, nil)
+	if _, ok := a.s3.Object(testBucket, uploaded.Filename); ok {
+		t.Fatalf("expected %s to be removed from the bucket", uploaded.Filename)
+	}
+	if status, _ := a.send(t, httptest.NewRequest(http.MethodGet, pdfPath, nil)); status != 404 {
+		t.Fatalf("GET %s after delete = %d, want 404", pdfPath, status)
+	}
+}
+
+func TestEmbeddingCachePersists(t *testing.T) {
+	db := testDatabase(t)
+	ctx := context.Background()
+
+	// Without memory entries, the second cache can only find the embedding in the table
+	utils.NewEmbeddingCache("test-model", 0, db).Put(ctx, "what is it about?", []float32{0.1, 0.2, 0.3})
+
+	cache := utils.NewEmbeddingCache("test-model", 10, db)
+	embedding, ok := cache.Get(ctx, "what is it about?")
+	if !ok || len(embedding) != 3 {
+		t.Fatalf("Get = %v, %v, want the stored embedding", embedding, ok)
+	}
+	if _, ok := utils.NewEmbeddingCache("other-model", 10, db).Get(ctx, "what is it about?"); ok {
+		t.Error("expected embeddings of another model not to be reused")
+	}
+
+	cache.Get(ctx, "what is it about?")
+	if stats := cache.Stats(); stats.DatabaseHits != 1 || stats.MemoryHits != 1 {
+		t.Errorf("stats = %+v, want one database hit followed by one memory hit", stats)
+	}
+}
+
+func TestDeletingSummariesRefreshesPDF(t *testing.T) {
+	db := testDatabase(t)
+	ctx := context.Background()
+	summaries := repository.NewSummaryRepository(db)
+
+	pdf := models.PDF{Filename: "report.pdf", FileSize: 1, Title: "Report", PageCount: 1}
+	if err := db.Create(&pdf).Error; err != nil {
+		t.Fatalf("failed to create PDF: %v", err)
+	}
+	older := models.Summaries{PDFID: &pdf.ID, Content: "older", Style: "short", Language: "en"}
+	newer := models.Summaries{PDFID: &pdf.ID, Content: "newer", Style: "detailed", Language: "de"}
+	for _, summary := range []*models.Summaries{&older, &newer} {
+		if err := db.Omit("Embedding").Create(summary).Error; err != nil {
+			t.Fatalf("failed to create summary: %v", err)
+		}
+	}
+
+	assertPDF := func(summary, style string) {
+		t.Helper()
+		var got models.PDF
+		db.First(&got, pdf.ID)
+		if got.Summary != summary || got.Style != style {
+			t.Fatalf("PDF summary = %q (%s), want %q (%s)", got.Summary, got.Style, summary, style)
+		}
+	}
+
+	// Deleting the latest summary falls back to the one before it
+	if err := summaries.Delete(ctx, &newer); err != nil {
+		t.Fatalf("failed to delete summary: %v", err)
+	}
+	assertPDF("older", "short")
+
+	// Deleting the pinned and only summary clears the fields
+	db.Model(&pdf).Update("pinned_summary_id", older.ID)
+	if _, err := summaries.DeleteMany(ctx, []uint{older.ID}); err != nil {
+		t.Fatalf("failed to delete summaries: %v", err)
+	}
+	assertPDF("", "")
+}
+
+func TestEditingSummaryRefreshesPDF(t *testing.T) {
+	a := newIntegrationApp(t)
+
+	pdf := models.PDF{Filename: "report.pdf", FileSize: 1, Title: "Report", PageCount: 1}
+	if err := a.db.Create(&pdf).Error; err != nil {
+		t.Fatalf("failed to create PDF: %v", err)
+	}
+	older := models.Summaries{PDFID: &pdf.ID, Content: "older", Style: "short", Language: "en"}
+	newer := models.Summaries{PDFID: &pdf.ID, Content: "newer", Style: "short", Language: "en"}
+	for _, summary := range []*models.Summaries{&older, &newer} {
+		if err := a.db.Omit("Embedding").Create(summary).Error; err != nil {
+			t.Fatalf("failed to create summary: %v", err)
+		}
+	}
+
+	// Without a new embedding, the edit is saved with the summary's embedding still NULL
+	a.python.EmbeddingStatus = http.StatusInternalServerError
+	a.sendJSON(t, jsonRequest(http.MethodPatch, fmt.Sprintf("/summaries/%d", newer.ID), map[string]string{"content": "edited"}), 200, nil)
+
+	var got models.PDF
+	a.db.First(&got, pdf.ID)
+	if got.Summary != "edited" {
+		t.Fatalf("PDF summary = %q, want the edited latest summary", got.Summary)
+	}
+}
+
+func TestConcurrentFirstReviews(t *testing.T) {
+	a := newIntegrationApp(t)
+
+	pdf := models.PDF{Filename: "notes.pdf", FileSize: 1, Title: "Notes", PageCount: 1}
+	if err := a.db.Create(&pdf).Error; err != nil {
+		t.Fatalf("failed to create PDF: %v", err)
+	}
+	summary := models.Summaries{PDFID: &pdf.ID, Content: "- A key point", Style: "short", Language: "en"}
+	if err := a.db.Create(&summary).Error; err != nil {
+		t.Fatalf("failed to create summary: %v", err)
+	}
+	item := models.StudyItem{WorkspaceID: utils.DefaultWorkspaceID, SummaryID: summary.ID, PDFID: pdf.ID, Content: "A key point"}
+	if err := a.db.Create(&item).Error; err != nil {
+		t.Fatalf("failed to create study item: %v", err)
+	}
+
+	// Every review finds no schedule yet; all of them must still be recorded
+	const reviews = 5
+	statuses := make(chan int, reviews)
+	for i := 0; i < reviews; i++ {
+		go func() {
+			req := jsonRequest(http.MethodPost, fmt.Sprintf("/study/items/%d/review", item.ID), map[string]int{"grade": 5})
+			req.Header.Set("X-User-ID", "alice")
+			resp, err := a.app.Test(req, -1)
+			if err != nil {
+				statuses <- 0
+				return
+			}
+			resp.Body.Close()
+			statuses <- resp.StatusCode
+		}()
+	}
+	for i := 0; i < reviews; i++ {
+		if status := <-statuses; status != http.StatusCreated {
+			t.Errorf("review status = %d, want 201", status)
+		}
+	}
+
+	var schedules []models.StudySchedule
+	a.db.Where("study_item_id = ? AND user_id = ?", item.ID, "alice").Find(&schedules)
+	if len(schedules) != 1 || schedules[0].Repetitions != reviews {
+		t.Fatalf("schedules = %+v, want one schedule with %d repetitions", schedules, reviews)
+	}
+}
+
+func TestQuizAnswersNeedAnAttempt(t *testing.T) {
+	a := newIntegrationApp(t)
+
+	pdf := models.PDF{Filename: "quiz.pdf", FileSize: 1, Title: "Quiz", PageCount: 1}
+	if err := a.db.Create(&pdf).Error; err != nil {
+		t.Fatalf("failed to create PDF: %v", err)
+	}
+	correct := 1
+	quiz := models.Quiz{PDFID: pdf.ID, WorkspaceID: "team", Title: "Quiz", Language: "en", Questions: []models.QuizQuestion{{
+		Position: 1, Type: "multiple_choice", Question: "2 + 2?", Options: []string{"3", "4"}, CorrectOption: &correct, Answer: "4",
+	}}}
+	if err := a.db.Create(&quiz).Error; err != nil {
+		t.Fatalf("failed to create quiz: %v", err)
+	}
+
+	request := func(method, path, workspace string, body interface{}) *http.Request {
+		req := jsonRequest(method, path, body)
+		req.Header.Set("X-User-ID", "alice")
+		req.Header.Set("X-Workspace-ID", workspace)
+		return req
+	}
+	quizPath := fmt.Sprintf("/quizzes/%d", quiz.ID)
+
+	a.sendJSON(t, request(http.MethodGet, quizPath+"?include_answers=true", "other", nil), http.StatusNotFound, nil)
+	a.sendJSON(t, request(http.MethodGet, quizPath+"?include_answers=true", "team", nil), http.StatusForbidden, nil)
+
+	a.sendJSON(t, request(http.MethodPost, quizPath+"/attempts", "team", map[string]interface{}{
+		"answers": []map[string]interface{}{{"question_id": quiz.Questions[0].ID, "selected_option": 0}},
+	}), http.StatusCreated, nil)
+
+	var got struct {
+		Questions []struct {
+			Answer string `json:"answer"`
+		} `json:"questions"`
+	}
+	a.sendJSON(t, request(http.MethodGet, quizPath+"?include_answers=true", "team", nil), http.StatusOK, &got)
+	if len(got.Questions) != 1 || got.Questions[0].Answer != "4" {
+		t.Fatalf("questions = %+v, want the answer after an attempt", got.Questions)
+	}
+}
+
+func TestBackupRoundTrip(t *testing.T) {
+	// The app sets up the storage client that backups read files through
+	a := newIntegrationApp(t)
+	ctx := context.Background()
+
+	pdf := models.PDF{Filename: "report.pdf", FileSize: 1, Title: "Report", PageCount: 3}
+	if err := a.db.Create(&pdf).Error; err != nil {
+		t.Fatalf("failed to create PDF: %v", err)
+	}
+	page := 2
+	outline := models.DocumentOutline{PDFID: pdf.ID, Source: "embedded", Language: "en", Sections: []models.OutlineSection{{
+		Heading: "Introduction", Level: 1, Page: &page,
+		Children: []models.OutlineSection{{Heading: "Scope", Level: 2, Gist: "What the report covers"}},
+	}}}
+	if err := a.db.Create(&outline).Error; err != nil {
+		t.Fatalf("failed to create outline: %v", err)
+	}
+	topic := models.Topic{Label: "Finance", Size: 1, Members: []models.TopicMember{{PDFID: pdf.ID, Score: 0.9}}}
+	if err := a.db.Create(&topic).Error; err != nil {
+		t.Fatalf("failed to create topic: %v", err)
+	}
+
+	var archive bytes.Buffer
+	if _, err := backup.Export(a.db.WithContext(ctx), &archive); err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+
+	restored := testDatabase(t)
+	result, err := backup.Restore(restored.WithContext(ctx), &archive, false)
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if result.PDFs != 1 || result.Outlines != 1 || result.Topics != 1 || result.TopicMembers != 1 {
+		t.Fatalf("restore result = %+v, want one PDF, outline, topic and topic assignment", result)
+	}
+
+	var got models.DocumentOutline
+	if err := restored.Where("pdf_id = ?", pdf.ID).First(&got).Error; err != nil {
+		t.Fatalf("restored outline not found: %v", err)
+	}
+	if got.Source != "embedded" || len(got.Sections) != 1 || got.Sections[0].Page == nil || *got.Sections[0].Page != page ||
+		len(got.Sections[0].Children) != 1 || got.Sections[0].Children[0].Gist != "What the report covers" {
+		t.Fatalf("restored outline = %+v, want the original sections", got)
+	}
+
+	// The restored assignments keep the topic filter of the PDF list working
+	var listed struct {
+		Data []struct {
+			ID uint `json:"id"`
+		} `json:"data"`
+	}
+	jobs := &utils.Jobs{}
+	t.Cleanup(func() { jobs.Wait(ctx) })
+	restoredApp := newApp(restored, config.Default(), jobs)
+	resp, err := restoredApp.Test(httptest.NewRequest(http.MethodGet, fmt.Sprintf("/pdf?topic=%d", topic.ID), nil), -1)
+	if err != nil {
+		t.Fatalf("GET /pdf failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&listed); err != nil || len(listed.Data) != 1 || listed.Data[0].ID != pdf.ID {
+		t.Fatalf("PDFs of topic %d = %+v (%v), want the restored PDF", topic.ID, listed.Data, err)
+	}
+}
+
+func TestRestoreIntoExistingDatabase(t *testing.T) {
+	a := newIntegrationApp(t)
+	ctx := context.Background()
+	db := a.db.WithContext(ctx)
+
+	pdf := models.PDF{Filename: "report.pdf", FileSize: 1, Title: "Report", PageCount: 1}
+	if err := db.Create(&pdf).Error; err != nil {
+		t.Fatalf("failed to create PDF: %v", err)
+	}
+	summary := models.Summaries{PDFID: &pdf.ID, Content: "Revenue grew.", Style: "short", Language: "en"}
+	if err := db.Omit("Embedding").Create(&summary).Error; err != nil {
+		t.Fatalf("failed to create summary: %v", err)
+	}
+	item := models.StudyItem{WorkspaceID: "default", SummaryID: summary.ID, PDFID: pdf.ID, Content: "Revenue grew."}
+	term := models.GlossaryTerm{PDFID: pdf.ID, Term: "Revenue", NormalizedTerm: "revenue", Definition: "Archived definition", Language: "en"}
+	for _, row := range []interface{}{&item, &term} {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatalf("failed to create %T: %v", row, err)
+		}
+	}
+	schedule := models.StudySchedule{StudyItemID: item.ID, UserID: "alice", EaseFactor: 2.5, Repetitions: 3, DueAt: time.Now()}
+	if err := db.Create(&schedule).Error; err != nil {
+		t.Fatalf("failed to create schedule: %v", err)
+	}
+
+	var archive bytes.Buffer
+	if _, err := backup.Export(db, &archive); err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+
+	// Meanwhile the same item, schedule and term were recreated under new IDs
+	db.Unscoped().Delete(&item)
+	db.Unscoped().Delete(&term)
+	recreated := models.StudyItem{WorkspaceID: "default", SummaryID: summary.ID, PDFID: pdf.ID, Content: "Revenue grew."}
+	newTerm := models.GlossaryTerm{PDFID: pdf.ID, Term: "Revenue", NormalizedTerm: "revenue", Definition: "New definition", Language: "en"}
+	for _, row := range []interface{}{&recreated, &newTerm} {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatalf("failed to recreate %T: %v", row, err)
+		}
+	}
+	schedules := []models.StudySchedule{
+		{StudyItemID: recreated.ID, UserID: "alice", EaseFactor: 2.5, DueAt: time.Now()},
+		{StudyItemID: recreated.ID, UserID: "bob", EaseFactor: 2.5, DueAt: time.Now()},
+	}
+	if err := db.Create(&schedules).Error; err != nil {
+		t.Fatalf("failed to recreate schedules: %v", err)
+	}
+
+	if _, err := backup.Restore(db, bytes.NewReader(archive.Bytes()), false); err != nil {
+		t.Fatalf("restore into a database with the same rows under other IDs failed: %v", err)
+	}
+
+	var items []models.StudyItem
+	db.Where("summary_id = ?", summary.ID).Find(&items)
+	if len(items) != 1 || items[0].ID != recreated.ID {
+		t.Fatalf("study items = %+v, want the archived item merged into item %d", items, recreated.ID)
+	}
+	var restoredSchedules []models.StudySchedule
+	db.Where("study_item_id = ?", recreated.ID).Order("user_id").Find(&restoredSchedules)
+	if len(restoredSchedules) != 2 || restoredSchedules[0].UserID != "alice" || restoredSchedules[0].Repetitions != 3 {
+		t.Fatalf("schedules = %+v, want alice's archived schedule next to bob's", restoredSchedules)
+	}
+	var terms []models.GlossaryTerm
+	db.Where("pdf_id = ?", pdf.ID).Find(&terms)
+	if len(terms) != 1 || terms[0].ID != newTerm.ID || terms[0].Definition != "Archived definition" {
+		t.Fatalf("glossary terms = %+v, want the archived term merged into term %d", terms, newTerm.ID)
+	}
+}
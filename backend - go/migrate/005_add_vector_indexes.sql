@@ -0,0 +1,13 @@
+-- Migration: Approximate nearest-neighbour index for summary embeddings
+-- Reason: Chat retrieval orders summaries by cosine distance (embedding <=> query),
+-- which is a sequential scan without a vector index.
+-- The Go migration creates the configured index type (VECTOR_INDEX_TYPE) on every vector column;
+-- this file is the equivalent for the default HNSW setup.
+
+DROP INDEX IF EXISTS idx_summaries_embedding_ivfflat;
+CREATE INDEX IF NOT EXISTS idx_summaries_embedding_hnsw
+    ON summaries USING hnsw (embedding vector_cosine_ops) WITH (m = 16, ef_construction = 64);
+
+-- Search accuracy is tuned per query by the application:
+-- SET LOCAL hnsw.ef_search = 100;   -- HNSW_EF_SEARCH
+-- SET LOCAL ivfflat.probes = 10;    -- IVFFLAT_PROBES
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// OutlineSection is one heading of a document outline; sections nest through Children
+type OutlineSection struct {
+	Heading  string           `json:"heading"`
+	Level    int              `json:"level"`
+	Page     *int             `json:"page"`
+	Gist     string           `json:"gist"`
+	Children []OutlineSection `json:"children"`
+}
+
+type DocumentOutline struct {
+	gorm.Model
+	PDFID         uint             `gorm:"not null;uniqueIndex"`
+	Source        string           `gorm:"not null"` // "embedded" (PDF bookmarks) or "ai"
+	Sections      []OutlineSection `gorm:"type:jsonb;serializer:json"`
+	Language      string           `gorm:"not null"`
+	ModelName     string           // AI model that derived the structure or wrote the gists
+	PromptVersion string           // Prompt template version used for generation
+	PDF           PDF              `gorm:"foreignKey:PDFID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// EmbeddingCache stores the embedding of a text so that identical text is not embedded twice
+type EmbeddingCache struct {
+	Model     string          `gorm:"primaryKey"`                 // Embedding model that produced the vector
+	TextHash  string          `gorm:"primaryKey;size:64"`         // Hex SHA-256 of the normalized text
+	Embedding pgvector.Vector `gorm:"type:vector(1024);not null"` // Same dimension as summaries.embedding
+	CreatedAt time.Time
+}
+
+// TableName keeps the table name singular, as it names a cache rather than a collection
+func (EmbeddingCache) TableName() string {
+	return "embedding_cache"
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type Flashcard struct {
+	gorm.Model
+	PDFID         uint       `gorm:"not null;index"`
+	SummaryID     *uint      `gorm:"index"` // Summary the card was generated from; nil when generated from the document
+	Question      string     `gorm:"type:text;not null"`
+	Answer        string     `gorm:"type:text;not null"`
+	SourcePage    *int       // 1-based PDF page the answer comes from
+	Language      string     `gorm:"not null"`
+	ModelName     string     // AI model that generated the card
+	PromptVersion string     // Prompt template version used for generation
+	PDF           PDF        `gorm:"foreignKey:PDFID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Summary       *Summaries `gorm:"foreignKey:SummaryID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+}
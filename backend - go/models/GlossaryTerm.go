@@ -0,0 +1,18 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type GlossaryTerm struct {
+	gorm.Model
+	PDFID          uint   `gorm:"not null;uniqueIndex:idx_glossary_terms_pdf_term"`
+	Term           string `gorm:"not null"`
+	NormalizedTerm string `gorm:"not null;uniqueIndex:idx_glossary_terms_pdf_term;index"` // Lowercased term used for lookups
+	Definition     string `gorm:"type:text;not null"`
+	Page           *int   // 1-based page where the term first appears
+	Language       string `gorm:"not null"`
+	ModelName      string // AI model that extracted the term
+	PromptVersion  string // Prompt template version used for extraction
+	PDF            PDF    `gorm:"foreignKey:PDFID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
@@ -6,14 +6,16 @@ import (
 
 type PDF struct {
 	gorm.Model
-	Filename       string `gorm:"not null"`
-	FileSize       int64  `gorm:"not null"`
-	Title          string `gorm:"not null"`
-	PageCount      int    `gorm:"not null"`
-	Summary        string
-	Style          string
-	Language       string
-	SummaryTime    float64
-	SummaryVersion int
-	Summaries      []Summaries `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Filename         string `gorm:"not null"`
+	FileSize         int64  `gorm:"not null"`
+	Title            string `gorm:"not null"`
+	PageCount        int    `gorm:"not null"`
+	Summary          string
+	Style            string
+	Language         string
+	SummaryTime      float64
+	SummaryVersion   int
+	DetectedLanguage string      // ISO 639-1 code detected from the PDF text
+	PinnedSummaryID  *uint       `gorm:"index"` // Summary explicitly chosen as primary
+	Summaries        []Summaries `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 }
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type Quiz struct {
+	gorm.Model
+	PDFID         uint           `gorm:"not null;index"`
+	WorkspaceID   string         `gorm:"not null;default:'default';index"` // Workspace of the identity that generated it
+	Title         string         `gorm:"not null"`
+	Language      string         `gorm:"not null"`
+	ModelName     string         // AI model that generated the quiz
+	PromptVersion string         // Prompt template version used for generation
+	PDF           PDF            `gorm:"foreignKey:PDFID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Questions     []QuizQuestion `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
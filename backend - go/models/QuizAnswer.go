@@ -0,0 +1,16 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type QuizAnswer struct {
+	gorm.Model
+	AttemptID      uint         `gorm:"not null;index"`
+	QuestionID     uint         `gorm:"not null;index"`
+	SelectedOption *int         // Chosen option for multiple-choice questions
+	Response       string       `gorm:"type:text"` // Free-text answer for short-answer questions
+	Correct        bool         `gorm:"not null"`
+	Similarity     *float64     // Embedding similarity to the reference answer for short answers
+	Question       QuizQuestion `gorm:"foreignKey:QuestionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
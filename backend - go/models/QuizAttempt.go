@@ -0,0 +1,17 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type QuizAttempt struct {
+	gorm.Model
+	QuizID       uint         `gorm:"not null;index"`
+	UserID       string       `gorm:"not null;index"`
+	WorkspaceID  string       `gorm:"not null;index"`
+	CorrectCount int          `gorm:"not null"`
+	Total        int          `gorm:"not null"`
+	Score        float64      `gorm:"not null"` // Share of correct answers, 0-1
+	Quiz         Quiz         `gorm:"foreignKey:QuizID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Answers      []QuizAnswer `gorm:"foreignKey:AttemptID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
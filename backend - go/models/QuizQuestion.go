@@ -0,0 +1,18 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type QuizQuestion struct {
+	gorm.Model
+	QuizID        uint     `gorm:"not null;index"`
+	Position      int      `gorm:"not null"`
+	Type          string   `gorm:"not null"` // "multiple_choice" or "short_answer"
+	Question      string   `gorm:"type:text;not null"`
+	Options       []string `gorm:"type:jsonb;serializer:json"` // Choices for multiple-choice questions
+	CorrectOption *int     // 0-based index into Options for multiple-choice questions
+	Answer        string   `gorm:"type:text;not null"` // Correct answer text, the reference for short answers
+	Explanation   string   `gorm:"type:text"`
+	SourcePage    *int     // 1-based PDF page the answer comes from
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// StudyItem is a reviewable key point taken from a summary
+type StudyItem struct {
+	gorm.Model
+	WorkspaceID string    `gorm:"not null;uniqueIndex:idx_study_items_point"`
+	SummaryID   uint      `gorm:"not null;uniqueIndex:idx_study_items_point"`
+	Position    int       `gorm:"not null;uniqueIndex:idx_study_items_point"` // Order of the key point within the summary
+	PDFID       uint      `gorm:"not null;index"`
+	Content     string    `gorm:"type:text;not null"`
+	PDF         PDF       `gorm:"foreignKey:PDFID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Summary     Summaries `gorm:"foreignKey:SummaryID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StudyReview records a single review of a study item by a user
+type StudyReview struct {
+	gorm.Model
+	StudyItemID  uint      `gorm:"not null;index"`
+	UserID       string    `gorm:"not null;index"`
+	WorkspaceID  string    `gorm:"not null;index"`
+	Grade        int       `gorm:"not null"` // 0-5, 3 or more is a pass
+	EaseFactor   float64   `gorm:"not null"` // Ease factor after the review
+	IntervalDays int       `gorm:"not null"` // Interval until the next review
+	DueAt        time.Time `gorm:"not null"` // When the item is due next
+	StudyItem    StudyItem `gorm:"foreignKey:StudyItemID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
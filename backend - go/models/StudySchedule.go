@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StudySchedule is one user's SM-2 scheduling state for a study item
+type StudySchedule struct {
+	gorm.Model
+	StudyItemID    uint      `gorm:"not null;uniqueIndex:idx_study_schedules_user_item"`
+	UserID         string    `gorm:"not null;uniqueIndex:idx_study_schedules_user_item;index:idx_study_schedules_due"`
+	EaseFactor     float64   `gorm:"not null;default:2.5"`
+	IntervalDays   int       `gorm:"not null;default:0"`
+	Repetitions    int       `gorm:"not null;default:0"`
+	DueAt          time.Time `gorm:"not null;index:idx_study_schedules_due"`
+	LastReviewedAt *time.Time
+	StudyItem      StudyItem `gorm:"foreignKey:StudyItemID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
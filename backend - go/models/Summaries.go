@@ -1,17 +1,28 @@
 package models
 
 import (
+	"time"
+
 	"github.com/pgvector/pgvector-go"
 	"gorm.io/gorm"
 )
 
 type Summaries struct {
 	gorm.Model
-	Style       string          `gorm:"not null"`
-	Content     string          `gorm:"not null"`
-	PDFID       uint            `gorm:"not null;index"`
-	Language    string          `gorm:"not null"`
-	SummaryTime float64         `gorm:"not null"`
-	Embedding   pgvector.Vector `gorm:"type:vector(1024)"`
-	PDF         PDF             `gorm:"foreignKey:PDFID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Style           string          `gorm:"not null"`
+	Content         string          `gorm:"not null"`
+	PDFID           *uint           `gorm:"index"` // Nil for summaries synthesized from several PDFs, see Sources
+	Language        string          `gorm:"not null"`
+	SummaryTime     float64         `gorm:"not null"`
+	Embedding       pgvector.Vector `gorm:"type:vector(1024)"`
+	SourceSummaryID *uint           `gorm:"index"`     // Summary this one was translated from
+	OriginalContent string          `gorm:"type:text"` // AI generated text, kept when the summary is edited
+	ModelName       string          // AI model that generated the summary
+	PromptVersion   string          // Prompt template version used for generation
+	EditedBy        string          // Who last edited the summary
+	EditedAt        *time.Time      // When the summary was last edited
+	Flagged         bool            `gorm:"not null;default:false;index"` // Feedback reported an issue
+	Focus           string          `gorm:"type:text"`                    // Question a synthesized summary answers
+	PDF             PDF             `gorm:"foreignKey:PDFID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Sources         []SummarySource `gorm:"foreignKey:SummaryID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"` // Source PDFs of a synthesized summary
 }
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type SummaryFeedback struct {
+	gorm.Model
+	SummaryID uint      `gorm:"not null;index"`
+	Vote      int       `gorm:"not null;default:0"` // 1 thumbs up, -1 thumbs down, 0 no vote
+	Rating    *int      // 1-5 star rating
+	Comment   string    `gorm:"type:text"`
+	Issues    string    // Comma-separated flagged issues, e.g. "hallucination,incomplete"
+	Summary   Summaries `gorm:"foreignKey:SummaryID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
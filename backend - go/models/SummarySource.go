@@ -0,0 +1,16 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// SummarySource links a synthesized summary to one of the PDFs it was built from
+type SummarySource struct {
+	gorm.Model
+	SummaryID        uint      `gorm:"not null;uniqueIndex:idx_summary_sources_pdf"`
+	PDFID            uint      `gorm:"not null;uniqueIndex:idx_summary_sources_pdf;index"`
+	Position         int       `gorm:"not null"` // Order of the PDF in the synthesis request
+	ContextSummaryID *uint     // Summary of the PDF used as context; nil when extracted text was used
+	Summary          Summaries `gorm:"foreignKey:SummaryID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PDF              PDF       `gorm:"foreignKey:PDFID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// Topic is a cluster of similar PDFs found by the topic clustering job
+type Topic struct {
+	gorm.Model
+	Label         string        `gorm:"not null"`
+	Description   string        `gorm:"type:text"`
+	Size          int           `gorm:"not null"` // Number of member PDFs
+	ModelName     string        // AI model that labeled the cluster
+	PromptVersion string        // Prompt template version used for labeling
+	Members       []TopicMember `gorm:"foreignKey:TopicID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
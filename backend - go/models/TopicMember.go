@@ -0,0 +1,15 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// TopicMember assigns a PDF to a topic
+type TopicMember struct {
+	gorm.Model
+	TopicID uint    `gorm:"not null;uniqueIndex:idx_topic_members_pdf"`
+	PDFID   uint    `gorm:"not null;uniqueIndex:idx_topic_members_pdf;index"`
+	Score   float64 `gorm:"not null"` // Cosine similarity of the PDF to the topic centroid
+	Topic   Topic   `gorm:"foreignKey:TopicID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PDF     PDF     `gorm:"foreignKey:PDFID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
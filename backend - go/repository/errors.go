@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotFound is returned when a requested record does not exist
+var ErrNotFound = errors.New("record not found")
+
+// notFound maps GORM's missing-record error onto ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
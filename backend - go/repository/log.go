@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"backend-go/dto"
+	"backend-go/models"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// LogFilter narrows a request log listing
+type LogFilter struct {
+	Method      string
+	Path        string // Substring of the request path
+	StatusCode  int    // 0 means any
+	MinDuration int    // Milliseconds; 0 means any
+}
+
+// LogRepository reads the request log
+type LogRepository interface {
+	List(ctx context.Context, filter LogFilter, sort Sort, page Page) ([]models.Log, int64, error)
+	Stats(ctx context.Context) (*dto.LogStatsResponse, error)
+}
+
+type logRepository struct {
+	db *gorm.DB
+}
+
+// NewLogRepository returns a LogRepository backed by db
+func NewLogRepository(db *gorm.DB) LogRepository {
+	return &logRepository{db: db}
+}
+
+func (r *logRepository) List(ctx context.Context, filter LogFilter, sort Sort, page Page) ([]models.Log, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Log{})
+	if filter.Method != "" {
+		query = query.Where("method = ?", filter.Method)
+	}
+	if filter.Path != "" {
+		query = query.Where("path ILIKE ?", "%"+filter.Path+"%")
+	}
+	if filter.StatusCode != 0 {
+		query = query.Where("status_code = ?", filter.StatusCode)
+	}
+	if filter.MinDuration > 0 {
+		query = query.Where("duration >= ?", filter.MinDuration)
+	}
+
+	var logs []models.Log
+	total, err := List(query, sort, page, &logs)
+	return logs, total, err
+}
+
+func (r *logRepository) Stats(ctx context.Context) (*dto.LogStatsResponse, error) {
+	db := r.db.WithContext(ctx)
+	stats := &dto.LogStatsResponse{
+		ByStatusCode:     make(map[string]int64),
+		ByMethod:         make(map[string]int64),
+		SlowestEndpoints: []dto.EndpointDuration{},
+	}
+
+	if err := db.Model(&models.Log{}).Count(&stats.TotalRequests).Error; err != nil {
+		return nil, err
+	}
+
+	var avgDuration sql.NullFloat64
+	if err := db.Model(&models.Log{}).Select("AVG(duration)").Scan(&avgDuration).Error; err != nil {
+		return nil, err
+	}
+	if avgDuration.Valid {
+		stats.AvgDuration = avgDuration.Float64
+	}
+
+	var statusCounts []struct {
+		StatusCode int
+		Count      int64
+	}
+	if err := db.Model(&models.Log{}).Select("status_code, COUNT(*) as count").Group("status_code").Find(&statusCounts).Error; err != nil {
+		return nil, err
+	}
+	for _, sc := range statusCounts {
+		stats.ByStatusCode[fmt.Sprintf("%d", sc.StatusCode)] = sc.Count
+	}
+
+	var methodCounts []struct {
+		Method string
+		Count  int64
+	}
+	if err := db.Model(&models.Log{}).Select("method, COUNT(*) as count").Group("method").Find(&methodCounts).Error; err != nil {
+		return nil, err
+	}
+	for _, mc := range methodCounts {
+		stats.ByMethod[mc.Method] = mc.Count
+	}
+
+	if err := db.Model(&models.Log{}).
+		Select("path, AVG(duration) as avg_duration").
+		Group("path").
+		Order("avg_duration DESC").
+		Limit(10).
+		Find(&stats.SlowestEndpoints).Error; err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
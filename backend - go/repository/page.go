@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// Page is a validated page number and size
+type Page struct {
+	Number int
+	Size   int
+}
+
+// ParsePage validates a 1-based page number and page size, using defaultSize
+// when the size is outside 1..maxSize
+func ParsePage(number, size, defaultSize, maxSize int) Page {
+	if number < 1 {
+		number = 1
+	}
+	if size < 1 || size > maxSize {
+		size = defaultSize
+	}
+	return Page{Number: number, Size: size}
+}
+
+// Offset returns the number of rows before the page
+func (p Page) Offset() int {
+	return (p.Number - 1) * p.Size
+}
+
+// TotalPages returns how many pages total rows fill
+func (p Page) TotalPages(total int64) int {
+	return int((total + int64(p.Size) - 1) / int64(p.Size))
+}
+
+// List counts the rows matched by query, then loads one sorted page of them into dest
+// with the given associations preloaded
+func List[T any](query *gorm.DB, sort Sort, page Page, dest *[]T, preloads ...string) (int64, error) {
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	find := query.Session(&gorm.Session{})
+	for _, preload := range preloads {
+		find = find.Preload(preload)
+	}
+	err := sort.Apply(find).Limit(page.Size).Offset(page.Offset()).Find(dest).Error
+	return total, err
+}
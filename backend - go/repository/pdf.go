@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"backend-go/dto"
+	"backend-go/models"
+	"backend-go/utils"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// PDFFilter narrows a PDF listing
+type PDFFilter struct {
+	Search  string // Matched against title and filename
+	TopicID uint   // Only members of this topic; 0 means all
+}
+
+// PDFRepository stores PDF records
+type PDFRepository interface {
+	List(ctx context.Context, filter PDFFilter, sort Sort, page Page) ([]models.PDF, int64, error)
+	Count(ctx context.Context) (int64, error)
+	Get(ctx context.Context, id uint) (*models.PDF, error)
+	GetWithSummaries(ctx context.Context, id uint) (*models.PDF, error)
+	FindMany(ctx context.Context, ids []uint) ([]models.PDF, error)
+	Create(ctx context.Context, pdf *models.PDF) error
+	Delete(ctx context.Context, pdf *models.PDF) error
+	Similar(ctx context.Context, id uint, limit int) ([]dto.SimilarPDFResponse, error)
+	SetPinnedSummary(ctx context.Context, id uint, summaryID *uint) error
+}
+
+type pdfRepository struct {
+	db *gorm.DB
+}
+
+// NewPDFRepository returns a PDFRepository backed by db
+func NewPDFRepository(db *gorm.DB) PDFRepository {
+	return &pdfRepository{db: db}
+}
+
+func (r *pdfRepository) List(ctx context.Context, filter PDFFilter, sort Sort, page Page) ([]models.PDF, int64, error) {
+	db := r.db.WithContext(ctx)
+	query := db.Model(&models.PDF{})
+	if filter.Search != "" {
+		query = query.Where("title ILIKE ? OR filename ILIKE ?", "%"+filter.Search+"%", "%"+filter.Search+"%")
+	}
+	if filter.TopicID != 0 {
+		query = query.Where("id IN (?)", db.Model(&models.TopicMember{}).Select("pdf_id").Where("topic_id = ?", filter.TopicID))
+	}
+
+	var pdfs []models.PDF
+	total, err := List(query, sort, page, &pdfs, "Summaries")
+	return pdfs, total, err
+}
+
+func (r *pdfRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.PDF{}).Count(&count).Error
+	return count, err
+}
+
+func (r *pdfRepository) Get(ctx context.Context, id uint) (*models.PDF, error) {
+	var pdf models.PDF
+	if err := r.db.WithContext(ctx).First(&pdf, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &pdf, nil
+}
+
+func (r *pdfRepository) GetWithSummaries(ctx context.Context, id uint) (*models.PDF, error) {
+	var pdf models.PDF
+	if err := r.db.WithContext(ctx).Preload("Summaries").First(&pdf, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &pdf, nil
+}
+
+// FindMany returns the PDFs with the given IDs; unknown IDs are skipped
+func (r *pdfRepository) FindMany(ctx context.Context, ids []uint) ([]models.PDF, error) {
+	var pdfs []models.PDF
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&pdfs).Error
+	return pdfs, err
+}
+
+func (r *pdfRepository) Create(ctx context.Context, pdf *models.PDF) error {
+	return r.db.WithContext(ctx).Create(pdf).Error
+}
+
+// Delete removes the record permanently; summaries go with it through the cascade
+func (r *pdfRepository) Delete(ctx context.Context, pdf *models.PDF) error {
+	return r.db.WithContext(ctx).Unscoped().Delete(pdf).Error
+}
+
+func (r *pdfRepository) Similar(ctx context.Context, id uint, limit int) ([]dto.SimilarPDFResponse, error) {
+	return utils.SimilarPDFs(r.db.WithContext(ctx), id, limit)
+}
+
+// SetPinnedSummary pins a summary as the PDF's primary summary, or unpins it with nil so
+// the latest summary becomes primary again, and updates the PDF's summary fields to match
+func (r *pdfRepository) SetPinnedSummary(ctx context.Context, id uint, summaryID *uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.PDF{}).Where("id = ?", id).Update("pinned_summary_id", summaryID).Error; err != nil {
+			return err
+		}
+		return RefreshPDFSummaries(tx, []uint{id})
+	})
+}
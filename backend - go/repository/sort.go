@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SortColumn is a column list endpoints can sort by. Only the constants below are
+// valid; user input is matched against them and never reaches SQL directly.
+type SortColumn string
+
+const (
+	SortCreatedAt   SortColumn = "created_at"
+	SortUpdatedAt   SortColumn = "updated_at"
+	SortTitle       SortColumn = "title"
+	SortFileSize    SortColumn = "file_size"
+	SortPageCount   SortColumn = "page_count"
+	SortStyle       SortColumn = "style"
+	SortLanguage    SortColumn = "language"
+	SortSummaryTime SortColumn = "summary_time"
+	SortDuration    SortColumn = "duration"
+	SortStatusCode  SortColumn = "status_code"
+)
+
+// Sortable columns per list endpoint
+var (
+	PDFSortColumns     = []SortColumn{SortCreatedAt, SortUpdatedAt, SortTitle, SortFileSize, SortPageCount}
+	SummarySortColumns = []SortColumn{SortCreatedAt, SortUpdatedAt, SortStyle, SortLanguage, SortSummaryTime}
+	LogSortColumns     = []SortColumn{SortCreatedAt, SortDuration, SortStatusCode}
+)
+
+// Sort is a validated ORDER BY column and direction
+type Sort struct {
+	Column SortColumn
+	Desc   bool
+}
+
+// ParseSort matches a requested column and order ("asc" or "desc") against allowed,
+// falling back to fallback and descending order for anything else
+func ParseSort(column, order string, allowed []SortColumn, fallback SortColumn) Sort {
+	sort := Sort{Column: fallback, Desc: strings.ToLower(order) != "asc"}
+	for _, candidate := range allowed {
+		if string(candidate) == column {
+			sort.Column = candidate
+			break
+		}
+	}
+	return sort
+}
+
+// Apply adds the ORDER BY clause to query, quoting the column as an identifier
+func (s Sort) Apply(query *gorm.DB) *gorm.DB {
+	return query.Order(clause.OrderByColumn{
+		Column: clause.Column{Name: string(s.Column)},
+		Desc:   s.Desc,
+	})
+}
@@ -0,0 +1,64 @@
+package repository
+
+import "testing"
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		name   string
+		column string
+		order  string
+		want   Sort
+	}{
+		{name: "allowed column ascending", column: "title", order: "asc", want: Sort{Column: SortTitle}},
+		{name: "allowed column descending", column: "file_size", order: "desc", want: Sort{Column: SortFileSize, Desc: true}},
+		{name: "order is case-insensitive", column: "title", order: "ASC", want: Sort{Column: SortTitle}},
+		{name: "defaults to descending", column: "title", want: Sort{Column: SortTitle, Desc: true}},
+		{name: "column of another endpoint", column: "duration", order: "asc", want: Sort{Column: SortCreatedAt}},
+		{name: "injection attempt", column: "title; DROP TABLE pdfs", order: "asc", want: Sort{Column: SortCreatedAt}},
+		{name: "invalid order", column: "title", order: "sideways", want: Sort{Column: SortTitle, Desc: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseSort(tt.column, tt.order, PDFSortColumns, SortCreatedAt); got != tt.want {
+				t.Errorf("ParseSort(%q, %q) = %+v, want %+v", tt.column, tt.order, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePage(t *testing.T) {
+	tests := []struct {
+		name       string
+		number     int
+		size       int
+		want       Page
+		wantOffset int
+	}{
+		{name: "valid page", number: 3, size: 20, want: Page{Number: 3, Size: 20}, wantOffset: 40},
+		{name: "page below one", number: 0, size: 20, want: Page{Number: 1, Size: 20}, wantOffset: 0},
+		{name: "size below one", number: 2, size: 0, want: Page{Number: 2, Size: 10}, wantOffset: 10},
+		{name: "size above maximum", number: 1, size: 101, want: Page{Number: 1, Size: 10}, wantOffset: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParsePage(tt.number, tt.size, 10, 100)
+			if got != tt.want {
+				t.Errorf("ParsePage(%d, %d) = %+v, want %+v", tt.number, tt.size, got, tt.want)
+			}
+			if got.Offset() != tt.wantOffset {
+				t.Errorf("Offset() = %d, want %d", got.Offset(), tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestTotalPages(t *testing.T) {
+	page := Page{Number: 1, Size: 10}
+	for total, want := range map[int64]int{0: 0, 1: 1, 10: 1, 11: 2, 95: 10} {
+		if got := page.TotalPages(total); got != want {
+			t.Errorf("TotalPages(%d) = %d, want %d", total, got, want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"backend-go/utils"
+	"context"
+	"io"
+)
+
+// Storage holds uploaded PDF files
+type Storage interface {
+	// Open returns the file and its size; the caller must close the reader
+	Open(ctx context.Context, filename string) (io.ReadCloser, int64, error)
+	Save(ctx context.Context, filename string, reader io.Reader, size int64) error
+	Delete(ctx context.Context, filename string) error
+}
+
+type objectStorage struct{}
+
+// NewObjectStorage returns a Storage using MinIO when available and the uploads directory otherwise
+func NewObjectStorage() Storage {
+	return objectStorage{}
+}
+
+func (objectStorage) Open(ctx context.Context, filename string) (io.ReadCloser, int64, error) {
+	return utils.OpenStoredFile(ctx, filename)
+}
+
+func (objectStorage) Save(ctx context.Context, filename string, reader io.Reader, size int64) error {
+	return utils.SaveStoredFile(ctx, filename, reader, size)
+}
+
+func (objectStorage) Delete(ctx context.Context, filename string) error {
+	return utils.DeleteStoredFile(ctx, filename)
+}
@@ -0,0 +1,339 @@
+package repository
+
+import (
+	"backend-go/dto"
+	"backend-go/models"
+	"backend-go/utils"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/pgvector/pgvector-go"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SummaryListFilter narrows a summary listing
+type SummaryListFilter struct {
+	Search       string   // Matched against content and style
+	PDFID        uint     // Only summaries of this PDF; 0 means all
+	Style        string   // Substring of the style
+	Languages    []string // Lower-case language values to match exactly
+	LanguageLike string   // Substring of the language, used when Languages is empty
+	Synthesized  *bool    // Only cross-document (true) or single-PDF (false) summaries
+}
+
+// SummaryExportFilter selects the summaries of a PDF to export
+type SummaryExportFilter struct {
+	PDFID    uint
+	IDs      []uint // Only these summaries; empty means all
+	Style    string // Exact style; empty means all
+	Language string // Exact language; empty means all
+}
+
+// SummaryRepository stores summaries
+type SummaryRepository interface {
+	List(ctx context.Context, filter SummaryListFilter, sort Sort, page Page) ([]models.Summaries, int64, error)
+	Count(ctx context.Context) (int64, error)
+	Get(ctx context.Context, id uint) (*models.Summaries, error)
+	FindMany(ctx context.Context, ids []uint) ([]models.Summaries, error)
+	ListForExport(ctx context.Context, filter SummaryExportFilter) ([]models.Summaries, error)
+	Create(ctx context.Context, summary *models.Summaries) error
+	Update(ctx context.Context, summary *models.Summaries) error
+	Delete(ctx context.Context, summary *models.Summaries) error
+	DeleteMany(ctx context.Context, ids []uint) (int64, error)
+	Nearest(ctx context.Context, v pgvector.Vector, filter SummaryFilter, limit int) ([]models.Summaries, error)
+	AddFeedback(ctx context.Context, feedback *models.SummaryFeedback, flag bool) error
+	Feedback(ctx context.Context, summaryID uint) ([]models.SummaryFeedback, error)
+	Stats(ctx context.Context) (*dto.SummaryStatsResponse, error)
+	SynthesisContexts(ctx context.Context, pdfs []models.PDF, focus string) ([]dto.PythonSynthesisSource, map[uint]*uint, error)
+}
+
+type summaryRepository struct {
+	db *gorm.DB
+}
+
+// NewSummaryRepository returns a SummaryRepository backed by db
+func NewSummaryRepository(db *gorm.DB) SummaryRepository {
+	return &summaryRepository{db: db}
+}
+
+func (r *summaryRepository) List(ctx context.Context, filter SummaryListFilter, sort Sort, page Page) ([]models.Summaries, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Summaries{})
+	if filter.Search != "" {
+		query = query.Where("content ILIKE ? OR style ILIKE ?", "%"+filter.Search+"%", "%"+filter.Search+"%")
+	}
+	if filter.PDFID != 0 {
+		query = query.Where("pdf_id = ?", filter.PDFID)
+	}
+	if filter.Synthesized != nil {
+		if *filter.Synthesized {
+			query = query.Where("pdf_id IS NULL")
+		} else {
+			query = query.Where("pdf_id IS NOT NULL")
+		}
+	}
+	if filter.Style != "" {
+		query = query.Where("style ILIKE ?", "%"+filter.Style+"%")
+	}
+	if len(filter.Languages) > 0 {
+		query = query.Where("LOWER(language) IN ?", filter.Languages)
+	} else if filter.LanguageLike != "" {
+		query = query.Where("language ILIKE ?", "%"+filter.LanguageLike+"%")
+	}
+
+	var summaries []models.Summaries
+	total, err := List(query, sort, page, &summaries, "PDF", "Sources.PDF")
+	return summaries, total, err
+}
+
+func (r *summaryRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Summaries{}).Count(&count).Error
+	return count, err
+}
+
+func (r *summaryRepository) Get(ctx context.Context, id uint) (*models.Summaries, error) {
+	var summary models.Summaries
+	if err := r.db.WithContext(ctx).Preload("PDF").Preload("Sources.PDF").First(&summary, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &summary, nil
+}
+
+// FindMany returns the summaries with the given IDs and their PDFs; unknown IDs are skipped
+func (r *summaryRepository) FindMany(ctx context.Context, ids []uint) ([]models.Summaries, error) {
+	var summaries []models.Summaries
+	err := r.db.WithContext(ctx).Preload("PDF").Where("id IN ?", ids).Find(&summaries).Error
+	return summaries, err
+}
+
+// ListForExport returns the matching summaries of a PDF, newest first
+func (r *summaryRepository) ListForExport(ctx context.Context, filter SummaryExportFilter) ([]models.Summaries, error) {
+	query := r.db.WithContext(ctx).Where("pdf_id = ?", filter.PDFID)
+	if len(filter.IDs) > 0 {
+		query = query.Where("id IN ?", filter.IDs)
+	}
+	if filter.Style != "" {
+		query = query.Where("style = ?", filter.Style)
+	}
+	if filter.Language != "" {
+		query = query.Where("language = ?", filter.Language)
+	}
+
+	var summaries []models.Summaries
+	err := query.Order("created_at DESC").Find(&summaries).Error
+	return summaries, err
+}
+
+// Create saves a new summary with its sources
+func (r *summaryRepository) Create(ctx context.Context, summary *models.Summaries) error {
+	return omitEmptyEmbedding(r.db.WithContext(ctx), summary).Create(summary).Error
+}
+
+// Update saves an edited summary and keeps its PDF's summary fields in sync
+func (r *summaryRepository) Update(ctx context.Context, summary *models.Summaries) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := omitEmptyEmbedding(tx, summary).Omit(clause.Associations).Save(summary).Error; err != nil {
+			return err
+		}
+		if summary.PDFID == nil {
+			return nil
+		}
+		return RefreshPDFSummaries(tx, []uint{*summary.PDFID})
+	})
+}
+
+// omitEmptyEmbedding leaves a summary without embedding out of the vector column,
+// which does not accept empty vectors, so a stored embedding is kept or it stays NULL
+func omitEmptyEmbedding(tx *gorm.DB, summary *models.Summaries) *gorm.DB {
+	if len(summary.Embedding.Slice()) == 0 {
+		return tx.Omit("Embedding")
+	}
+	return tx
+}
+
+// Delete removes the summary permanently
+func (r *summaryRepository) Delete(ctx context.Context, summary *models.Summaries) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(summary).Error; err != nil {
+			return err
+		}
+		if summary.PDFID == nil {
+			return nil
+		}
+		return RefreshPDFSummaries(tx, []uint{*summary.PDFID})
+	})
+}
+
+// DeleteMany soft-deletes the summaries with the given IDs and returns how many were deleted
+func (r *summaryRepository) DeleteMany(ctx context.Context, ids []uint) (int64, error) {
+	var deleted int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var pdfIDs []uint
+		if err := tx.Model(&models.Summaries{}).Where("id IN ? AND pdf_id IS NOT NULL", ids).Distinct().Pluck("pdf_id", &pdfIDs).Error; err != nil {
+			return err
+		}
+
+		// Soft-deleted summaries must not stay pinned as a PDF's primary summary
+		if err := tx.Model(&models.PDF{}).Where("pinned_summary_id IN ?", ids).Update("pinned_summary_id", nil).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("id IN ?", ids).Delete(&models.Summaries{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+		return RefreshPDFSummaries(tx, pdfIDs)
+	})
+	return deleted, err
+}
+
+// RefreshPDFSummaries recomputes the denormalized summary fields of PDFs after summaries were
+// edited or removed: from the pinned summary, else the latest remaining one, else they are cleared
+func RefreshPDFSummaries(tx *gorm.DB, pdfIDs []uint) error {
+	for _, pdfID := range pdfIDs {
+		var pdf models.PDF
+		if err := tx.First(&pdf, pdfID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		var primary models.Summaries
+		err := gorm.ErrRecordNotFound
+		if pdf.PinnedSummaryID != nil {
+			err = tx.Where("id = ? AND pdf_id = ?", *pdf.PinnedSummaryID, pdf.ID).First(&primary).Error
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = tx.Where("pdf_id = ?", pdf.ID).Order("created_at DESC").First(&primary).Error
+		}
+
+		updates := map[string]interface{}{"summary": "", "style": "", "language": "", "summary_time": 0}
+		switch {
+		case err == nil:
+			updates = map[string]interface{}{
+				"summary":      primary.Content,
+				"style":        primary.Style,
+				"language":     primary.Language,
+				"summary_time": primary.SummaryTime,
+			}
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+		if err := tx.Model(&pdf).Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *summaryRepository) Nearest(ctx context.Context, v pgvector.Vector, filter SummaryFilter, limit int) ([]models.Summaries, error) {
+	return NearestSummaries(r.db.WithContext(ctx), v, filter, limit)
+}
+
+// AddFeedback saves feedback on a summary and, when flag is set, flags the summary
+// so it can be excluded from RAG
+func (r *summaryRepository) AddFeedback(ctx context.Context, feedback *models.SummaryFeedback, flag bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(feedback).Error; err != nil {
+			return err
+		}
+		if !flag {
+			return nil
+		}
+		return tx.Model(&models.Summaries{}).Where("id = ?", feedback.SummaryID).Update("flagged", true).Error
+	})
+}
+
+// Feedback returns the feedback on a summary, newest first
+func (r *summaryRepository) Feedback(ctx context.Context, summaryID uint) ([]models.SummaryFeedback, error) {
+	var feedback []models.SummaryFeedback
+	err := r.db.WithContext(ctx).Where("summary_id = ?", summaryID).Order("created_at DESC").Find(&feedback).Error
+	return feedback, err
+}
+
+// Stats counts summaries by style and language and aggregates their feedback
+func (r *summaryRepository) Stats(ctx context.Context) (*dto.SummaryStatsResponse, error) {
+	db := r.db.WithContext(ctx)
+	stats := &dto.SummaryStatsResponse{
+		ByStyle:            make(map[string]int64),
+		ByLanguage:         make(map[string]int64),
+		FeedbackByStyle:    make(map[string]dto.FeedbackStats),
+		FeedbackByLanguage: make(map[string]dto.FeedbackStats),
+	}
+
+	if err := db.Model(&models.Summaries{}).Count(&stats.TotalSummaries).Error; err != nil {
+		return nil, err
+	}
+
+	for column, target := range map[string]map[string]int64{
+		"style":    stats.ByStyle,
+		"language": stats.ByLanguage,
+	} {
+		var counts []struct {
+			GroupKey string
+			Count    int64
+		}
+		if err := db.Model(&models.Summaries{}).Select(column + " AS group_key, COUNT(*) AS count").Group(column).Find(&counts).Error; err != nil {
+			return nil, err
+		}
+		for _, count := range counts {
+			target[count.GroupKey] = count.Count
+		}
+	}
+
+	var avgTime sql.NullFloat64
+	if err := db.Model(&models.Summaries{}).Select("AVG(summary_time)").Scan(&avgTime).Error; err != nil {
+		return nil, err
+	}
+	stats.AvgSummaryTime = avgTime.Float64
+
+	if err := db.Model(&models.PDF{}).Count(&stats.TotalPDFs).Error; err != nil {
+		return nil, err
+	}
+
+	// Feedback aggregated per style and language
+	for column, target := range map[string]map[string]dto.FeedbackStats{
+		"summaries.style":    stats.FeedbackByStyle,
+		"summaries.language": stats.FeedbackByLanguage,
+	} {
+		var feedbackStats []struct {
+			GroupKey      string
+			FeedbackCount int64
+			AvgRating     sql.NullFloat64
+			ThumbsUp      int64
+			ThumbsDown    int64
+			FlaggedCount  int64
+		}
+		if err := db.Model(&models.SummaryFeedback{}).
+			Select(column + ` AS group_key,
+				COUNT(*) AS feedback_count,
+				AVG(summary_feedbacks.rating) AS avg_rating,
+				COUNT(*) FILTER (WHERE summary_feedbacks.vote > 0) AS thumbs_up,
+				COUNT(*) FILTER (WHERE summary_feedbacks.vote < 0) AS thumbs_down,
+				COUNT(DISTINCT summaries.id) FILTER (WHERE summaries.flagged) AS flagged_count`).
+			Joins("JOIN summaries ON summaries.id = summary_feedbacks.summary_id AND summaries.deleted_at IS NULL").
+			Group(column).
+			Scan(&feedbackStats).Error; err != nil {
+			return nil, err
+		}
+
+		for _, stat := range feedbackStats {
+			target[stat.GroupKey] = dto.FeedbackStats{
+				FeedbackCount: stat.FeedbackCount,
+				AvgRating:     stat.AvgRating.Float64,
+				ThumbsUp:      stat.ThumbsUp,
+				ThumbsDown:    stat.ThumbsDown,
+				FlaggedCount:  stat.FlaggedCount,
+			}
+		}
+	}
+
+	return stats, nil
+}
+
+func (r *summaryRepository) SynthesisContexts(ctx context.Context, pdfs []models.PDF, focus string) ([]dto.PythonSynthesisSource, map[uint]*uint, error) {
+	return utils.SynthesisContexts(ctx, r.db.WithContext(ctx), pdfs, focus)
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"backend-go/models"
+	"backend-go/utils"
+
+	"github.com/pgvector/pgvector-go"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VectorColumn is a pgvector column similarity queries can order by
+type VectorColumn string
+
+const SummaryEmbedding VectorColumn = "embedding"
+
+// ByCosineDistance orders rows by cosine distance between column and v, nearest first.
+// The vector is bound as a query parameter.
+func ByCosineDistance(column VectorColumn, v pgvector.Vector) clause.OrderBy {
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL:  "? <=> ?",
+		Vars: []interface{}{clause.Column{Name: string(column)}, v},
+	}}
+}
+
+// SummaryFilter restricts a nearest-summary search
+type SummaryFilter struct {
+	PDFIDs         []uint // Only summaries of these PDFs; empty means all
+	ExcludeFlagged bool   // Skip summaries flagged by feedback
+}
+
+// NearestSummaries returns up to limit summaries with embeddings closest to v,
+// nearest first, using the configured approximate search settings
+func NearestSummaries(db *gorm.DB, v pgvector.Vector, filter SummaryFilter, limit int) ([]models.Summaries, error) {
+	var summaries []models.Summaries
+	err := utils.WithVectorSearch(db, utils.VectorSearchOptions{}, func(tx *gorm.DB) error {
+		query := tx.Model(&models.Summaries{}).Where("embedding IS NOT NULL")
+		if len(filter.PDFIDs) > 0 {
+			query = query.Where("pdf_id IN ?", filter.PDFIDs)
+		}
+		if filter.ExcludeFlagged {
+			query = query.Where("flagged = ?", false)
+		}
+		return query.Order(ByCosineDistance(SummaryEmbedding, v)).Limit(limit).Find(&summaries).Error
+	})
+	return summaries, err
+}
@@ -0,0 +1,127 @@
+package schema
+
+import (
+	"backend-go/models"
+	"backend-go/utils"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Migrate brings the database schema up to date: the pgvector extension, every model's
+// table, constraints, the latest-summary trigger and the vector indexes. Only a failed
+// AutoMigrate is an error; the other steps print a warning and continue.
+func Migrate(db *gorm.DB) error {
+	// Enable pgvector extension
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS vector").Error; err != nil {
+		println("Warning: Could not create vector extension: " + err.Error())
+	} else {
+		println("pgvector extension enabled successfully!")
+	}
+
+	// Migrate models in correct order (parent first, then child)
+	if err := db.AutoMigrate(
+		&models.PDF{},
+		&models.Summaries{},
+		&models.Log{},
+		&models.SummaryFeedback{},
+		&models.Flashcard{},
+		&models.StudyItem{},
+		&models.StudySchedule{},
+		&models.StudyReview{},
+		&models.Quiz{},
+		&models.QuizQuestion{},
+		&models.QuizAttempt{},
+		&models.QuizAnswer{},
+		&models.GlossaryTerm{},
+		&models.DocumentOutline{},
+		&models.SummarySource{},
+		&models.Topic{},
+		&models.TopicMember{},
+		&models.EmbeddingCache{},
+	); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
+	// Ensure foreign key constraint is properly created
+	if err := db.Exec(`
+		ALTER TABLE summaries 
+		DROP CONSTRAINT IF EXISTS fk_pdfs_summaries;
+		
+		ALTER TABLE summaries 
+		DROP CONSTRAINT IF EXISTS fk_summaries_pdf;
+		
+		ALTER TABLE summaries 
+		ADD CONSTRAINT fk_summaries_pdf 
+		FOREIGN KEY (pdf_id) REFERENCES pdfs(id) 
+		ON UPDATE CASCADE ON DELETE CASCADE;
+	`).Error; err != nil {
+		println("Warning: Could not create/update foreign key constraint: " + err.Error())
+	} else {
+		println("Foreign key constraint created successfully!")
+	}
+
+	// Synthesized summaries have no single PDF; older schemas declared pdf_id NOT NULL
+	if err := db.Exec(`ALTER TABLE summaries ALTER COLUMN pdf_id DROP NOT NULL;`).Error; err != nil {
+		println("Warning: Could not make summaries.pdf_id nullable: " + err.Error())
+	}
+
+	// Pinned summaries are cleared when the summary is hard-deleted
+	if err := db.Exec(`
+		ALTER TABLE pdfs
+		DROP CONSTRAINT IF EXISTS fk_pdfs_pinned_summary;
+
+		ALTER TABLE pdfs
+		ADD CONSTRAINT fk_pdfs_pinned_summary
+		FOREIGN KEY (pinned_summary_id) REFERENCES summaries(id)
+		ON DELETE SET NULL;
+	`).Error; err != nil {
+		println("Warning: Could not create pinned summary constraint: " + err.Error())
+	}
+
+	// Read and execute the update_latest_summary function from SQL file
+	// A pinned summary keeps the PDF's denormalized fields; new inserts only bump the version
+	if err := db.Exec(`
+	CREATE OR REPLACE FUNCTION update_latest_summary()
+	RETURNS TRIGGER AS $$
+	BEGIN
+		UPDATE pdfs
+		SET
+			summary = CASE WHEN pinned_summary_id IS NULL THEN NEW.content ELSE summary END,
+			style = CASE WHEN pinned_summary_id IS NULL THEN NEW.style ELSE style END,
+			language = CASE WHEN pinned_summary_id IS NULL THEN NEW.language ELSE language END,
+			summary_time = CASE WHEN pinned_summary_id IS NULL THEN NEW.summary_time ELSE summary_time END,
+			summary_version = COALESCE(summary_version, 0) + 1,
+			updated_at = NOW()
+		WHERE id = NEW.pdf_id;
+
+		RETURN NEW;
+	END;
+	$$ LANGUAGE plpgsql;`).Error; err != nil {
+		println("Warning: Could not create update_latest_summary function: " + err.Error())
+	}
+
+	// Read and execute the trigger from SQL file (drop first if exists)
+	if err := db.Exec(`DROP TRIGGER IF EXISTS trg_update_latest_summary ON summaries;`).Error; err != nil {
+		println("Warning: Could not drop existing trigger: " + err.Error())
+	}
+
+	if err := db.Exec(`
+	CREATE TRIGGER trg_update_latest_summary
+	AFTER INSERT ON summaries
+	FOR EACH ROW
+	EXECUTE FUNCTION update_latest_summary();`).Error; err != nil {
+		println("Warning: Could not create trigger trg_update_latest_summary: " + err.Error())
+	}
+
+	// Approximate nearest-neighbour indexes so similarity ordering avoids sequential scans
+	indexed, err := utils.EnsureVectorIndexes(db)
+	if err != nil {
+		println("Warning: Could not create vector indexes: " + err.Error())
+	}
+	for _, column := range indexed {
+		println("Vector index (" + utils.VectorIndexType() + ") ready on " + column)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"backend-go/dto"
+	"backend-go/utils"
+	"context"
+	"io"
+)
+
+// AI is the Python AI service
+type AI interface {
+	Summarize(ctx context.Context, filename string, file io.Reader, style string, language utils.Language) (*dto.PythonSummaryResponse, error)
+	ExtractText(ctx context.Context, filename string, file io.Reader, maxChars int) (string, error)
+	Embed(ctx context.Context, text string) ([]float32, error)
+	Translate(ctx context.Context, request dto.PythonTranslateRequest) (*dto.PythonTranslateResponse, error)
+	Synthesize(ctx context.Context, request dto.PythonSynthesizeRequest) (*dto.PythonSynthesizeResponse, error)
+	// Chat returns the raw JSON reply, which is relayed to the client unchanged
+	Chat(ctx context.Context, request dto.PythonChatRequest) ([]byte, error)
+}
+
+type pythonAI struct{}
+
+// NewPythonAI returns an AI calling the Python backend at PYTHON_API_URL
+func NewPythonAI() AI {
+	return pythonAI{}
+}
+
+func (pythonAI) Summarize(ctx context.Context, filename string, file io.Reader, style string, language utils.Language) (*dto.PythonSummaryResponse, error) {
+	var response dto.PythonSummaryResponse
+	if err := utils.PostFileToPythonAPI(ctx, "/summarize", filename, file, map[string]string{
+		"style":                style,
+		"language":             language.Code,
+		"language_name":        language.Name,
+		"language_instruction": language.Instruction,
+	}, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (pythonAI) ExtractText(ctx context.Context, filename string, file io.Reader, maxChars int) (string, error) {
+	return utils.ExtractPDFText(ctx, filename, file, maxChars)
+}
+
+func (pythonAI) Embed(ctx context.Context, text string) ([]float32, error) {
+	return utils.GenerateEmbedding(ctx, text)
+}
+
+func (pythonAI) Translate(ctx context.Context, request dto.PythonTranslateRequest) (*dto.PythonTranslateResponse, error) {
+	var response dto.PythonTranslateResponse
+	if err := utils.PostToPythonAPI(ctx, "/translate", request, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (pythonAI) Synthesize(ctx context.Context, request dto.PythonSynthesizeRequest) (*dto.PythonSynthesizeResponse, error) {
+	var response dto.PythonSynthesizeResponse
+	if err := utils.PostToPythonAPI(ctx, "/synthesize", request, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (pythonAI) Chat(ctx context.Context, request dto.PythonChatRequest) ([]byte, error) {
+	var reply []byte
+	if err := utils.PostToPythonAPI(ctx, "/chat", request, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"backend-go/dto"
+	"backend-go/repository"
+	"backend-go/utils"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// ChatService answers chat messages, grounding them in the selected PDFs' summaries
+type ChatService struct {
+	summaries repository.SummaryRepository
+	ai        AI
+}
+
+// NewChatService returns a ChatService using the given summary repository and AI service
+func NewChatService(summaries repository.SummaryRepository, ai AI) *ChatService {
+	return &ChatService{summaries: summaries, ai: ai}
+}
+
+// Chat forwards a message to the AI service and returns its raw JSON reply; error replies are
+// returned as *utils.PythonAPIError so they can be relayed, and a paused endpoint as
+// *utils.CircuitOpenError. When PDFs are
+// selected, the summary closest to the message is sent as context; retrieval failures only
+// mean the message is answered without context.
+func (s *ChatService) Chat(ctx context.Context, request dto.ChatRequest) ([]byte, error) {
+	reply, err := s.ai.Chat(ctx, dto.PythonChatRequest{
+		Message: request.Message,
+		History: request.History,
+		Context: s.context(ctx, request),
+	})
+	if err != nil {
+		var apiErr *utils.PythonAPIError
+		var openErr *utils.CircuitOpenError
+		if errors.As(err, &apiErr) || errors.As(err, &openErr) {
+			return nil, err
+		}
+		if timeout := timeoutError(err); timeout != nil {
+			return nil, timeout
+		}
+		return nil, &Error{Status: 503, Code: "backend_error", Message: "Failed to connect to AI service", Err: err}
+	}
+	return reply, nil
+}
+
+// context returns the content of the summary most similar to the message, or "" when
+// no PDFs are selected or nothing relevant is found
+func (s *ChatService) context(ctx context.Context, request dto.ChatRequest) string {
+	if len(request.PDFIDs) == 0 {
+		return ""
+	}
+
+	// Rate limits have already been retried by the AI client, so a failure here is final
+	embedding, err := s.ai.Embed(ctx, request.Message)
+	if err != nil {
+		fmt.Printf("Warning: Answering without context, failed to embed chat message: %v\n", err)
+		return ""
+	}
+
+	matches, err := s.summaries.Nearest(ctx, pgvector.NewVector(embedding), repository.SummaryFilter{
+		PDFIDs:         request.PDFIDs,
+		ExcludeFlagged: request.ExcludeFlagged,
+	}, 1)
+	if err != nil {
+		fmt.Printf("Warning: Failed to find similar summary: %v\n", err)
+		return ""
+	}
+	if len(matches) == 0 {
+		fmt.Printf("Warning: No summaries found with embeddings for PDF IDs: %v\n", request.PDFIDs)
+		return ""
+	}
+
+	fmt.Printf("✓ Found relevant summary (ID: %d) for chat context\n", matches[0].ID)
+	return matches[0].Content
+}
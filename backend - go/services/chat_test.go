@@ -0,0 +1,142 @@
+package services
+
+import (
+	"backend-go/dto"
+	"backend-go/fakes"
+	"backend-go/models"
+	"backend-go/utils"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+func TestChatContext(t *testing.T) {
+	pdfID := uint(1)
+	otherPDFID := uint(2)
+	embedding := pgvector.NewVector([]float32{0.1, 0.2})
+
+	tests := []struct {
+		name        string
+		request     dto.ChatRequest
+		summaries   []models.Summaries
+		embedErr    error
+		wantContext string
+		wantEmbed   bool
+	}{
+		{
+			name:    "no PDFs selected",
+			request: dto.ChatRequest{Message: "hello"},
+			summaries: []models.Summaries{
+				{Content: "unused", PDFID: &pdfID, Embedding: embedding},
+			},
+		},
+		{
+			name:    "uses the nearest summary of the selected PDFs",
+			request: dto.ChatRequest{Message: "what is it about?", PDFIDs: []uint{1}},
+			summaries: []models.Summaries{
+				{Content: "other document", PDFID: &otherPDFID, Embedding: embedding},
+				{Content: "selected document", PDFID: &pdfID, Embedding: embedding},
+			},
+			wantContext: "selected document",
+			wantEmbed:   true,
+		},
+		{
+			name:    "skips flagged summaries on request",
+			request: dto.ChatRequest{Message: "what is it about?", PDFIDs: []uint{1}, ExcludeFlagged: true},
+			summaries: []models.Summaries{
+				{Content: "flagged", PDFID: &pdfID, Embedding: embedding, Flagged: true},
+				{Content: "reviewed", PDFID: &pdfID, Embedding: embedding},
+			},
+			wantContext: "reviewed",
+			wantEmbed:   true,
+		},
+		{
+			name:    "answers without context when nothing is embedded",
+			request: dto.ChatRequest{Message: "what is it about?", PDFIDs: []uint{1}},
+			summaries: []models.Summaries{
+				{Content: "not embedded", PDFID: &pdfID},
+			},
+			wantEmbed: true,
+		},
+		{
+			name:    "answers without context when embedding is rate limited",
+			request: dto.ChatRequest{Message: "what is it about?", PDFIDs: []uint{1}},
+			summaries: []models.Summaries{
+				{Content: "selected document", PDFID: &pdfID, Embedding: embedding},
+			},
+			embedErr:  &utils.PythonAPIError{StatusCode: 429, Body: "quota exceeded"},
+			wantEmbed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summaries := &fakes.SummaryRepository{Summaries: tt.summaries}
+			ai := &fakes.AI{Embedding: []float32{0.1, 0.2}, EmbedErr: tt.embedErr, Reply: []byte(`{"reply":"hi"}`)}
+			service := NewChatService(summaries, ai)
+
+			reply, err := service.Chat(context.Background(), tt.request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(reply) != `{"reply":"hi"}` {
+				t.Errorf("reply = %s, want the AI reply", reply)
+			}
+			if got := len(ai.Embedded) > 0; got != tt.wantEmbed {
+				t.Errorf("embedded message = %v, want %v", got, tt.wantEmbed)
+			}
+			if len(ai.ChatRequests) != 1 {
+				t.Fatalf("expected 1 chat request, got %d", len(ai.ChatRequests))
+			}
+			if ai.ChatRequests[0].Context != tt.wantContext {
+				t.Errorf("context = %q, want %q", ai.ChatRequests[0].Context, tt.wantContext)
+			}
+		})
+	}
+}
+
+func TestChatErrors(t *testing.T) {
+	apiErr := &utils.PythonAPIError{StatusCode: 500, Body: `{"detail":"model overloaded"}`}
+
+	tests := []struct {
+		name    string
+		chatErr error
+		check   func(t *testing.T, err error)
+	}{
+		{
+			name:    "relays Python errors",
+			chatErr: apiErr,
+			check: func(t *testing.T, err error) {
+				if !errors.Is(err, apiErr) {
+					t.Errorf("expected the Python error, got %v", err)
+				}
+			},
+		},
+		{
+			name:    "reports unreachable AI service",
+			chatErr: errors.New("connection refused"),
+			check: func(t *testing.T, err error) {
+				assertError(t, err, 503, "backend_error")
+			},
+		},
+		{
+			name:    "reports timeouts",
+			chatErr: fmt.Errorf("Post \"http://python/chat\": %w", context.DeadlineExceeded),
+			check: func(t *testing.T, err error) {
+				assertError(t, err, 504, "timeout")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewChatService(&fakes.SummaryRepository{}, &fakes.AI{ChatErr: tt.chatErr})
+
+			_, err := service.Chat(context.Background(), dto.ChatRequest{Message: "hello"})
+			tt.check(t, err)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"backend-go/utils"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Error is a failure handlers report to the client as-is
+type Error struct {
+	Status  int    // HTTP status code
+	Code    string // Machine readable error, e.g. "not_found"
+	Message string
+	Err     error // Underlying cause, reported as details when set
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func notFound(message string) *Error {
+	return &Error{Status: 404, Code: "not_found", Message: message}
+}
+
+func invalid(code, message string) *Error {
+	return &Error{Status: 400, Code: code, Message: message}
+}
+
+func databaseError(message string, err error) *Error {
+	return &Error{Status: 500, Code: "database_error", Message: message, Err: err}
+}
+
+func storageError(message string, err error) *Error {
+	return &Error{Status: 500, Code: "storage_error", Message: message, Err: err}
+}
+
+// timeoutError reports a call that ran past its timeout, or nil when err is something else
+func timeoutError(err error) *Error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &Error{Status: 504, Code: "timeout", Message: "Python backend did not respond in time", Err: err}
+	}
+	return nil
+}
+
+// aiError passes Python backend responses and paused endpoints through unchanged, so
+// handlers can relay their status, reports timeouts as such and anything else as a
+// connection failure
+func aiError(err error) error {
+	var apiErr *utils.PythonAPIError
+	var openErr *utils.CircuitOpenError
+	if errors.As(err, &apiErr) || errors.As(err, &openErr) {
+		return err
+	}
+	if timeout := timeoutError(err); timeout != nil {
+		return timeout
+	}
+	return &Error{Status: 500, Code: "backend_error", Message: "Failed to connect to Python backend", Err: err}
+}
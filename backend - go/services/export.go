@@ -0,0 +1,37 @@
+package services
+
+import (
+	"backend-go/dto"
+	"backend-go/utils"
+	"strings"
+)
+
+// Export is a rendered export file
+type Export struct {
+	Content     []byte
+	ContentType string
+	Filename    string
+}
+
+// exportFormat looks up a format of utils.ExportFormats by its case-insensitive name
+func exportFormat(name string) (string, utils.ExportFormat, error) {
+	name = strings.ToLower(name)
+	format, ok := utils.ExportFormats[name]
+	if !ok {
+		return "", utils.ExportFormat{}, invalid("invalid_format", "Format must be one of md, html, docx or json")
+	}
+	return name, format, nil
+}
+
+// renderExport renders doc as a file named after title; failure is the message reported when rendering fails
+func renderExport(doc dto.ExportDocument, name string, format utils.ExportFormat, title, failure string) (*Export, error) {
+	content, err := utils.RenderExport(doc, name)
+	if err != nil {
+		return nil, &Error{Status: 500, Code: "export_error", Message: failure, Err: err}
+	}
+	return &Export{
+		Content:     content,
+		ContentType: format.ContentType,
+		Filename:    utils.ExportFilename(title, format.Extension),
+	}, nil
+}
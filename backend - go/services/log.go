@@ -0,0 +1,36 @@
+package services
+
+import (
+	"backend-go/dto"
+	"backend-go/models"
+	"backend-go/repository"
+	"context"
+)
+
+// LogService reads the request log
+type LogService struct {
+	logs repository.LogRepository
+}
+
+// NewLogService returns a LogService using the given repository
+func NewLogService(logs repository.LogRepository) *LogService {
+	return &LogService{logs: logs}
+}
+
+// List returns one page of logged requests and the total number of matches
+func (s *LogService) List(ctx context.Context, filter repository.LogFilter, sort repository.Sort, page repository.Page) ([]models.Log, int64, error) {
+	logs, total, err := s.logs.List(ctx, filter, sort, page)
+	if err != nil {
+		return nil, 0, databaseError("Failed to fetch logs", err)
+	}
+	return logs, total, nil
+}
+
+// Stats summarizes the logged requests
+func (s *LogService) Stats(ctx context.Context) (*dto.LogStatsResponse, error) {
+	stats, err := s.logs.Stats(ctx)
+	if err != nil {
+		return nil, databaseError("Failed to compute log statistics", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,336 @@
+package services
+
+import (
+	"backend-go/dto"
+	"backend-go/models"
+	"backend-go/repository"
+	"backend-go/utils"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/extemporalgenome/npdfpages"
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
+)
+
+// languageSampleChars is how much text of an upload is used to detect its language
+const languageSampleChars = 5000
+
+// PDFService manages uploaded PDFs and their summarization
+type PDFService struct {
+	pdfs      repository.PDFRepository
+	summaries repository.SummaryRepository
+	storage   repository.Storage
+	ai        AI
+
+	// CountPages returns the page count of the PDF at path, or 0 when it cannot be read
+	CountPages func(path string) int
+}
+
+// NewPDFService returns a PDFService using the given repositories, file storage and AI service
+func NewPDFService(pdfs repository.PDFRepository, summaries repository.SummaryRepository, storage repository.Storage, ai AI) *PDFService {
+	return &PDFService{
+		pdfs:       pdfs,
+		summaries:  summaries,
+		storage:    storage,
+		ai:         ai,
+		CountPages: npdfpages.PagesAtPath,
+	}
+}
+
+// List returns one page of PDFs with their summaries and the total number of matches
+func (s *PDFService) List(ctx context.Context, filter repository.PDFFilter, sort repository.Sort, page repository.Page) ([]models.PDF, int64, error) {
+	pdfs, total, err := s.pdfs.List(ctx, filter, sort, page)
+	if err != nil {
+		return nil, 0, databaseError("Failed to fetch PDFs", err)
+	}
+	return pdfs, total, nil
+}
+
+// Count returns the number of PDFs
+func (s *PDFService) Count(ctx context.Context) (int64, error) {
+	count, err := s.pdfs.Count(ctx)
+	if err != nil {
+		return 0, databaseError("Failed to count PDFs", err)
+	}
+	return count, nil
+}
+
+// Create stores a PDF record for a file that is already in storage
+func (s *PDFService) Create(ctx context.Context, request dto.PDFCreateRequest) (*models.PDF, error) {
+	pdf := models.PDF{
+		Filename:  request.Filename,
+		FileSize:  request.FileSize,
+		Title:     request.Title,
+		PageCount: request.PageCount,
+	}
+	if err := s.pdfs.Create(ctx, &pdf); err != nil {
+		return nil, databaseError("Failed to create PDF record", err)
+	}
+	return &pdf, nil
+}
+
+// Get returns a PDF without its summaries
+func (s *PDFService) Get(ctx context.Context, id uint) (*models.PDF, error) {
+	pdf, err := s.pdfs.Get(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("PDF not found")
+	}
+	if err != nil {
+		return nil, databaseError("Failed to find PDF", err)
+	}
+	return pdf, nil
+}
+
+// Detail returns a PDF with its summaries and the most similar other PDFs
+func (s *PDFService) Detail(ctx context.Context, id uint) (*models.PDF, []dto.SimilarPDFResponse, error) {
+	pdf, err := s.pdfs.GetWithSummaries(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil, notFound("PDF not found")
+	}
+	if err != nil {
+		return nil, nil, databaseError("Failed to find PDF", err)
+	}
+
+	// Related documents are a convenience; a ranking failure still returns the PDF
+	related, err := s.pdfs.Similar(ctx, pdf.ID, utils.DefaultRelatedLimit)
+	if err != nil {
+		fmt.Printf("Warning: Failed to find related PDFs for PDF %d: %v\n", pdf.ID, err)
+		related = []dto.SimilarPDFResponse{}
+	}
+	return pdf, related, nil
+}
+
+// Similar returns up to limit (1..50) other PDFs ranked by the similarity of their summaries
+func (s *PDFService) Similar(ctx context.Context, id uint, limit int) ([]dto.SimilarPDFResponse, error) {
+	pdf, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if limit < 1 || limit > 50 {
+		return nil, invalid("invalid_request", "limit must be between 1 and 50")
+	}
+
+	similar, err := s.pdfs.Similar(ctx, pdf.ID, limit)
+	if err != nil {
+		return nil, databaseError("Failed to find similar PDFs", err)
+	}
+	return similar, nil
+}
+
+// Summaries returns one page of a PDF's summaries and the total number of them
+func (s *PDFService) Summaries(ctx context.Context, id uint, sort repository.Sort, page repository.Page) ([]models.Summaries, int64, error) {
+	pdf, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	summaries, total, err := s.summaries.List(ctx, repository.SummaryListFilter{PDFID: pdf.ID}, sort, page)
+	if err != nil {
+		return nil, 0, databaseError("Failed to fetch summaries", err)
+	}
+	return summaries, total, nil
+}
+
+// Export renders the summaries of a PDF matching filter in format (md, html, docx or json),
+// the pinned summary first and the others newest first; filter.PDFID is set to id
+func (s *PDFService) Export(ctx context.Context, id uint, format string, filter repository.SummaryExportFilter) (*Export, error) {
+	name, fileFormat, err := exportFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.PDFID = pdf.ID
+	summaries, err := s.summaries.ListForExport(ctx, filter)
+	if err != nil {
+		return nil, databaseError("Failed to fetch summaries", err)
+	}
+	if pdf.PinnedSummaryID != nil {
+		sort.SliceStable(summaries, func(i, j int) bool {
+			return summaries[i].ID == *pdf.PinnedSummaryID && summaries[j].ID != *pdf.PinnedSummaryID
+		})
+	}
+
+	doc := dto.ExportDocument{
+		Title:      pdf.Title,
+		PDF:        utils.ConvertPDFToBasicInfo(*pdf),
+		Summaries:  utils.ConvertSummariesToResponse(summaries),
+		ExportedAt: time.Now(),
+	}
+	return renderExport(doc, name, fileFormat, pdf.Title, "Failed to export PDF summaries")
+}
+
+// Open returns a PDF and its stored file; the caller must close the reader
+func (s *PDFService) Open(ctx context.Context, id uint) (*models.PDF, io.ReadCloser, int64, error) {
+	pdf, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	file, size, err := s.storage.Open(ctx, pdf.Filename)
+	if err != nil {
+		return nil, nil, 0, &Error{Status: 404, Code: "file_not_found", Message: "PDF file not found in storage", Err: err}
+	}
+	return pdf, file, size, nil
+}
+
+// Delete removes a PDF, its summaries and its stored file
+func (s *PDFService) Delete(ctx context.Context, id uint) error {
+	pdf, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// A file left behind in storage does not stop the record from being deleted
+	if err := s.storage.Delete(ctx, pdf.Filename); err != nil {
+		fmt.Printf("Warning: Failed to delete stored file: %v\n", err)
+	}
+
+	if err := s.pdfs.Delete(ctx, pdf); err != nil {
+		return databaseError("Failed to delete PDF", err)
+	}
+	return nil
+}
+
+// Upload validates an uploaded file, stores it under a generated name and creates its record.
+// An empty title falls back to the file name without its extension. The document language is
+// detected from a text sample when the AI service is reachable.
+func (s *PDFService) Upload(ctx context.Context, filename string, size int64, title string, file io.Reader) (*models.PDF, error) {
+	if err := utils.ValidateFileExtension(filename); err != nil {
+		return nil, invalid("invalid_file", err.Error())
+	}
+	if err := utils.ValidateFileSize(size); err != nil {
+		return nil, invalid("invalid_file", err.Error())
+	}
+
+	ext := filepath.Ext(filename)
+	if title == "" {
+		title = strings.TrimSuffix(filename, ext)
+	}
+	if err := utils.ValidateTitle(title); err != nil {
+		return nil, invalid("invalid_title", err.Error())
+	}
+
+	storedName := uuid.New().String() + ext
+
+	// The page count needs a file on disk, so the upload is staged before it is stored
+	tempFile, err := os.CreateTemp("", "pdf-*.pdf")
+	if err != nil {
+		return nil, &Error{Status: 500, Code: "server_error", Message: "Failed to create temporary file", Err: err}
+	}
+	defer os.Remove(tempFile.Name())
+	defer tempFile.Close()
+
+	if _, err := io.Copy(tempFile, file); err != nil {
+		return nil, &Error{Status: 500, Code: "server_error", Message: "Failed to process file", Err: err}
+	}
+
+	pageCount := s.CountPages(tempFile.Name())
+	if pageCount <= 0 {
+		return nil, invalid("invalid_file", "Invalid PDF file or unable to read page count")
+	}
+
+	// Detect the document language from a text sample (non-fatal)
+	var detectedLanguage string
+	if _, err := tempFile.Seek(0, io.SeekStart); err == nil {
+		text, err := s.ai.ExtractText(ctx, storedName, tempFile, languageSampleChars)
+		if err != nil {
+			fmt.Printf("Warning: Failed to extract text for language detection: %v\n", err)
+		} else if lang, ok := utils.DetectLanguage(text); ok {
+			detectedLanguage = lang.Code
+			fmt.Printf("✓ Detected document language: %s\n", lang.Name)
+		}
+	}
+
+	if _, err := tempFile.Seek(0, io.SeekStart); err != nil {
+		return nil, &Error{Status: 500, Code: "server_error", Message: "Failed to process file", Err: err}
+	}
+	if err := s.storage.Save(ctx, storedName, tempFile, size); err != nil {
+		return nil, storageError("Failed to upload file to storage", err)
+	}
+
+	pdf := models.PDF{
+		Filename:         storedName,
+		FileSize:         size,
+		Title:            title,
+		PageCount:        pageCount,
+		DetectedLanguage: detectedLanguage,
+	}
+	if err := s.pdfs.Create(ctx, &pdf); err != nil {
+		// Clean up the stored file if the record cannot be saved, even when ctx was cancelled
+		if err := s.storage.Delete(context.WithoutCancel(ctx), storedName); err != nil {
+			fmt.Printf("Warning: Failed to delete stored file: %v\n", err)
+		}
+		return nil, databaseError("Failed to create PDF record", err)
+	}
+
+	return &pdf, nil
+}
+
+// Summarize asks the AI service for a summary of a PDF in the requested style and language
+// (falling back to the detected document language) and saves it with its embedding.
+// The AI response is returned even when saving the summary fails.
+func (s *PDFService) Summarize(ctx context.Context, id uint, request dto.SummarizeRequest) (*dto.PythonSummaryResponse, error) {
+	if err := utils.ValidateSummaryStyle(request.Style); err != nil {
+		return nil, invalid("invalid_style", err.Error())
+	}
+
+	pdf, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	language, err := utils.ResolveLanguage(request.Language, pdf.DetectedLanguage)
+	if err != nil {
+		return nil, invalid("invalid_language", err.Error())
+	}
+
+	file, _, err := s.storage.Open(ctx, pdf.Filename)
+	if err != nil {
+		return nil, storageError("Failed to retrieve PDF from storage", err)
+	}
+	defer file.Close()
+
+	response, err := s.ai.Summarize(ctx, pdf.Filename, file, request.Style, language)
+	if err != nil {
+		return nil, aiError(err)
+	}
+
+	summary := models.Summaries{
+		Style:         response.Style,
+		Content:       response.Summary.MainSummary,
+		PDFID:         &pdf.ID,
+		Language:      response.Language,
+		SummaryTime:   response.ProcessInfo.ProcessingTimeSeconds,
+		ModelName:     response.Model,
+		PromptVersion: response.PromptVersion,
+	}
+
+	// Only set embedding if it's not empty
+	if len(response.Embedding) > 0 {
+		summary.Embedding = pgvector.NewVector(response.Embedding)
+		fmt.Printf("✓ Embedding saved with %d dimensions\n", len(response.Embedding))
+	} else {
+		fmt.Println("Warning: No embedding generated for summary")
+	}
+
+	if err := s.summaries.Create(ctx, &summary); err != nil {
+		fmt.Printf("Failed to save summary: %v\n", err)
+	} else {
+		fmt.Printf("✓ Summary saved successfully (ID: %d)\n", summary.ID)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"backend-go/dto"
+	"backend-go/fakes"
+	"backend-go/models"
+	"backend-go/repository"
+	"backend-go/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newTestPDFService(pages int) (*PDFService, *fakes.PDFRepository, *fakes.SummaryRepository, *fakes.Storage, *fakes.AI) {
+	pdfs := &fakes.PDFRepository{}
+	summaries := &fakes.SummaryRepository{}
+	storage := fakes.NewStorage()
+	ai := &fakes.AI{}
+	service := NewPDFService(pdfs, summaries, storage, ai)
+	service.CountPages = func(string) int { return pages }
+	return service, pdfs, summaries, storage, ai
+}
+
+// assertError fails unless err is a service Error with the given status and code
+func assertError(t *testing.T, err error, status int, code string) {
+	t.Helper()
+	var serviceErr *Error
+	if !errors.As(err, &serviceErr) {
+		t.Fatalf("expected service error %d %s, got %v", status, code, err)
+	}
+	if serviceErr.Status != status || serviceErr.Code != code {
+		t.Fatalf("expected %d %s, got %d %s (%s)", status, code, serviceErr.Status, serviceErr.Code, serviceErr.Message)
+	}
+}
+
+func TestUpload(t *testing.T) {
+	tests := []struct {
+		name      string
+		filename  string
+		size      int64
+		title     string
+		pages     int
+		text      string
+		saveErr   error
+		createErr error
+		status    int
+		code      string
+		wantTitle string
+		wantLang  string
+	}{
+		{name: "stores file and record", filename: "report.pdf", size: 4, title: "Annual report", pages: 3, wantTitle: "Annual report"},
+		{name: "title defaults to file name", filename: "notes.pdf", size: 4, pages: 1, wantTitle: "notes"},
+		{name: "detects language", filename: "a.pdf", size: 4, pages: 1, text: "Das ist nicht der Text, und die Arbeit ist mit einer Idee von sich auf den Weg", wantTitle: "a", wantLang: "de"},
+		{name: "rejects other extensions", filename: "notes.txt", size: 4, status: 400, code: "invalid_file"},
+		{name: "rejects files without extension", filename: "notes", size: 4, status: 400, code: "invalid_file"},
+		{name: "rejects empty files", filename: "a.pdf", size: 0, status: 400, code: "invalid_file"},
+		{name: "rejects long titles", filename: "a.pdf", size: 4, title: strings.Repeat("x", 256), pages: 1, status: 400, code: "invalid_title"},
+		{name: "rejects unreadable PDFs", filename: "a.pdf", size: 4, pages: 0, status: 400, code: "invalid_file"},
+		{name: "reports storage failures", filename: "a.pdf", size: 4, pages: 1, saveErr: errors.New("bucket missing"), status: 500, code: "storage_error"},
+		{name: "reports database failures", filename: "a.pdf", size: 4, pages: 1, createErr: errors.New("connection lost"), status: 500, code: "database_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, pdfs, _, storage, ai := newTestPDFService(tt.pages)
+			storage.SaveErr = tt.saveErr
+			pdfs.Err = tt.createErr
+			ai.Text = tt.text
+
+			pdf, err := service.Upload(context.Background(), tt.filename, tt.size, tt.title, strings.NewReader("%PDF"))
+			if tt.code != "" {
+				assertError(t, err, tt.status, tt.code)
+				if len(storage.Files) != 0 {
+					t.Errorf("expected no stored files after a failed upload, got %d", len(storage.Files))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if pdf.Title != tt.wantTitle {
+				t.Errorf("title = %q, want %q", pdf.Title, tt.wantTitle)
+			}
+			if pdf.PageCount != tt.pages {
+				t.Errorf("page count = %d, want %d", pdf.PageCount, tt.pages)
+			}
+			if pdf.DetectedLanguage != tt.wantLang {
+				t.Errorf("detected language = %q, want %q", pdf.DetectedLanguage, tt.wantLang)
+			}
+			if !strings.HasSuffix(pdf.Filename, ".pdf") || pdf.Filename == tt.filename {
+				t.Errorf("expected a generated .pdf file name, got %q", pdf.Filename)
+			}
+			if string(storage.Files[pdf.Filename]) != "%PDF" {
+				t.Errorf("stored file = %q, want the uploaded content", storage.Files[pdf.Filename])
+			}
+			if len(pdfs.PDFs) != 1 {
+				t.Errorf("expected 1 PDF record, got %d", len(pdfs.PDFs))
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	response := &dto.PythonSummaryResponse{
+		Summary:   dto.SummaryDetails{MainSummary: "A short summary."},
+		Embedding: []float32{0.1, 0.2, 0.3},
+		Language:  "en",
+		Style:     "short",
+		Model:     "test-model",
+	}
+
+	tests := []struct {
+		name         string
+		id           uint
+		request      dto.SummarizeRequest
+		detected     string
+		summarizeErr error
+		status       int
+		code         string
+		wantLanguage string
+	}{
+		{name: "saves summary", id: 1, request: dto.SummarizeRequest{Style: "short", Language: "en"}, wantLanguage: "en"},
+		{name: "resolves language aliases", id: 1, request: dto.SummarizeRequest{Style: "detailed", Language: "Deutsch"}, wantLanguage: "de"},
+		{name: "falls back to detected language", id: 1, request: dto.SummarizeRequest{Style: "general", Language: "auto"}, detected: "ja", wantLanguage: "ja"},
+		{name: "rejects unknown styles", id: 1, request: dto.SummarizeRequest{Style: "poem", Language: "en"}, status: 400, code: "invalid_style"},
+		{name: "rejects unknown languages", id: 1, request: dto.SummarizeRequest{Style: "short", Language: "klingon"}, status: 400, code: "invalid_language"},
+		{name: "reports missing PDFs", id: 2, request: dto.SummarizeRequest{Style: "short", Language: "en"}, status: 404, code: "not_found"},
+		{name: "reports unreachable AI service", id: 1, request: dto.SummarizeRequest{Style: "short", Language: "en"}, summarizeErr: errors.New("connection refused"), status: 500, code: "backend_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, pdfs, summaries, storage, ai := newTestPDFService(1)
+			pdfs.PDFs = []models.PDF{{Filename: "stored.pdf", Title: "Doc", DetectedLanguage: tt.detected}}
+			pdfs.PDFs[0].ID = 1
+			storage.Files["stored.pdf"] = []byte("%PDF")
+			ai.Summary = response
+			ai.SummarizeErr = tt.summarizeErr
+
+			got, err := service.Summarize(context.Background(), tt.id, tt.request)
+			if tt.code != "" {
+				assertError(t, err, tt.status, tt.code)
+				if len(summaries.Summaries) != 0 {
+					t.Errorf("expected no saved summaries, got %d", len(summaries.Summaries))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != response {
+				t.Errorf("expected the AI response to be returned")
+			}
+			if len(ai.SummarizeCalls) != 1 {
+				t.Fatalf("expected 1 summarize call, got %d", len(ai.SummarizeCalls))
+			}
+			call := ai.SummarizeCalls[0]
+			if call.Language != tt.wantLanguage || call.Style != tt.request.Style || string(call.Content) != "%PDF" {
+				t.Errorf("summarize call = %+v, want language %q, style %q and the stored file", call, tt.wantLanguage, tt.request.Style)
+			}
+
+			if len(summaries.Summaries) != 1 {
+				t.Fatalf("expected 1 saved summary, got %d", len(summaries.Summaries))
+			}
+			saved := summaries.Summaries[0]
+			if saved.Content != "A short summary." || saved.PDFID == nil || *saved.PDFID != 1 || saved.ModelName != "test-model" {
+				t.Errorf("saved summary = %+v", saved)
+			}
+			if len(saved.Embedding.Slice()) != 3 {
+				t.Errorf("expected the embedding to be saved, got %v", saved.Embedding.Slice())
+			}
+		})
+	}
+}
+
+func TestSummarizeRelaysPythonErrors(t *testing.T) {
+	service, pdfs, _, storage, ai := newTestPDFService(1)
+	pdfs.PDFs = []models.PDF{{Filename: "stored.pdf"}}
+	pdfs.PDFs[0].ID = 1
+	storage.Files["stored.pdf"] = []byte("%PDF")
+	ai.SummarizeErr = &utils.PythonAPIError{StatusCode: 429, Body: "quota exceeded"}
+
+	_, err := service.Summarize(context.Background(), 1, dto.SummarizeRequest{Style: "short", Language: "en"})
+	var apiErr *utils.PythonAPIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 429 {
+		t.Fatalf("expected the Python error to be returned, got %v", err)
+	}
+}
+
+func TestDeletePDF(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     uint
+		status int
+		code   string
+	}{
+		{name: "removes record and file", id: 1},
+		{name: "reports missing PDFs", id: 7, status: 404, code: "not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, pdfs, _, storage, _ := newTestPDFService(1)
+			pdfs.PDFs = []models.PDF{{Filename: "stored.pdf"}}
+			pdfs.PDFs[0].ID = 1
+			storage.Files["stored.pdf"] = []byte("%PDF")
+
+			err := service.Delete(context.Background(), tt.id)
+			if tt.code != "" {
+				assertError(t, err, tt.status, tt.code)
+				if len(pdfs.PDFs) != 1 || len(storage.Files) != 1 {
+					t.Errorf("expected nothing to be deleted")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(pdfs.PDFs) != 0 || len(storage.Files) != 0 {
+				t.Errorf("expected record and file to be deleted, got %d records and %d files", len(pdfs.PDFs), len(storage.Files))
+			}
+		})
+	}
+}
+
+func TestSimilarLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		code  string
+	}{
+		{name: "accepts the minimum", limit: 1},
+		{name: "accepts the maximum", limit: 50},
+		{name: "rejects zero", limit: 0, code: "invalid_request"},
+		{name: "rejects large limits", limit: 51, code: "invalid_request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, pdfs, _, _, _ := newTestPDFService(1)
+			pdfs.PDFs = []models.PDF{{Title: "Doc"}}
+			pdfs.PDFs[0].ID = 1
+
+			_, err := service.Similar(context.Background(), 1, tt.limit)
+			if tt.code != "" {
+				assertError(t, err, 400, tt.code)
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestExportPutsPinnedSummaryFirst(t *testing.T) {
+	service, pdfs, summaries, _, _ := newTestPDFService(1)
+	pdfs.Create(context.Background(), &models.PDF{Title: "Doc"})
+	pdfID, pinned := uint(1), uint(2)
+	pdfs.PDFs[0].PinnedSummaryID = &pinned
+	for _, content := range []string{"newest", "pinned", "oldest"} {
+		summaries.Create(context.Background(), &models.Summaries{Content: content, PDFID: &pdfID})
+	}
+
+	export, err := service.Export(context.Background(), 1, "JSON", repository.SummaryExportFilter{IDs: []uint{1, 2}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summaries.LastExportQuery.PDFID != 1 {
+		t.Errorf("exported summaries of PDF %d, want 1", summaries.LastExportQuery.PDFID)
+	}
+	if export.ContentType != "application/json" || export.Filename != "Doc.json" {
+		t.Errorf("unexpected file: %s %s", export.ContentType, export.Filename)
+	}
+
+	var doc dto.ExportDocument
+	if err := json.Unmarshal(export.Content, &doc); err != nil {
+		t.Fatalf("invalid export: %v", err)
+	}
+	var contents []string
+	for _, summary := range doc.Summaries {
+		contents = append(contents, summary.Content)
+	}
+	if strings.Join(contents, ",") != "pinned,newest" {
+		t.Errorf("exported %v, want the pinned summary first", contents)
+	}
+}
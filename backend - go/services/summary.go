@@ -0,0 +1,481 @@
+package services
+
+import (
+	"backend-go/dto"
+	"backend-go/models"
+	"backend-go/repository"
+	"backend-go/utils"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// MaxBulkDelete limits how many summaries one bulk delete can remove
+const MaxBulkDelete = 100
+
+// SummaryQuery is the filter of a summary listing as requested by a client
+type SummaryQuery struct {
+	Search      string
+	PDFID       uint
+	Style       string
+	Language    string // Registered languages match by code, name and aliases; anything else by substring
+	Synthesized *bool
+}
+
+// SummaryService manages summaries, their translations, syntheses and feedback
+type SummaryService struct {
+	summaries repository.SummaryRepository
+	pdfs      repository.PDFRepository
+	ai        AI
+}
+
+// NewSummaryService returns a SummaryService using the given repositories and AI service
+func NewSummaryService(summaries repository.SummaryRepository, pdfs repository.PDFRepository, ai AI) *SummaryService {
+	return &SummaryService{summaries: summaries, pdfs: pdfs, ai: ai}
+}
+
+// List returns one page of summaries and the total number of matches
+func (s *SummaryService) List(ctx context.Context, query SummaryQuery, sort repository.Sort, page repository.Page) ([]models.Summaries, int64, error) {
+	filter := repository.SummaryListFilter{
+		Search:      query.Search,
+		PDFID:       query.PDFID,
+		Style:       query.Style,
+		Synthesized: query.Synthesized,
+	}
+	if query.Language != "" {
+		// Match registered languages by code and legacy aliases
+		if lang, ok := utils.LookupLanguage(query.Language); ok {
+			filter.Languages = append([]string{lang.Code, strings.ToLower(lang.Name)}, lang.Aliases...)
+		} else {
+			filter.LanguageLike = query.Language
+		}
+	}
+
+	summaries, total, err := s.summaries.List(ctx, filter, sort, page)
+	if err != nil {
+		return nil, 0, databaseError("Failed to fetch summaries", err)
+	}
+	return summaries, total, nil
+}
+
+// Count returns the number of summaries
+func (s *SummaryService) Count(ctx context.Context) (int64, error) {
+	count, err := s.summaries.Count(ctx)
+	if err != nil {
+		return 0, databaseError("Failed to count summaries", err)
+	}
+	return count, nil
+}
+
+// Get returns a summary with its PDF and, for synthesized summaries, its source PDFs
+func (s *SummaryService) Get(ctx context.Context, id uint) (*models.Summaries, error) {
+	summary, err := s.summaries.Get(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Summary not found")
+	}
+	if err != nil {
+		return nil, databaseError("Failed to find summary", err)
+	}
+	return summary, nil
+}
+
+// Delete removes a summary permanently
+func (s *SummaryService) Delete(ctx context.Context, id uint) error {
+	summary, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.summaries.Delete(ctx, summary); err != nil {
+		return databaseError("Failed to delete summary", err)
+	}
+	return nil
+}
+
+// DeleteMany soft-deletes up to MaxBulkDelete summaries and returns how many were deleted
+func (s *SummaryService) DeleteMany(ctx context.Context, ids []uint) (int64, error) {
+	if len(ids) == 0 {
+		return 0, invalid("invalid_request", "No IDs provided")
+	}
+	if len(ids) > MaxBulkDelete {
+		return 0, invalid("invalid_request", "Too many IDs provided (max 100)")
+	}
+
+	deleted, err := s.summaries.DeleteMany(ctx, ids)
+	if err != nil {
+		return 0, databaseError("Failed to delete summaries", err)
+	}
+	return deleted, nil
+}
+
+// Stats counts summaries by style and language and aggregates their feedback
+func (s *SummaryService) Stats(ctx context.Context) (*dto.SummaryStatsResponse, error) {
+	stats, err := s.summaries.Stats(ctx)
+	if err != nil {
+		return nil, databaseError("Failed to get summary statistics", err)
+	}
+	return stats, nil
+}
+
+// Compare diffs two summaries by sentence and word and, when both have embeddings,
+// reports their similarity
+func (s *SummaryService) Compare(ctx context.Context, idA, idB uint) (*dto.SummaryCompareResponse, error) {
+	if idA == 0 || idB == 0 {
+		return nil, invalid("invalid_request", "Query parameters a and b must be summary IDs")
+	}
+
+	summaries, err := s.summaries.FindMany(ctx, []uint{idA, idB})
+	if err != nil {
+		return nil, databaseError("Failed to fetch summaries", err)
+	}
+	byID := make(map[uint]models.Summaries, len(summaries))
+	for _, summary := range summaries {
+		byID[summary.ID] = summary
+	}
+	a, okA := byID[idA]
+	b, okB := byID[idB]
+	if !okA || !okB {
+		return nil, notFound("Summary not found")
+	}
+
+	response := dto.SummaryCompareResponse{
+		A: utils.ConvertSummaryToResponse(a),
+		B: utils.ConvertSummaryToResponse(b),
+	}
+	response.SentenceDiff, response.SentenceStats = utils.DiffSentences(a.Content, b.Content)
+	response.WordDiff, response.WordStats = utils.DiffWords(a.Content, b.Content)
+	if similarity, ok := utils.CosineSimilarity(a.Embedding, b.Embedding); ok {
+		response.Similarity = &similarity
+	}
+	return &response, nil
+}
+
+// Synthesize writes one summary across several PDFs, optionally answering a focus
+// question, and saves it with the PDFs as its sources in the requested order
+func (s *SummaryService) Synthesize(ctx context.Context, request dto.SynthesizeRequest) (*models.Summaries, error) {
+	focus := strings.TrimSpace(request.Focus)
+	pdfIDs, err := utils.ValidateSynthesisRequest(request.PDFIDs, focus)
+	if err != nil {
+		return nil, invalid("invalid_request", err.Error())
+	}
+
+	style := strings.ToLower(request.Style)
+	if style == "" {
+		style = "general"
+	}
+	if err := utils.ValidateSummaryStyle(style); err != nil {
+		return nil, invalid("invalid_style", err.Error())
+	}
+
+	found, err := s.pdfs.FindMany(ctx, pdfIDs)
+	if err != nil {
+		return nil, databaseError("Failed to find PDFs", err)
+	}
+	byID := make(map[uint]models.PDF, len(found))
+	for _, pdf := range found {
+		byID[pdf.ID] = pdf
+	}
+
+	// Keep the requested order, which is also the order of the sources
+	pdfs := make([]models.PDF, 0, len(pdfIDs))
+	var missing []uint
+	for _, id := range pdfIDs {
+		if pdf, ok := byID[id]; ok {
+			pdfs = append(pdfs, pdf)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &Error{Status: 404, Code: "not_found", Message: "PDF not found", Err: fmt.Errorf("missing PDF IDs: %v", missing)}
+	}
+
+	// Without an explicit language the first document's language is used
+	language, err := utils.ResolveLanguage(request.Language, pdfs[0].DetectedLanguage)
+	if err != nil {
+		return nil, invalid("invalid_language", err.Error())
+	}
+
+	sources, contextSummaries, err := s.summaries.SynthesisContexts(ctx, pdfs, focus)
+	if err != nil {
+		return nil, &Error{Status: 500, Code: "retrieval_error", Message: "Failed to collect source content", Err: err}
+	}
+
+	result, err := s.ai.Synthesize(ctx, dto.PythonSynthesizeRequest{
+		Sources:             sources,
+		Focus:               focus,
+		Style:               style,
+		Language:            language.Code,
+		LanguageName:        language.Name,
+		LanguageInstruction: language.Instruction,
+	})
+	if err != nil {
+		return nil, aiError(err)
+	}
+
+	summary := models.Summaries{
+		Style:         style,
+		Content:       result.Synthesis,
+		Language:      language.Code,
+		SummaryTime:   result.ProcessingTime,
+		ModelName:     result.Model,
+		PromptVersion: result.PromptVersion,
+		Focus:         focus,
+	}
+	for i, pdf := range pdfs {
+		summary.Sources = append(summary.Sources, models.SummarySource{
+			PDFID:            pdf.ID,
+			Position:         i,
+			ContextSummaryID: contextSummaries[pdf.ID],
+		})
+	}
+	if len(result.Embedding) > 0 {
+		summary.Embedding = pgvector.NewVector(result.Embedding)
+	} else {
+		fmt.Println("Warning: No embedding generated for synthesized summary")
+	}
+
+	if err := s.summaries.Create(ctx, &summary); err != nil {
+		return nil, databaseError("Failed to save synthesized summary", err)
+	}
+	fmt.Printf("✓ Synthesized summary of %d PDFs (ID: %d)\n", len(pdfs), summary.ID)
+
+	return s.reload(ctx, &summary), nil
+}
+
+// Translate saves a translation of a summary's text into language; a translated
+// synthesis keeps the source PDFs of the original
+func (s *SummaryService) Translate(ctx context.Context, id uint, language string) (*models.Summaries, error) {
+	if err := utils.ValidateLanguage(language); err != nil {
+		return nil, invalid("invalid_language", err.Error())
+	}
+	target, _ := utils.LookupLanguage(language)
+
+	source, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sourceLanguage, ok := utils.LookupLanguage(source.Language); ok && sourceLanguage.Code == target.Code {
+		return nil, invalid("invalid_language", "Summary is already in "+target.Name)
+	}
+
+	// Translate only the summary text instead of re-summarizing the whole PDF
+	translation, err := s.ai.Translate(ctx, dto.PythonTranslateRequest{
+		Text:                source.Content,
+		Language:            target.Code,
+		LanguageName:        target.Name,
+		LanguageInstruction: target.Instruction,
+	})
+	if err != nil {
+		return nil, aiError(err)
+	}
+
+	summary := models.Summaries{
+		Style:           source.Style,
+		Content:         translation.Translation,
+		PDFID:           source.PDFID,
+		Language:        target.Code,
+		SummaryTime:     translation.ProcessingTime,
+		SourceSummaryID: &source.ID,
+		ModelName:       translation.Model,
+		PromptVersion:   translation.PromptVersion,
+		Focus:           source.Focus,
+	}
+	for _, src := range source.Sources {
+		summary.Sources = append(summary.Sources, models.SummarySource{
+			PDFID:            src.PDFID,
+			Position:         src.Position,
+			ContextSummaryID: src.ContextSummaryID,
+		})
+	}
+	if len(translation.Embedding) > 0 {
+		summary.Embedding = pgvector.NewVector(translation.Embedding)
+	} else {
+		fmt.Println("Warning: No embedding generated for translated summary")
+	}
+
+	if err := s.summaries.Create(ctx, &summary); err != nil {
+		return nil, databaseError("Failed to save translated summary", err)
+	}
+	fmt.Printf("✓ Translated summary %d to %s (ID: %d)\n", source.ID, target.Name, summary.ID)
+
+	return s.reload(ctx, &summary), nil
+}
+
+// Update replaces a summary's text with an edit, keeping the AI generated text of the
+// first edit. The edit is re-embedded so chat retrieval matches it; when that fails the
+// stored embedding is kept.
+func (s *SummaryService) Update(ctx context.Context, id uint, request dto.SummaryUpdateRequest) (*models.Summaries, error) {
+	if strings.TrimSpace(request.Content) == "" {
+		return nil, invalid("invalid_request", "Content cannot be empty")
+	}
+
+	summary, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if summary.OriginalContent == "" {
+		summary.OriginalContent = summary.Content
+	}
+	now := time.Now()
+	summary.Content = request.Content
+	summary.EditedBy = request.EditedBy
+	summary.EditedAt = &now
+
+	if embedding, err := s.ai.Embed(ctx, request.Content); err != nil {
+		fmt.Printf("Warning: Failed to re-embed edited summary: %v\n", err)
+	} else {
+		summary.Embedding = pgvector.NewVector(embedding)
+	}
+
+	if err := s.summaries.Update(ctx, summary); err != nil {
+		return nil, databaseError("Failed to update summary", err)
+	}
+	return s.reload(ctx, summary), nil
+}
+
+// Pin makes a summary its PDF's primary summary and returns the PDF with its summaries
+func (s *SummaryService) Pin(ctx context.Context, id uint) (*models.PDF, error) {
+	summary, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if summary.PDFID == nil {
+		return nil, invalid("invalid_request", "Synthesized summaries cannot be pinned")
+	}
+
+	if err := s.pdfs.SetPinnedSummary(ctx, *summary.PDFID, &summary.ID); err != nil {
+		return nil, databaseError("Failed to pin summary", err)
+	}
+	return s.pinnedPDF(ctx, *summary.PDFID)
+}
+
+// Unpin makes the latest summary of a pinned summary's PDF primary again and returns
+// the PDF with its summaries
+func (s *SummaryService) Unpin(ctx context.Context, id uint) (*models.PDF, error) {
+	summary, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if summary.PDFID == nil {
+		return nil, invalid("invalid_request", "Summary is not pinned")
+	}
+
+	pdf, err := s.pdfs.Get(ctx, *summary.PDFID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("PDF not found")
+	}
+	if err != nil {
+		return nil, databaseError("Failed to find PDF", err)
+	}
+	if pdf.PinnedSummaryID == nil || *pdf.PinnedSummaryID != summary.ID {
+		return nil, invalid("invalid_request", "Summary is not pinned")
+	}
+
+	if err := s.pdfs.SetPinnedSummary(ctx, pdf.ID, nil); err != nil {
+		return nil, databaseError("Failed to unpin summary", err)
+	}
+	return s.pinnedPDF(ctx, pdf.ID)
+}
+
+// pinnedPDF returns a PDF with its summaries after its pinned summary changed
+func (s *SummaryService) pinnedPDF(ctx context.Context, id uint) (*models.PDF, error) {
+	pdf, err := s.pdfs.GetWithSummaries(ctx, id)
+	if err != nil {
+		return nil, databaseError("Failed to find PDF", err)
+	}
+	return pdf, nil
+}
+
+// AddFeedback saves a vote, rating, comment or reported issues on a summary. Any
+// reported issue flags the summary so it can be excluded from RAG.
+func (s *SummaryService) AddFeedback(ctx context.Context, id uint, request dto.FeedbackRequest) (*models.SummaryFeedback, error) {
+	if err := utils.ValidateFeedback(request.Vote, request.Rating, request.Comment, request.Issues); err != nil {
+		return nil, invalid("invalid_feedback", err.Error())
+	}
+
+	summary, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := make([]string, len(request.Issues))
+	for i, issue := range request.Issues {
+		issues[i] = strings.ToLower(issue)
+	}
+	feedback := models.SummaryFeedback{
+		SummaryID: summary.ID,
+		Vote:      request.Vote,
+		Rating:    request.Rating,
+		Comment:   strings.TrimSpace(request.Comment),
+		Issues:    strings.Join(issues, ","),
+	}
+
+	if err := s.summaries.AddFeedback(ctx, &feedback, len(issues) > 0 && !summary.Flagged); err != nil {
+		return nil, databaseError("Failed to save feedback", err)
+	}
+	return &feedback, nil
+}
+
+// Feedback returns the feedback on a summary, newest first
+func (s *SummaryService) Feedback(ctx context.Context, id uint) ([]models.SummaryFeedback, error) {
+	summary, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	feedback, err := s.summaries.Feedback(ctx, summary.ID)
+	if err != nil {
+		return nil, databaseError("Failed to fetch feedback", err)
+	}
+	return feedback, nil
+}
+
+// Export renders a summary in format (md, html, docx or json); a synthesized summary
+// lists its source PDFs in place of a single PDF
+func (s *SummaryService) Export(ctx context.Context, id uint, format string) (*Export, error) {
+	name, fileFormat, err := exportFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	summaryResponse := utils.ConvertSummaryToResponse(*summary)
+	summaryResponse.PDF = nil
+
+	pdfInfo := utils.ConvertPDFToBasicInfo(summary.PDF)
+	if summary.PDFID == nil {
+		titles := make([]string, 0, len(summary.Sources))
+		for _, source := range summary.Sources {
+			titles = append(titles, source.PDF.Title)
+		}
+		pdfInfo.Title = fmt.Sprintf("Synthesis of %d documents", len(summary.Sources))
+		pdfInfo.Filename = strings.Join(titles, ", ")
+	}
+
+	doc := dto.ExportDocument{
+		Title:      pdfInfo.Title,
+		PDF:        pdfInfo,
+		Summaries:  []dto.SummaryResponse{summaryResponse},
+		ExportedAt: time.Now(),
+	}
+	return renderExport(doc, name, fileFormat, fmt.Sprintf("%s - summary %d", doc.Title, summary.ID), "Failed to export summary")
+}
+
+// reload reads a saved summary back with its PDF and sources; when that fails the
+// summary is returned as it was saved
+func (s *SummaryService) reload(ctx context.Context, summary *models.Summaries) *models.Summaries {
+	saved, err := s.summaries.Get(ctx, summary.ID)
+	if err != nil {
+		return summary
+	}
+	return saved
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"backend-go/fakes"
+	"backend-go/models"
+	"backend-go/repository"
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestListSummariesLanguageFilter(t *testing.T) {
+	tests := []struct {
+		name      string
+		language  string
+		languages []string
+		like      string
+	}{
+		{name: "no language", language: ""},
+		{name: "registered code", language: "de", languages: []string{"de", "german", "german", "deu", "deutsch"}},
+		{name: "registered alias", language: "English", languages: []string{"en", "english", "english", "eng"}},
+		{name: "unregistered language", language: "fr", like: "fr"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summaries := &fakes.SummaryRepository{}
+			service := NewSummaryService(summaries, &fakes.PDFRepository{}, &fakes.AI{})
+
+			if _, _, err := service.List(context.Background(), SummaryQuery{Language: tt.language}, repository.Sort{}, repository.ParsePage(1, 10, 10, 100)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(summaries.LastFilter.Languages, tt.languages) {
+				t.Errorf("languages = %v, want %v", summaries.LastFilter.Languages, tt.languages)
+			}
+			if summaries.LastFilter.LanguageLike != tt.like {
+				t.Errorf("language pattern = %q, want %q", summaries.LastFilter.LanguageLike, tt.like)
+			}
+		})
+	}
+}
+
+func TestDeleteManySummaries(t *testing.T) {
+	tooMany := make([]uint, MaxBulkDelete+1)
+	for i := range tooMany {
+		tooMany[i] = uint(i + 1)
+	}
+
+	tests := []struct {
+		name        string
+		ids         []uint
+		wantDeleted int64
+		code        string
+	}{
+		{name: "deletes existing summaries", ids: []uint{1, 3}, wantDeleted: 2},
+		{name: "ignores unknown IDs", ids: []uint{2, 99}, wantDeleted: 1},
+		{name: "rejects empty requests", ids: nil, code: "invalid_request"},
+		{name: "rejects too many IDs", ids: tooMany, code: "invalid_request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summaries := &fakes.SummaryRepository{}
+			for i := 0; i < 3; i++ {
+				summaries.Create(context.Background(), &models.Summaries{Content: "summary"})
+			}
+			service := NewSummaryService(summaries, &fakes.PDFRepository{}, &fakes.AI{})
+
+			deleted, err := service.DeleteMany(context.Background(), tt.ids)
+			if tt.code != "" {
+				assertError(t, err, 400, tt.code)
+				if len(summaries.Summaries) != 3 {
+					t.Errorf("expected nothing to be deleted")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if deleted != tt.wantDeleted {
+				t.Errorf("deleted = %d, want %d", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestGetSummaryNotFound(t *testing.T) {
+	service := NewSummaryService(&fakes.SummaryRepository{}, &fakes.PDFRepository{}, &fakes.AI{})
+
+	_, err := service.Get(context.Background(), 1)
+	assertError(t, err, 404, "not_found")
+}
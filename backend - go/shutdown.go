@@ -0,0 +1,47 @@
+package main
+
+import (
+	"backend-go/utils"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// shutdown stops accepting connections, waits for in-flight requests and then for background
+// jobs (such as request log writes), and finally closes the storage client and the database
+// pool. Both waits share timeout; the clients are closed even when it runs out.
+func shutdown(app *fiber.App, jobs *utils.Jobs, db *gorm.DB, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var errs []error
+
+	// Requests finish before jobs are drained, since finishing requests still queue log writes
+	if err := app.ShutdownWithContext(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("in-flight requests did not finish: %w", err))
+	} else {
+		fmt.Println("✓ In-flight requests finished")
+	}
+
+	if err := jobs.Wait(ctx); err != nil {
+		errs = append(errs, err)
+	} else {
+		fmt.Println("✓ Background jobs finished")
+	}
+
+	utils.CloseMinIO()
+
+	if sqlDB, err := db.DB(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to get database pool: %w", err))
+	} else if err := sqlDB.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close database: %w", err))
+	} else {
+		fmt.Println("✓ Database connections closed")
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"backend-go/utils"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// lazyDB returns a database handle that never connects; shutdown only closes its pool
+func lazyDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.Open("host=127.0.0.1 port=1 user=test dbname=test"), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("failed to create database handle: %v", err)
+	}
+	return db
+}
+
+// serveSlowRequest serves an app whose /slow route blocks until release is closed and then
+// queues a background job. It returns once a request is in flight, with the channel its
+// response arrives on.
+func serveSlowRequest(t *testing.T, jobs *utils.Jobs, release <-chan struct{}, jobDone *atomic.Bool) (chan *http.Response, *fiber.App) {
+	t.Helper()
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	started := make(chan struct{})
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		close(started)
+		<-release
+		jobs.Go("test job", func() {
+			time.Sleep(20 * time.Millisecond)
+			jobDone.Store(true)
+		})
+		return c.SendString("done")
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go app.Listener(listener)
+
+	responses := make(chan *http.Response, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/slow")
+		if err != nil {
+			responses <- nil
+			return
+		}
+		responses <- resp
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("request did not reach the handler")
+	}
+	return responses, app
+}
+
+func TestShutdownDrainsRequestsAndJobs(t *testing.T) {
+	jobs := &utils.Jobs{}
+	release := make(chan struct{})
+	var jobDone atomic.Bool
+	responses, app := serveSlowRequest(t, jobs, release, &jobDone)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+	}()
+	if err := shutdown(app, jobs, lazyDB(t), 5*time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp := <-responses
+	if resp == nil {
+		t.Fatal("expected the in-flight request to complete")
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != 200 || string(body) != "done" {
+		t.Errorf("response = %d %q, want 200 done", resp.StatusCode, body)
+	}
+	if !jobDone.Load() {
+		t.Error("expected the job queued by the request to finish before shutdown returned")
+	}
+}
+
+func TestShutdownTimesOut(t *testing.T) {
+	jobs := &utils.Jobs{}
+	release := make(chan struct{})
+	defer close(release)
+	var jobDone atomic.Bool
+	_, app := serveSlowRequest(t, jobs, release, &jobDone)
+
+	err := shutdown(app, jobs, lazyDB(t), 100*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "in-flight requests") {
+		t.Fatalf("error = %v, want a timeout waiting for in-flight requests", err)
+	}
+}
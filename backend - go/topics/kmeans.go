@@ -0,0 +1,184 @@
+package topics
+
+import (
+	"math"
+	"math/rand"
+)
+
+// maxIterations bounds each k-means run; runs usually converge well before it
+const maxIterations = 100
+
+// restarts is how many differently seeded runs are tried, keeping the tightest clustering
+const restarts = 5
+
+// SuggestK picks a topic count for n documents using the sqrt(n/2) rule of thumb,
+// kept between 2 and 15 and never above n
+func SuggestK(n int) int {
+	k := int(math.Round(math.Sqrt(float64(n) / 2)))
+	if k < 2 {
+		k = 2
+	}
+	if k > 15 {
+		k = 15
+	}
+	if k > n {
+		k = n
+	}
+	return k
+}
+
+// KMeans clusters vectors into k groups by cosine similarity (spherical k-means with
+// k-means++ seeding). It returns the cluster of each vector and the unit-length
+// centroids. The result only depends on the vectors, k and rng.
+func KMeans(vectors [][]float64, k int, rng *rand.Rand) ([]int, [][]float64) {
+	points := make([][]float64, len(vectors))
+	for i, v := range vectors {
+		points[i] = normalize(v)
+	}
+	if k > len(points) {
+		k = len(points)
+	}
+	if k <= 0 {
+		return make([]int, len(points)), nil
+	}
+
+	var bestAssignments []int
+	var bestCentroids [][]float64
+	bestCost := math.Inf(1)
+	for run := 0; run < restarts; run++ {
+		assignments, centroids := kmeansRun(points, k, rng)
+
+		cost := 0.0
+		for i, p := range points {
+			cost += 1 - dot(p, centroids[assignments[i]])
+		}
+		if cost < bestCost {
+			bestCost, bestAssignments, bestCentroids = cost, assignments, centroids
+		}
+	}
+
+	return bestAssignments, bestCentroids
+}
+
+// CosineToCentroid returns the cosine similarity of v to a unit-length centroid
+func CosineToCentroid(v, centroid []float64) float64 {
+	return dot(normalize(v), centroid)
+}
+
+func kmeansRun(points [][]float64, k int, rng *rand.Rand) ([]int, [][]float64) {
+	centroids := seedCentroids(points, k, rng)
+	assignments := make([]int, len(points))
+	for i := range assignments {
+		assignments[i] = -1
+	}
+
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		changed := false
+		for i, p := range points {
+			best, bestSimilarity := 0, math.Inf(-1)
+			for c, centroid := range centroids {
+				if similarity := dot(p, centroid); similarity > bestSimilarity {
+					best, bestSimilarity = c, similarity
+				}
+			}
+			if assignments[i] != best {
+				assignments[i] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+
+		sums := make([][]float64, k)
+		counts := make([]int, k)
+		for c := range sums {
+			sums[c] = make([]float64, len(points[0]))
+		}
+		for i, p := range points {
+			counts[assignments[i]]++
+			for d, x := range p {
+				sums[assignments[i]][d] += x
+			}
+		}
+
+		for c := range centroids {
+			if counts[c] > 0 {
+				centroids[c] = normalize(sums[c])
+				continue
+			}
+			// Re-seed an empty cluster with the point farthest from its own centroid
+			farthest, lowest := 0, math.Inf(1)
+			for i, p := range points {
+				if counts[assignments[i]] > 1 {
+					if similarity := dot(p, centroids[assignments[i]]); similarity < lowest {
+						farthest, lowest = i, similarity
+					}
+				}
+			}
+			counts[assignments[farthest]]--
+			assignments[farthest] = c
+			counts[c] = 1
+			centroids[c] = append([]float64(nil), points[farthest]...)
+		}
+	}
+
+	return assignments, centroids
+}
+
+// seedCentroids picks k starting centroids with k-means++: each next centroid is drawn
+// with probability proportional to its squared cosine distance from the nearest one
+func seedCentroids(points [][]float64, k int, rng *rand.Rand) [][]float64 {
+	centroids := [][]float64{append([]float64(nil), points[rng.Intn(len(points))]...)}
+	distances := make([]float64, len(points))
+
+	for len(centroids) < k {
+		total := 0.0
+		for i, p := range points {
+			nearest := math.Inf(1)
+			for _, centroid := range centroids {
+				if d := 1 - dot(p, centroid); d < nearest {
+					nearest = d
+				}
+			}
+			distances[i] = nearest * nearest
+			total += distances[i]
+		}
+
+		// All points coincide with a centroid; any choice is as good as another
+		next := rng.Intn(len(points))
+		if total > 0 {
+			target := rng.Float64() * total
+			for i, d := range distances {
+				target -= d
+				if target <= 0 {
+					next = i
+					break
+				}
+			}
+		}
+		centroids = append(centroids, append([]float64(nil), points[next]...))
+	}
+
+	return centroids
+}
+
+func normalize(v []float64) []float64 {
+	norm := math.Sqrt(dot(v, v))
+	out := make([]float64, len(v))
+	if norm == 0 {
+		return out
+	}
+	for i, x := range v {
+		out[i] = x / norm
+	}
+	return out
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
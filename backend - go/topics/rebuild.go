@@ -0,0 +1,230 @@
+package topics
+
+import (
+	"backend-go/dto"
+	"backend-go/models"
+	"backend-go/utils"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/pgvector/pgvector-go"
+	"gorm.io/gorm"
+)
+
+// seed keeps clustering reproducible, so rebuilding an unchanged library yields the same topics
+const seed = 42
+
+// labelDocuments is how many PDFs closest to a centroid are shown to the LLM for labeling
+const labelDocuments = 8
+
+// excerptChars is how much of each PDF's primary summary is shown to the LLM
+const excerptChars = 500
+
+// ErrCannotCluster is wrapped by the errors Rebuild returns when the library or the requested
+// k do not allow clustering, as opposed to a failure while clustering
+var ErrCannotCluster = errors.New("cannot cluster")
+
+// Result describes a finished clustering run
+type Result struct {
+	Documents int `json:"documents"` // PDFs with summary embeddings that were clustered
+	Topics    int `json:"topics"`
+	Labeled   int `json:"labeled"` // Topics labeled by the LLM; the rest use member titles
+}
+
+type document struct {
+	pdf     models.PDF
+	vector  []float64
+	excerpt string
+}
+
+// Rebuild clusters every PDF with embedded summaries into k topics (0 picks k from the
+// library size), asks the Python backend to label each cluster and replaces the stored topics.
+// Each PDF is represented by the average of its summary embeddings. Queries and the labelling
+// call use the context of db.
+func Rebuild(db *gorm.DB, k int) (*Result, error) {
+	docs, err := loadDocuments(db)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) < 2 {
+		return nil, fmt.Errorf("%w: at least 2 PDFs with embedded summaries are needed, found %d", ErrCannotCluster, len(docs))
+	}
+	if k == 0 {
+		k = SuggestK(len(docs))
+	}
+	if k < 1 || k > len(docs) {
+		return nil, fmt.Errorf("%w: k must be between 1 and the number of PDFs (%d)", ErrCannotCluster, len(docs))
+	}
+
+	vectors := make([][]float64, len(docs))
+	for i, doc := range docs {
+		vectors[i] = doc.vector
+	}
+	assignments, centroids := KMeans(vectors, k, rand.New(rand.NewSource(seed)))
+
+	topics := make([]models.Topic, len(centroids))
+	for i, doc := range docs {
+		c := assignments[i]
+		topics[c].Members = append(topics[c].Members, models.TopicMember{
+			PDFID: doc.pdf.ID,
+			Score: CosineToCentroid(doc.vector, centroids[c]),
+		})
+	}
+
+	// Label from the members closest to each centroid
+	byPDF := make(map[uint]document, len(docs))
+	for _, doc := range docs {
+		byPDF[doc.pdf.ID] = doc
+	}
+	request := dto.PythonLabelTopicsRequest{}
+	for c := range topics {
+		members := topics[c].Members
+		sort.Slice(members, func(a, b int) bool { return members[a].Score > members[b].Score })
+		topics[c].Size = len(members)
+
+		cluster := dto.PythonTopicCluster{ID: c}
+		for _, member := range members {
+			if len(cluster.Documents) == labelDocuments {
+				break
+			}
+			doc := byPDF[member.PDFID]
+			cluster.Documents = append(cluster.Documents, dto.PythonTopicDocument{
+				Title:   doc.pdf.Title,
+				Excerpt: doc.excerpt,
+			})
+		}
+		request.Clusters = append(request.Clusters, cluster)
+	}
+
+	result := &Result{Documents: len(docs)}
+	var labels dto.PythonLabelTopicsResponse
+	if err := utils.PostToPythonAPI(db.Statement.Context, "/label-topics", request, &labels); err != nil {
+		fmt.Printf("Warning: Failed to label topics, using member titles: %v\n", err)
+	}
+	labeled := make(map[int]dto.PythonTopicLabel, len(labels.Topics))
+	for _, label := range labels.Topics {
+		if strings.TrimSpace(label.Label) != "" {
+			labeled[label.ID] = label
+		}
+	}
+
+	for c := range topics {
+		if label, ok := labeled[c]; ok {
+			topics[c].Label = strings.TrimSpace(label.Label)
+			topics[c].Description = strings.TrimSpace(label.Description)
+			topics[c].ModelName = labels.Model
+			topics[c].PromptVersion = labels.PromptVersion
+			result.Labeled++
+			continue
+		}
+		titles := make([]string, 0, 2)
+		for _, document := range request.Clusters[c].Documents {
+			if len(titles) == 2 {
+				break
+			}
+			titles = append(titles, document.Title)
+		}
+		topics[c].Label = strings.Join(titles, " / ")
+	}
+
+	// Drop clusters that ended up empty after re-seeding
+	kept := topics[:0]
+	for _, topic := range topics {
+		if topic.Size > 0 {
+			kept = append(kept, topic)
+		}
+	}
+	topics = kept
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Members are removed by the ON DELETE CASCADE constraint
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(&models.Topic{}).Error; err != nil {
+			return fmt.Errorf("failed to clear topics: %w", err)
+		}
+		if err := tx.Create(&topics).Error; err != nil {
+			return fmt.Errorf("failed to save topics: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Topics = len(topics)
+	return result, nil
+}
+
+// loadDocuments returns each PDF with embedded summaries, its average embedding
+// and an excerpt of its primary summary
+func loadDocuments(db *gorm.DB) ([]document, error) {
+	var rows []struct {
+		PDFID     uint
+		Embedding pgvector.Vector
+	}
+	if err := db.Raw(`
+		SELECT s.pdf_id, AVG(s.embedding) AS embedding
+		FROM summaries s
+		JOIN pdfs p ON p.id = s.pdf_id AND p.deleted_at IS NULL
+		WHERE s.embedding IS NOT NULL AND s.deleted_at IS NULL
+		GROUP BY s.pdf_id
+		ORDER BY s.pdf_id`).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch document embeddings: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.PDFID
+	}
+	var pdfs []models.PDF
+	if err := db.Where("id IN ?", ids).Find(&pdfs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch PDFs: %w", err)
+	}
+	pdfByID := make(map[uint]models.PDF, len(pdfs))
+	for _, pdf := range pdfs {
+		pdfByID[pdf.ID] = pdf
+	}
+
+	summaryIDs, err := utils.PrimarySummaryIDs(db, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find primary summaries: %w", err)
+	}
+	var summaries []models.Summaries
+	if len(summaryIDs) > 0 {
+		if err := db.Omit("embedding").Where("id IN ?", summaryIDs).Find(&summaries).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch summaries: %w", err)
+		}
+	}
+	excerpts := make(map[uint]string, len(summaries))
+	for _, summary := range summaries {
+		if summary.PDFID == nil {
+			continue
+		}
+		excerpt := []rune(summary.Content)
+		if len(excerpt) > excerptChars {
+			excerpt = excerpt[:excerptChars]
+		}
+		excerpts[*summary.PDFID] = string(excerpt)
+	}
+
+	docs := make([]document, 0, len(rows))
+	for _, row := range rows {
+		pdf, ok := pdfByID[row.PDFID]
+		if !ok {
+			continue
+		}
+		values := row.Embedding.Slice()
+		vector := make([]float64, len(values))
+		for i, x := range values {
+			vector[i] = float64(x)
+		}
+		docs = append(docs, document{pdf: pdf, vector: vector, excerpt: excerpts[pdf.ID]})
+	}
+
+	return docs, nil
+}
@@ -0,0 +1,219 @@
+package utils
+
+import (
+	"backend-go/config"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	// pythonAPIURL is the base URL of the Python AI service
+	pythonAPIURL = config.Default().PythonAPI.URL
+	// pythonAPITimeout bounds each call to the Python AI service
+	pythonAPITimeout = config.Default().PythonAPI.Timeout
+)
+
+// SetPythonAPI sets the base URL, per-attempt timeout, retries and circuit breakers of the
+// Python AI service. Every breaker starts closed.
+func SetPythonAPI(cfg config.PythonAPI) {
+	pythonAPIURL = strings.TrimRight(cfg.URL, "/")
+	pythonAPITimeout = cfg.Timeout
+	pythonAPIMaxRetries = cfg.MaxRetries
+	resetAIBreakers(cfg.BreakerThreshold, cfg.BreakerCooldown)
+}
+
+// GetPythonAPIURL returns the base URL of the Python AI service
+func GetPythonAPIURL() string {
+	return pythonAPIURL
+}
+
+// ExtractPDFText asks the Python backend for the first maxChars characters of a PDF's text
+func ExtractPDFText(ctx context.Context, filename string, reader io.Reader, maxChars int) (string, error) {
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := PostFileToPythonAPI(ctx, "/extract-text", filename, reader, map[string]string{
+		"max_chars": strconv.Itoa(maxChars),
+	}, &result); err != nil {
+		return "", err
+	}
+
+	return result.Text, nil
+}
+
+// PostFileToPythonAPI posts a file and form fields as multipart data to a Python backend endpoint
+// and decodes the response into out. A nil reader sends only the fields.
+func PostFileToPythonAPI(ctx context.Context, path, filename string, reader io.Reader, fields map[string]string, out interface{}) error {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	if reader != nil {
+		filePart, err := writer.CreateFormFile("file", filename)
+		if err != nil {
+			return fmt.Errorf("failed to create form file: %w", err)
+		}
+		if _, err := io.Copy(filePart, reader); err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+	}
+	for name, value := range fields {
+		writer.WriteField(name, value)
+	}
+	writer.Close()
+
+	return postToPythonAPI(ctx, path, writer.FormDataContentType(), body.Bytes(), out)
+}
+
+// PythonAPIError is returned when the Python backend responds with a non-200 status
+type PythonAPIError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // From the Retry-After header; 0 when not sent
+}
+
+func (e *PythonAPIError) Error() string {
+	return fmt.Sprintf("python backend returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// PostToPythonAPI posts payload as JSON to a Python backend endpoint and decodes the response into out.
+// When out is a *[]byte the raw response body is stored instead.
+func PostToPythonAPI(ctx context.Context, path string, payload interface{}, out interface{}) error {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to prepare request: %w", err)
+	}
+
+	return postToPythonAPI(ctx, path, "application/json", jsonData, out)
+}
+
+// postToPythonAPI sends a request body to a Python backend endpoint and decodes the response
+// into out (or stores it raw for a *[]byte). Rate-limited and failed calls are retried and
+// guarded by the endpoint's circuit breaker (see callPythonAPI). The call is cancelled with
+// ctx and each attempt is limited to the configured timeout; a timeout is reported as
+// context.DeadlineExceeded.
+func postToPythonAPI(ctx context.Context, path, contentType string, body []byte, out interface{}) error {
+	return callPythonAPI(ctx, path, func(ctx context.Context) error {
+		return postOnce(ctx, path, contentType, body, out)
+	})
+}
+
+// postOnce makes a single attempt of a postToPythonAPI call
+func postOnce(ctx context.Context, path, contentType string, body []byte, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, pythonAPITimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, GetPythonAPIURL()+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to prepare request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Python backend: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return &PythonAPIError{
+			StatusCode: resp.StatusCode,
+			Body:       string(bodyBytes),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+
+	// Binary responses (e.g. generated files) are returned as-is
+	if raw, ok := out.(*[]byte); ok {
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		*raw = data
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return nil
+}
+
+// SendPythonAPIError writes a consistent error response for a failed Python backend call
+func SendPythonAPIError(c *fiber.Ctx, err error) error {
+	var apiErr *PythonAPIError
+	if errors.As(err, &apiErr) {
+		SetRetryAfter(c, apiErr.RetryAfter)
+		return c.Status(apiErr.StatusCode).JSON(fiber.Map{
+			"error":   "backend_error",
+			"message": "Python backend error",
+			"details": apiErr.Body,
+		})
+	}
+
+	var openErr *CircuitOpenError
+	if errors.As(err, &openErr) {
+		SetRetryAfter(c, openErr.RetryAfter)
+		return c.Status(503).JSON(fiber.Map{
+			"error":   "ai_unavailable",
+			"message": "AI service is temporarily unavailable, try again later",
+			"details": err.Error(),
+		})
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return c.Status(504).JSON(fiber.Map{
+			"error":   "timeout",
+			"message": "Python backend did not respond in time",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(500).JSON(fiber.Map{
+		"error":   "backend_error",
+		"message": "Failed to connect to Python backend",
+		"details": err.Error(),
+	})
+}
+
+// SetRetryAfter tells the client how long to wait before retrying, in whole seconds
+func SetRetryAfter(c *fiber.Ctx, wait time.Duration) {
+	if wait > 0 {
+		c.Set("Retry-After", strconv.Itoa(int((wait+time.Second-1)/time.Second)))
+	}
+}
+
+// GenerateEmbedding returns an embedding vector of text, from the embedding cache when the
+// same text (ignoring surrounding and repeated whitespace) has been embedded before, and from
+// the Python backend otherwise
+func GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
+	text = NormalizeEmbeddingText(text)
+	if embedding, ok := embeddingCache.Get(ctx, text); ok {
+		return embedding, nil
+	}
+
+	var result struct {
+		Embedding []float32 `json:"embedding"`
+	}
+	if err := PostToPythonAPI(ctx, "/embedding", map[string]interface{}{"text": text}, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Embedding) == 0 {
+		return nil, fmt.Errorf("no embedding returned from Python backend")
+	}
+
+	embeddingCache.Put(ctx, text, result.Embedding)
+	return result.Embedding, nil
+}
@@ -0,0 +1,247 @@
+package utils
+
+import (
+	"backend-go/config"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// usePythonAPI points the AI client at url with the given settings applied to the defaults,
+// and fast retries, until the test ends
+func usePythonAPI(t *testing.T, url string, modify func(cfg *config.PythonAPI)) {
+	t.Helper()
+	cfg := config.Default().PythonAPI
+	cfg.URL = url
+	if modify != nil {
+		modify(&cfg)
+	}
+	SetPythonAPI(cfg)
+
+	baseDelay := retryBaseDelay
+	retryBaseDelay = time.Millisecond
+	t.Cleanup(func() {
+		retryBaseDelay = baseDelay
+		SetPythonAPI(config.Default().PythonAPI)
+	})
+}
+
+// scriptedServer answers the n-th request (from 0) with respond(n, w) and counts requests
+func scriptedServer(t *testing.T, respond func(n int, w http.ResponseWriter)) *atomic.Int32 {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		respond(int(calls.Add(1))-1, w)
+	}))
+	t.Cleanup(server.Close)
+	usePythonAPI(t, server.URL, nil)
+	return &calls
+}
+
+func TestPostToPythonAPIStopsWaiting(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		timeout time.Duration
+		want    error
+	}{
+		{name: "timeout", ctx: context.Background(), timeout: 50 * time.Millisecond, want: context.DeadlineExceeded},
+		{name: "cancelled request", ctx: cancelled, timeout: time.Minute, want: context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usePythonAPI(t, server.URL, func(cfg *config.PythonAPI) { cfg.Timeout = tt.timeout })
+
+			start := time.Now()
+			err := PostToPythonAPI(tt.ctx, "/slow", map[string]string{}, nil)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error = %v, want %v", err, tt.want)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("call took %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestPostToPythonAPIRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		respond    func(n int, w http.ResponseWriter)
+		wantCalls  int32
+		wantStatus int // 0 when the call succeeds
+		wantWait   time.Duration
+	}{
+		{
+			name: "retries until the backend recovers",
+			respond: func(n int, w http.ResponseWriter) {
+				if n < 2 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Write([]byte(`{}`))
+			},
+			wantCalls: 3,
+		},
+		{
+			name: "honours a short Retry-After",
+			respond: func(n int, w http.ResponseWriter) {
+				if n == 0 {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.Write([]byte(`{}`))
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "gives up after the last retry",
+			respond: func(n int, w http.ResponseWriter) {
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			wantCalls:  4,
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name: "does not wait for a long Retry-After",
+			respond: func(n int, w http.ResponseWriter) {
+				w.Header().Set("Retry-After", "120")
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			wantCalls:  1,
+			wantStatus: http.StatusTooManyRequests,
+			wantWait:   2 * time.Minute,
+		},
+		{
+			name: "does not retry rejected requests",
+			respond: func(n int, w http.ResponseWriter) {
+				w.WriteHeader(http.StatusBadRequest)
+			},
+			wantCalls:  1,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := scriptedServer(t, tt.respond)
+
+			var out struct{}
+			err := PostToPythonAPI(context.Background(), "/chat", map[string]string{}, &out)
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var apiErr *PythonAPIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.wantStatus {
+				t.Fatalf("error = %v, want status %d", err, tt.wantStatus)
+			}
+			if apiErr.RetryAfter != tt.wantWait {
+				t.Errorf("retry after = %s, want %s", apiErr.RetryAfter, tt.wantWait)
+			}
+		})
+	}
+}
+
+func TestPostToPythonAPICircuitBreaker(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	calls := scriptedServer(t, func(n int, w http.ResponseWriter) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{}`))
+	})
+	usePythonAPI(t, GetPythonAPIURL(), func(cfg *config.PythonAPI) {
+		cfg.MaxRetries = 0
+		cfg.BreakerThreshold = 2
+		cfg.BreakerCooldown = 50 * time.Millisecond
+	})
+
+	var out struct{}
+	for i := 0; i < 2; i++ {
+		PostToPythonAPI(context.Background(), "/summarize", map[string]string{}, &out)
+	}
+
+	err := PostToPythonAPI(context.Background(), "/summarize", map[string]string{}, &out)
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) || openErr.Endpoint != "summarize" {
+		t.Fatalf("error = %v, want the summarize circuit to be open", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want the open circuit to fail without calling the backend", calls.Load())
+	}
+	if state := AIServiceStatus()["summarize"].State; state != BreakerOpen {
+		t.Errorf("summarize breaker = %s, want open", state)
+	}
+	if state := AIServiceStatus()["chat"].State; state != BreakerClosed {
+		t.Errorf("chat breaker = %s, want closed; breakers are per endpoint", state)
+	}
+
+	failing.Store(false)
+	time.Sleep(60 * time.Millisecond)
+	if err := PostToPythonAPI(context.Background(), "/summarize", map[string]string{}, &out); err != nil {
+		t.Fatalf("expected the trial call after the cooldown to succeed, got %v", err)
+	}
+	if state := AIServiceStatus()["summarize"].State; state != BreakerClosed {
+		t.Errorf("summarize breaker = %s, want closed after a successful trial", state)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"30", 30 * time.Second},
+		{"-5", 0},
+		{"Mon, 01 Jan 2024 12:00:10 GMT", 10 * time.Second},
+		{"Mon, 01 Jan 2024 11:00:00 GMT", 0},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRetryDelayBacksOffWithJitter(t *testing.T) {
+	for retry := 0; retry < 8; retry++ {
+		backoff := retryBaseDelay << retry
+		if backoff > retryMaxDelay {
+			backoff = retryMaxDelay
+		}
+		delay, ok := retryDelay(retry, errors.New("connection refused"))
+		if !ok || delay < backoff/2 || delay > backoff {
+			t.Errorf("retry %d: delay = %s, want between %s and %s", retry, delay, backoff/2, backoff)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package utils
+
+import (
+	"backend-go/config"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// pythonAPIMaxRetries is how often a rate-limited or unavailable call is retried
+	pythonAPIMaxRetries = config.Default().PythonAPI.MaxRetries
+	// retryBaseDelay is the backoff before the first retry; it doubles with every retry
+	retryBaseDelay = 500 * time.Millisecond
+	// retryMaxDelay caps the backoff, and a longer Retry-After is not waited for
+	retryMaxDelay = 30 * time.Second
+)
+
+// aiEndpoints are the Python API endpoints /health always reports, whether or not they
+// have been called yet
+var aiEndpoints = []string{"summarize", "embedding", "chat"}
+
+// aiBreakers holds one circuit breaker per Python API endpoint
+var aiBreakers = struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	byName    map[string]*CircuitBreaker
+}{
+	threshold: config.Default().PythonAPI.BreakerThreshold,
+	cooldown:  config.Default().PythonAPI.BreakerCooldown,
+}
+
+// resetAIBreakers replaces every breaker with a closed one using the given settings
+func resetAIBreakers(threshold int, cooldown time.Duration) {
+	aiBreakers.mu.Lock()
+	defer aiBreakers.mu.Unlock()
+	aiBreakers.threshold = threshold
+	aiBreakers.cooldown = cooldown
+	aiBreakers.byName = make(map[string]*CircuitBreaker)
+	for _, name := range aiEndpoints {
+		aiBreakers.byName[name] = NewCircuitBreaker(threshold, cooldown)
+	}
+}
+
+func init() {
+	resetAIBreakers(aiBreakers.threshold, aiBreakers.cooldown)
+}
+
+// aiBreaker returns the breaker of an endpoint, creating it on first use
+func aiBreaker(name string) *CircuitBreaker {
+	aiBreakers.mu.Lock()
+	defer aiBreakers.mu.Unlock()
+	breaker, ok := aiBreakers.byName[name]
+	if !ok {
+		breaker = NewCircuitBreaker(aiBreakers.threshold, aiBreakers.cooldown)
+		aiBreakers.byName[name] = breaker
+	}
+	return breaker
+}
+
+// AIServiceStatus returns the circuit breaker state of every Python API endpoint by name
+func AIServiceStatus() map[string]BreakerStatus {
+	aiBreakers.mu.Lock()
+	defer aiBreakers.mu.Unlock()
+	status := make(map[string]BreakerStatus, len(aiBreakers.byName))
+	for name, breaker := range aiBreakers.byName {
+		status[name] = breaker.Status()
+	}
+	return status
+}
+
+// CircuitOpenError is returned without calling the Python backend while the breaker of the
+// endpoint is open
+type CircuitOpenError struct {
+	Endpoint   string
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("python backend %s calls are paused after repeated failures, retry in %s",
+		e.Endpoint, e.RetryAfter.Round(time.Second))
+}
+
+// callPythonAPI makes a call through the breaker of its endpoint, retrying failures that
+// may pass. Errors the backend answered deliberately (such as a 400) keep the breaker closed;
+// calls abandoned because ctx ended do not count either way.
+func callPythonAPI(ctx context.Context, path string, attempt func(ctx context.Context) error) error {
+	name := strings.TrimPrefix(path, "/")
+	breaker := aiBreaker(name)
+	if wait, ok := breaker.Allow(); !ok {
+		return &CircuitOpenError{Endpoint: name, RetryAfter: wait}
+	}
+
+	err := withRetries(ctx, path, attempt)
+	switch {
+	case err == nil:
+		breaker.Success()
+	case ctx.Err() != nil:
+		breaker.Abandon()
+	case isOutage(err):
+		breaker.Failure(err)
+	default:
+		breaker.Success()
+	}
+	return err
+}
+
+// withRetries runs attempt, retrying retryable failures up to pythonAPIMaxRetries times with
+// exponential backoff and jitter, or after the delay the backend asked for with Retry-After.
+// It gives up early, returning the last error, when the wait would outlast ctx.
+func withRetries(ctx context.Context, path string, attempt func(ctx context.Context) error) error {
+	for retry := 0; ; retry++ {
+		err := attempt(ctx)
+		if err == nil || retry >= pythonAPIMaxRetries || ctx.Err() != nil || !isRetryable(err) {
+			return err
+		}
+
+		delay, ok := retryDelay(retry, err)
+		if !ok {
+			return err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+
+		fmt.Printf("Warning: Python backend %s failed (%v), retrying in %s (%d/%d)\n",
+			path, err, delay.Round(time.Millisecond), retry+1, pythonAPIMaxRetries)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
+
+// isRetryable reports whether a failed attempt may succeed when repeated: rate limits,
+// unavailable or overloaded backends and connection failures. Timeouts are not repeated,
+// since a retry would wait as long again.
+func isRetryable(err error) bool {
+	var apiErr *PythonAPIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// isOutage reports whether a failed call counts against the breaker of its endpoint
+func isOutage(err error) bool {
+	var apiErr *PythonAPIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	return true
+}
+
+// retryDelay returns how long to wait before retry number retry+1. A Retry-After sent with
+// the error is honoured, unless it is longer than retryMaxDelay, in which case ok is false.
+func retryDelay(retry int, err error) (delay time.Duration, ok bool) {
+	var apiErr *PythonAPIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter, apiErr.RetryAfter <= retryMaxDelay
+	}
+
+	backoff := retryBaseDelay << retry
+	if backoff <= 0 || backoff > retryMaxDelay {
+		backoff = retryMaxDelay
+	}
+	// Equal jitter keeps at least half the backoff while spreading out concurrent callers
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1)), true
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date; it
+// returns 0 when the header is missing or invalid
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
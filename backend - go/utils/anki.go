@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AnkiCard is one question/answer note in an Anki export
+type AnkiCard struct {
+	ID    uint     `json:"id,omitempty"` // Flashcard ID, which Anki notes are matched on when re-imported
+	Front string   `json:"front"`
+	Back  string   `json:"back"`
+	Tags  []string `json:"tags"`
+}
+
+// AnkiFormats lists the supported Anki export formats by name
+var AnkiFormats = map[string]ExportFormat{
+	"apkg": {ContentType: "application/octet-stream", Extension: ".apkg"},
+	"csv":  {ContentType: "text/csv; charset=utf-8", Extension: ".csv"},
+	"tsv":  {ContentType: "text/tab-separated-values; charset=utf-8", Extension: ".tsv"},
+}
+
+var tagWhitespace = regexp.MustCompile(`\s+`)
+
+// AnkiTag turns text such as a document title into an Anki tag, which cannot contain spaces
+func AnkiTag(text string) string {
+	return tagWhitespace.ReplaceAllString(strings.TrimSpace(text), "_")
+}
+
+// RenderAnkiDeck renders cards in the requested Anki export format.
+// apkg packages are built by the Python backend, which has SQLite available.
+func RenderAnkiDeck(ctx context.Context, deckName string, cards []AnkiCard, format string) ([]byte, error) {
+	switch format {
+	case "apkg":
+		var pkg []byte
+		if err := PostToPythonAPI(ctx, "/anki-package", map[string]interface{}{
+			"deck_name": deckName,
+			"cards":     cards,
+		}, &pkg); err != nil {
+			return nil, err
+		}
+		return pkg, nil
+	case "csv":
+		return renderAnkiText(cards, ',', "Comma")
+	case "tsv":
+		return renderAnkiText(cards, '\t', "Tab")
+	default:
+		return nil, fmt.Errorf("unsupported Anki format: %s", format)
+	}
+}
+
+// renderAnkiText writes front, back and tags columns with the header lines Anki's
+// importer uses to pick the separator and the tags column
+func renderAnkiText(cards []AnkiCard, separator rune, separatorName string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "#separator:%s\n#html:false\n#tags column:3\n", separatorName)
+
+	writer := csv.NewWriter(&buf)
+	writer.Comma = separator
+	for _, card := range cards {
+		if err := writer.Write([]string{card.Front, card.Back, strings.Join(card.Tags, " ")}); err != nil {
+			return nil, fmt.Errorf("failed to write card: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write cards: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a CircuitBreaker
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"    // Calls go through
+	BreakerOpen     BreakerState = "open"      // Calls fail fast until the cooldown ends
+	BreakerHalfOpen BreakerState = "half_open" // One trial call decides whether to close again
+)
+
+// BreakerStatus is a snapshot of a CircuitBreaker, as reported by /health
+type BreakerStatus struct {
+	State               BreakerState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	OpenedAt            *time.Time   `json:"opened_at,omitempty"`
+	RetryInSeconds      int          `json:"retry_in_seconds,omitempty"`
+	LastError           string       `json:"last_error,omitempty"`
+}
+
+// CircuitBreaker stops calls to a dependency that keeps failing. After threshold failures
+// in a row it opens and rejects calls for cooldown, then lets a single trial call through:
+// success closes it again, failure reopens it for another cooldown.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	trial     bool // A half-open trial call is in flight
+	lastError string
+}
+
+// NewCircuitBreaker returns a closed breaker that opens after threshold failures in a row
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now, state: BreakerClosed}
+}
+
+// Allow reports whether a call may be made. When it may not, it returns how long until the
+// breaker lets a trial call through. Every allowed call must be finished with Success,
+// Failure or Abandon.
+func (b *CircuitBreaker) Allow() (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if wait := b.openedAt.Add(b.cooldown).Sub(b.now()); wait > 0 {
+			return wait, false
+		}
+		b.state = BreakerHalfOpen
+		b.trial = true
+		return 0, true
+	case BreakerHalfOpen:
+		if b.trial {
+			// Other calls wait for the outcome of the trial
+			return time.Second, false
+		}
+		b.trial = true
+		return 0, true
+	default:
+		return 0, true
+	}
+}
+
+// Success records that an allowed call worked, closing the breaker
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = BreakerClosed
+	b.failures = 0
+	b.trial = false
+}
+
+// Failure records that an allowed call failed, opening the breaker once the threshold is
+// reached or when the failed call was the half-open trial
+func (b *CircuitBreaker) Failure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.lastError = err.Error()
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+	b.trial = false
+}
+
+// Abandon records that an allowed call ended without telling whether the dependency works,
+// e.g. because the client went away
+func (b *CircuitBreaker) Abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+// Status returns the current state of the breaker
+func (b *CircuitBreaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := BreakerStatus{State: b.state, ConsecutiveFailures: b.failures, LastError: b.lastError}
+	if b.state != BreakerClosed {
+		openedAt := b.openedAt
+		status.OpenedAt = &openedAt
+	}
+	if b.state == BreakerOpen {
+		if wait := b.openedAt.Add(b.cooldown).Sub(b.now()); wait > 0 {
+			status.RetryInSeconds = int((wait + time.Second - 1) / time.Second)
+		}
+	}
+	return status
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	breaker := NewCircuitBreaker(3, 30*time.Second)
+	breaker.now = func() time.Time { return now }
+	failure := errors.New("python backend returned status 503")
+
+	allow := func(want bool) {
+		t.Helper()
+		if _, ok := breaker.Allow(); ok != want {
+			t.Fatalf("Allow() = %v, want %v in state %s", ok, want, breaker.Status().State)
+		}
+	}
+
+	// Failures below the threshold, or interrupted by a success, keep it closed
+	for i := 0; i < 2; i++ {
+		allow(true)
+		breaker.Failure(failure)
+	}
+	allow(true)
+	breaker.Success()
+	for i := 0; i < 2; i++ {
+		allow(true)
+		breaker.Failure(failure)
+	}
+	if state := breaker.Status().State; state != BreakerClosed {
+		t.Fatalf("state = %s, want closed", state)
+	}
+
+	allow(true)
+	breaker.Failure(failure)
+	status := breaker.Status()
+	if status.State != BreakerOpen || status.RetryInSeconds != 30 || status.LastError != failure.Error() {
+		t.Fatalf("status = %+v, want open for 30s", status)
+	}
+	if wait, ok := breaker.Allow(); ok || wait != 30*time.Second {
+		t.Fatalf("Allow() = %s, %v, want a 30s wait", wait, ok)
+	}
+
+	// After the cooldown one trial call is let through; a failed trial reopens it
+	now = now.Add(30 * time.Second)
+	allow(true)
+	allow(false)
+	breaker.Failure(failure)
+	if state := breaker.Status().State; state != BreakerOpen {
+		t.Fatalf("state = %s, want open after a failed trial", state)
+	}
+
+	// An abandoned trial lets the next call try again; a successful one closes it
+	now = now.Add(30 * time.Second)
+	allow(true)
+	breaker.Abandon()
+	allow(true)
+	breaker.Success()
+	if status := breaker.Status(); status.State != BreakerClosed || status.ConsecutiveFailures != 0 {
+		t.Fatalf("status = %+v, want closed with no failures", status)
+	}
+}
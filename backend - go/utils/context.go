@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// disconnectPollInterval is how often an in-flight request checks whether its client has gone
+const disconnectPollInterval = 200 * time.Millisecond
+
+// RequestContextMiddleware gives each request a context, available as c.UserContext(), that is
+// cancelled when the client disconnects before the handler returns. Database, storage and AI
+// calls made with it stop instead of finishing work nobody will receive. A response body that is
+// streamed after the handler returns (such as a download) keeps its context.
+func RequestContextMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithCancel(c.UserContext())
+		c.SetUserContext(ctx)
+
+		stop := watchDisconnect(c.Context().Conn(), cancel)
+		defer stop()
+
+		return c.Next()
+	}
+}
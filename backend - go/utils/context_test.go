@@ -0,0 +1,91 @@
+//go:build linux || darwin
+
+package utils
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// serveContextApp serves an app whose /wait route reports on result whether its request
+// context was cancelled before it gave up after 5 seconds, and whose /pause route outlasts
+// a few disconnect checks and responds with the state of its context
+func serveContextApp(t *testing.T, result chan<- bool) string {
+	t.Helper()
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Use(RequestContextMiddleware())
+	app.Get("/wait", func(c *fiber.Ctx) error {
+		select {
+		case <-c.UserContext().Done():
+			result <- true
+		case <-time.After(5 * time.Second):
+			result <- false
+		}
+		return c.SendString("done")
+	})
+	app.Get("/pause", func(c *fiber.Ctx) error {
+		time.Sleep(3 * disconnectPollInterval)
+		if c.UserContext().Err() != nil {
+			return c.SendString("cancelled")
+		}
+		return c.SendString("connected")
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go app.Listener(listener)
+	t.Cleanup(func() { app.Shutdown() })
+	return listener.Addr().String()
+}
+
+func TestRequestContextCancelledOnDisconnect(t *testing.T) {
+	result := make(chan bool, 1)
+	address := serveContextApp(t, result)
+
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	conn.Write([]byte("GET /wait HTTP/1.1\r\nHost: test\r\n\r\n"))
+	time.Sleep(50 * time.Millisecond)
+	conn.Close()
+
+	if cancelled := <-result; !cancelled {
+		t.Fatal("expected the request context to be cancelled after the client disconnected")
+	}
+}
+
+func TestRequestContextKeptForConnectedClients(t *testing.T) {
+	result := make(chan bool, 1)
+	address := serveContextApp(t, result)
+
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	// Two pipelined requests: peeking must not consume the second one
+	conn.Write([]byte("GET /pause HTTP/1.1\r\nHost: test\r\n\r\nGET /pause HTTP/1.1\r\nHost: test\r\n\r\n"))
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reader := bufio.NewReader(conn)
+	for i := 0; i < 2; i++ {
+		resp, err := http.ReadResponse(reader, nil)
+		if err != nil {
+			t.Fatalf("response %d: %v", i+1, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != 200 || string(body) != "connected" {
+			t.Fatalf("response %d = %d %q, want 200 connected", i+1, resp.StatusCode, body)
+		}
+	}
+}
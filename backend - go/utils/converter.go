@@ -3,6 +3,7 @@ package utils
 import (
 	"backend-go/dto"
 	"backend-go/models"
+	"strings"
 )
 
 // ConvertPDFToResponse converts PDF model to PDFResponse DTO
@@ -24,6 +25,8 @@ func ConvertPDFToResponse(pdf models.PDF) dto.PDFResponse {
 	response.Style = pdf.Style
 	response.Language = pdf.Language
 	response.SummaryTime = pdf.SummaryTime
+	response.DetectedLanguage = pdf.DetectedLanguage
+	response.PinnedSummaryID = pdf.PinnedSummaryID
 
 	return response
 }
@@ -37,28 +40,56 @@ func ConvertPDFsToResponse(pdfs []models.PDF) []dto.PDFResponse {
 	return responses
 }
 
+// ConvertPDFToBasicInfo converts PDF model to PDFBasicInfo DTO
+func ConvertPDFToBasicInfo(pdf models.PDF) dto.PDFBasicInfo {
+	return dto.PDFBasicInfo{
+		ID:        pdf.ID,
+		Title:     pdf.Title,
+		Filename:  pdf.Filename,
+		FileSize:  pdf.FileSize,
+		PageCount: pdf.PageCount,
+	}
+}
+
 // ConvertSummaryToResponse converts Summary model to SummaryResponse DTO
 func ConvertSummaryToResponse(summary models.Summaries) dto.SummaryResponse {
 	response := dto.SummaryResponse{
-		ID:          summary.ID,
-		Style:       summary.Style,
-		Content:     summary.Content,
-		PDFID:       summary.PDFID,
-		Language:    summary.Language,
-		SummaryTime: summary.SummaryTime,
-		CreatedAt:   summary.CreatedAt,
-		UpdatedAt:   summary.UpdatedAt,
+		ID:              summary.ID,
+		Style:           summary.Style,
+		Content:         summary.Content,
+		PDFID:           summary.PDFID,
+		Language:        summary.Language,
+		SummaryTime:     summary.SummaryTime,
+		SourceSummaryID: summary.SourceSummaryID,
+		OriginalContent: summary.OriginalContent,
+		ModelName:       summary.ModelName,
+		PromptVersion:   summary.PromptVersion,
+		EditedBy:        summary.EditedBy,
+		EditedAt:        summary.EditedAt,
+		Flagged:         summary.Flagged,
+		Focus:           summary.Focus,
+		CreatedAt:       summary.CreatedAt,
+		UpdatedAt:       summary.UpdatedAt,
 	}
 
 	// Include PDF basic info if available
 	if summary.PDF.ID != 0 {
-		response.PDF = &dto.PDFBasicInfo{
-			ID:        summary.PDF.ID,
-			Title:     summary.PDF.Title,
-			Filename:  summary.PDF.Filename,
-			FileSize:  summary.PDF.FileSize,
-			PageCount: summary.PDF.PageCount,
+		info := ConvertPDFToBasicInfo(summary.PDF)
+		response.PDF = &info
+	}
+
+	// Include source PDFs of a synthesized summary if loaded
+	for _, source := range summary.Sources {
+		info := dto.SourceInfo{
+			PDFID:            source.PDFID,
+			Position:         source.Position,
+			ContextSummaryID: source.ContextSummaryID,
+		}
+		if source.PDF.ID != 0 {
+			basic := ConvertPDFToBasicInfo(source.PDF)
+			info.PDF = &basic
 		}
+		response.Sources = append(response.Sources, info)
 	}
 
 	return response
@@ -71,3 +102,257 @@ func ConvertSummariesToResponse(summaries []models.Summaries) []dto.SummaryRespo
 	}
 	return responses
 }
+
+// ConvertFeedbackToResponse converts SummaryFeedback model to FeedbackResponse DTO
+func ConvertFeedbackToResponse(feedback models.SummaryFeedback) dto.FeedbackResponse {
+	issues := []string{}
+	if feedback.Issues != "" {
+		issues = strings.Split(feedback.Issues, ",")
+	}
+
+	return dto.FeedbackResponse{
+		ID:        feedback.ID,
+		SummaryID: feedback.SummaryID,
+		Vote:      feedback.Vote,
+		Rating:    feedback.Rating,
+		Comment:   feedback.Comment,
+		Issues:    issues,
+		CreatedAt: feedback.CreatedAt,
+	}
+}
+
+// ConvertFlashcardToResponse converts Flashcard model to FlashcardResponse DTO
+func ConvertFlashcardToResponse(card models.Flashcard) dto.FlashcardResponse {
+	return dto.FlashcardResponse{
+		ID:            card.ID,
+		PDFID:         card.PDFID,
+		SummaryID:     card.SummaryID,
+		Question:      card.Question,
+		Answer:        card.Answer,
+		SourcePage:    card.SourcePage,
+		Language:      card.Language,
+		ModelName:     card.ModelName,
+		PromptVersion: card.PromptVersion,
+		CreatedAt:     card.CreatedAt,
+		UpdatedAt:     card.UpdatedAt,
+	}
+}
+
+func ConvertFlashcardsToResponse(cards []models.Flashcard) []dto.FlashcardResponse {
+	responses := make([]dto.FlashcardResponse, len(cards))
+	for i, card := range cards {
+		responses[i] = ConvertFlashcardToResponse(card)
+	}
+	return responses
+}
+
+// ConvertStudyReviewToResponse converts StudyReview model to StudyReviewResponse DTO
+func ConvertStudyReviewToResponse(review models.StudyReview) dto.StudyReviewResponse {
+	return dto.StudyReviewResponse{
+		ID:           review.ID,
+		StudyItemID:  review.StudyItemID,
+		Grade:        review.Grade,
+		EaseFactor:   review.EaseFactor,
+		IntervalDays: review.IntervalDays,
+		DueAt:        review.DueAt,
+		ReviewedAt:   review.CreatedAt,
+	}
+}
+
+// ConvertStudyItemRowToResponse converts a StudyItemRow to StudyItemResponse DTO
+func ConvertStudyItemRowToResponse(row StudyItemRow) dto.StudyItemResponse {
+	response := dto.StudyItemResponse{
+		ID:             row.ID,
+		PDFID:          row.PDFID,
+		PDFTitle:       row.PDFTitle,
+		SummaryID:      row.SummaryID,
+		Position:       row.Position,
+		Content:        row.Content,
+		New:            row.ScheduleID == nil,
+		EaseFactor:     DefaultEaseFactor,
+		DueAt:          row.DueAt,
+		LastReviewedAt: row.LastReviewedAt,
+	}
+	if row.EaseFactor != nil {
+		response.EaseFactor = *row.EaseFactor
+	}
+	if row.IntervalDays != nil {
+		response.IntervalDays = *row.IntervalDays
+	}
+	if row.Repetitions != nil {
+		response.Repetitions = *row.Repetitions
+	}
+	return response
+}
+
+// ConvertQuizToResponse converts Quiz model to QuizResponse DTO.
+// Correct answers are only included when includeAnswers is set.
+func ConvertQuizToResponse(quiz models.Quiz, includeAnswers bool) dto.QuizResponse {
+	response := dto.QuizResponse{
+		ID:            quiz.ID,
+		PDFID:         quiz.PDFID,
+		Title:         quiz.Title,
+		Language:      quiz.Language,
+		ModelName:     quiz.ModelName,
+		PromptVersion: quiz.PromptVersion,
+		QuestionCount: len(quiz.Questions),
+		CreatedAt:     quiz.CreatedAt,
+	}
+
+	for _, question := range quiz.Questions {
+		options := question.Options
+		if options == nil {
+			options = []string{}
+		}
+		q := dto.QuizQuestionResponse{
+			ID:         question.ID,
+			Position:   question.Position,
+			Type:       question.Type,
+			Question:   question.Question,
+			Options:    options,
+			SourcePage: question.SourcePage,
+		}
+		if includeAnswers {
+			q.CorrectOption = question.CorrectOption
+			q.Answer = question.Answer
+			q.Explanation = question.Explanation
+		}
+		response.Questions = append(response.Questions, q)
+	}
+
+	return response
+}
+
+// ConvertQuizAttemptToResponse converts QuizAttempt model to QuizAttemptResponse DTO,
+// revealing the correct answer of each graded question
+func ConvertQuizAttemptToResponse(attempt models.QuizAttempt, questions map[uint]models.QuizQuestion) dto.QuizAttemptResponse {
+	response := dto.QuizAttemptResponse{
+		ID:           attempt.ID,
+		QuizID:       attempt.QuizID,
+		UserID:       attempt.UserID,
+		CorrectCount: attempt.CorrectCount,
+		Total:        attempt.Total,
+		Score:        attempt.Score,
+		Answers:      make([]dto.QuizAnswerResult, len(attempt.Answers)),
+		CreatedAt:    attempt.CreatedAt,
+	}
+
+	for i, answer := range attempt.Answers {
+		question := questions[answer.QuestionID]
+		response.Answers[i] = dto.QuizAnswerResult{
+			QuestionID:     answer.QuestionID,
+			SelectedOption: answer.SelectedOption,
+			Response:       answer.Response,
+			Correct:        answer.Correct,
+			Similarity:     answer.Similarity,
+			CorrectOption:  question.CorrectOption,
+			Answer:         question.Answer,
+			Explanation:    question.Explanation,
+		}
+	}
+
+	return response
+}
+
+// ConvertGlossaryTermToResponse converts GlossaryTerm model to GlossaryTermResponse DTO
+func ConvertGlossaryTermToResponse(term models.GlossaryTerm) dto.GlossaryTermResponse {
+	response := dto.GlossaryTermResponse{
+		ID:         term.ID,
+		PDFID:      term.PDFID,
+		Term:       term.Term,
+		Definition: term.Definition,
+		Page:       term.Page,
+		Language:   term.Language,
+		ModelName:  term.ModelName,
+		CreatedAt:  term.CreatedAt,
+		UpdatedAt:  term.UpdatedAt,
+	}
+
+	// Include PDF basic info if available
+	if term.PDF.ID != 0 {
+		info := ConvertPDFToBasicInfo(term.PDF)
+		response.PDF = &info
+	}
+
+	return response
+}
+
+func ConvertGlossaryTermsToResponse(terms []models.GlossaryTerm) []dto.GlossaryTermResponse {
+	responses := make([]dto.GlossaryTermResponse, len(terms))
+	for i, term := range terms {
+		responses[i] = ConvertGlossaryTermToResponse(term)
+	}
+	return responses
+}
+
+// ConvertOutlineToResponse converts DocumentOutline model to OutlineResponse DTO
+func ConvertOutlineToResponse(outline models.DocumentOutline) dto.OutlineResponse {
+	return dto.OutlineResponse{
+		ID:        outline.ID,
+		PDFID:     outline.PDFID,
+		Source:    outline.Source,
+		Sections:  convertOutlineSections(outline.Sections),
+		Language:  outline.Language,
+		ModelName: outline.ModelName,
+		CreatedAt: outline.CreatedAt,
+		UpdatedAt: outline.UpdatedAt,
+	}
+}
+
+func convertOutlineSections(sections []models.OutlineSection) []dto.OutlineSection {
+	responses := make([]dto.OutlineSection, len(sections))
+	for i, section := range sections {
+		responses[i] = dto.OutlineSection{
+			Heading:  section.Heading,
+			Level:    section.Level,
+			Page:     section.Page,
+			Gist:     section.Gist,
+			Children: convertOutlineSections(section.Children),
+		}
+	}
+	return responses
+}
+
+// ConvertPythonOutlineSections converts outline sections returned by the Python API to models
+func ConvertPythonOutlineSections(sections []dto.OutlineSection) []models.OutlineSection {
+	result := make([]models.OutlineSection, len(sections))
+	for i, section := range sections {
+		result[i] = models.OutlineSection{
+			Heading:  section.Heading,
+			Level:    section.Level,
+			Page:     section.Page,
+			Gist:     section.Gist,
+			Children: ConvertPythonOutlineSections(section.Children),
+		}
+	}
+	return result
+}
+
+// ConvertTopicToResponse converts Topic model to TopicResponse DTO
+func ConvertTopicToResponse(topic models.Topic) dto.TopicResponse {
+	members := make([]dto.TopicMemberResponse, 0, len(topic.Members))
+	for _, member := range topic.Members {
+		members = append(members, dto.TopicMemberResponse{
+			PDF:   ConvertPDFToBasicInfo(member.PDF),
+			Score: member.Score,
+		})
+	}
+
+	return dto.TopicResponse{
+		ID:          topic.ID,
+		Label:       topic.Label,
+		Description: topic.Description,
+		Size:        topic.Size,
+		ModelName:   topic.ModelName,
+		CreatedAt:   topic.CreatedAt,
+		Members:     members,
+	}
+}
+
+func ConvertTopicsToResponse(topics []models.Topic) []dto.TopicResponse {
+	responses := make([]dto.TopicResponse, len(topics))
+	for i, topic := range topics {
+		responses[i] = ConvertTopicToResponse(topic)
+	}
+	return responses
+}
@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"backend-go/dto"
+	"regexp"
+	"strings"
+)
+
+// maxDiffEdits bounds the work done by the diff; beyond it the remainder is reported as replaced
+const maxDiffEdits = 2000
+
+// Latin punctuation needs trailing whitespace to end a sentence; CJK punctuation does not
+var sentenceBoundary = regexp.MustCompile(`([.!?]+)\s+|([。！？]+)|\n+`)
+
+// SplitSentences splits text into trimmed sentences, keeping terminal punctuation
+func SplitSentences(text string) []string {
+	marked := sentenceBoundary.ReplaceAllString(text, "$1$2\x00")
+
+	var sentences []string
+	for _, s := range strings.Split(marked, "\x00") {
+		if s = strings.TrimSpace(s); s != "" {
+			sentences = append(sentences, s)
+		}
+	}
+	return sentences
+}
+
+// DiffWords returns a word-level diff of a and b with consecutive edits merged
+func DiffWords(a, b string) ([]dto.DiffSegment, dto.DiffStats) {
+	return diffTokens(strings.Fields(a), strings.Fields(b), " ")
+}
+
+// DiffSentences returns a sentence-level diff of a and b suitable for side-by-side rendering
+func DiffSentences(a, b string) ([]dto.DiffSegment, dto.DiffStats) {
+	return diffTokens(SplitSentences(a), SplitSentences(b), "\n")
+}
+
+type diffEdit struct {
+	op    byte // '=', '-' or '+'
+	token string
+}
+
+func diffTokens(a, b []string, sep string) ([]dto.DiffSegment, dto.DiffStats) {
+	edits := myersDiff(a, b)
+
+	var stats dto.DiffStats
+	var segments []dto.DiffSegment
+	var removed, added []string
+
+	flush := func() {
+		switch {
+		case len(removed) > 0 && len(added) > 0:
+			segments = append(segments, dto.DiffSegment{Op: "replace", A: strings.Join(removed, sep), B: strings.Join(added, sep)})
+		case len(removed) > 0:
+			segments = append(segments, dto.DiffSegment{Op: "delete", A: strings.Join(removed, sep)})
+		case len(added) > 0:
+			segments = append(segments, dto.DiffSegment{Op: "insert", B: strings.Join(added, sep)})
+		}
+		removed, added = nil, nil
+	}
+
+	for _, e := range edits {
+		switch e.op {
+		case '-':
+			stats.Removed++
+			removed = append(removed, e.token)
+		case '+':
+			stats.Added++
+			added = append(added, e.token)
+		default:
+			stats.Unchanged++
+			flush()
+			if n := len(segments); n > 0 && segments[n-1].Op == "equal" {
+				segments[n-1].A += sep + e.token
+				segments[n-1].B = segments[n-1].A
+			} else {
+				segments = append(segments, dto.DiffSegment{Op: "equal", A: e.token, B: e.token})
+			}
+		}
+	}
+	flush()
+
+	return segments, stats
+}
+
+// myersDiff computes a shortest edit script between a and b (Myers, 1986)
+func myersDiff(a, b []string) []diffEdit {
+	// Strip common prefix and suffix to keep the search small
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var edits []diffEdit
+	for _, t := range a[:prefix] {
+		edits = append(edits, diffEdit{'=', t})
+	}
+	edits = append(edits, myersMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, t := range a[len(a)-suffix:] {
+		edits = append(edits, diffEdit{'=', t})
+	}
+	return edits
+}
+
+func myersMiddle(a, b []string) []diffEdit {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return replaceAll(a, b)
+	}
+
+	limit := n + m
+	if limit > maxDiffEdits {
+		limit = maxDiffEdits
+	}
+
+	// v[offset+k] is the furthest x reached on diagonal k.
+	// trace[d] snapshots v before round d for k in [-d-1, d+1], stored at index k+d+1.
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+	found := false
+
+	for d := 0; d <= limit && !found; d++ {
+		snapshot := make([]int, 2*d+3)
+		copy(snapshot, v[offset-d-1:offset+d+2])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	if !found {
+		return replaceAll(a, b)
+	}
+
+	// Walk the trace backwards to recover the edit script
+	var reversed []diffEdit
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[k-1+d+1] < v[k+1+d+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[prevK+d+1]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, diffEdit{'=', a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			reversed = append(reversed, diffEdit{'+', b[y-1]})
+		} else {
+			reversed = append(reversed, diffEdit{'-', a[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		reversed = append(reversed, diffEdit{'=', a[x-1]})
+		x--
+		y--
+	}
+
+	edits := make([]diffEdit, len(reversed))
+	for i, e := range reversed {
+		edits[len(reversed)-1-i] = e
+	}
+	return edits
+}
+
+func replaceAll(a, b []string) []diffEdit {
+	edits := make([]diffEdit, 0, len(a)+len(b))
+	for _, t := range a {
+		edits = append(edits, diffEdit{'-', t})
+	}
+	for _, t := range b {
+		edits = append(edits, diffEdit{'+', t})
+	}
+	return edits
+}
@@ -0,0 +1,60 @@
+//go:build linux || darwin
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+	"time"
+)
+
+// watchDisconnect calls cancel if the peer of conn closes the connection, until stop is called.
+// The socket is peeked without consuming data, so a pipelined next request is left in place.
+func watchDisconnect(conn net.Conn, cancel context.CancelFunc) (stop func()) {
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return func() {}
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(disconnectPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if peerClosed(raw) {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+// peerClosed reports whether the peer has closed or reset the connection
+func peerClosed(raw syscall.RawConn) bool {
+	closed := false
+	buf := make([]byte, 1)
+	raw.Read(func(fd uintptr) bool {
+		n, _, err := syscall.Recvfrom(int(fd), buf, syscall.MSG_PEEK|syscall.MSG_DONTWAIT)
+		closed = (n == 0 && err == nil) || errors.Is(err, syscall.ECONNRESET)
+		return true
+	})
+	return closed
+}
@@ -0,0 +1,14 @@
+//go:build !linux && !darwin
+
+package utils
+
+import (
+	"context"
+	"net"
+)
+
+// watchDisconnect does not detect disconnects on this platform; contexts are only
+// cancelled by their timeouts
+func watchDisconnect(conn net.Conn, cancel context.CancelFunc) (stop func()) {
+	return func() {}
+}
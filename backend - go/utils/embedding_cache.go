@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"backend-go/config"
+	"backend-go/models"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pgvector/pgvector-go"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// embeddingCache is used by GenerateEmbedding; by default it keeps embeddings in memory only
+var embeddingCache = NewEmbeddingCache(config.Default().Embeddings.Model, config.Default().Embeddings.CacheSize, nil)
+
+// SetEmbeddingCache replaces the cache used by GenerateEmbedding
+func SetEmbeddingCache(cache *EmbeddingCache) {
+	embeddingCache = cache
+}
+
+// GetEmbeddingCache returns the cache used by GenerateEmbedding
+func GetEmbeddingCache() *EmbeddingCache {
+	return embeddingCache
+}
+
+// NormalizeEmbeddingText trims text and collapses runs of whitespace, so that texts differing
+// only in spacing share one embedding
+func NormalizeEmbeddingText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// EmbeddingCacheStats reports how well the embedding cache is doing, as shown by /health
+type EmbeddingCacheStats struct {
+	Model        string  `json:"model"`
+	Entries      int     `json:"entries"`
+	Capacity     int     `json:"capacity"`
+	Persistent   bool    `json:"persistent"`
+	MemoryHits   int64   `json:"memory_hits"`
+	DatabaseHits int64   `json:"database_hits"`
+	Misses       int64   `json:"misses"`
+	HitRate      float64 `json:"hit_rate"` // Share of lookups answered from the cache; 0 before the first one
+}
+
+// EmbeddingCache remembers embeddings by model and a hash of the normalized text. The most
+// recently used entries are kept in memory; with a database, every embedding is also stored
+// in the embedding_cache table so that it survives restarts and is shared between instances.
+type EmbeddingCache struct {
+	model    string
+	capacity int
+	db       *gorm.DB
+
+	mu           sync.Mutex
+	entries      map[string]*list.Element
+	order        *list.List // Front is the most recently used
+	memoryHits   int64
+	databaseHits int64
+	misses       int64
+}
+
+type embeddingCacheEntry struct {
+	hash      string
+	embedding []float32
+}
+
+// NewEmbeddingCache returns a cache for embeddings of model holding up to capacity entries
+// in memory. db may be nil to keep embeddings in memory only.
+func NewEmbeddingCache(model string, capacity int, db *gorm.DB) *EmbeddingCache {
+	return &EmbeddingCache{
+		model:    model,
+		capacity: capacity,
+		db:       db,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// embeddingHash returns the cache key of normalized text
+func embeddingHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the cached embedding of normalized text. Database errors are logged and
+// reported as a miss, since the embedding can always be generated again.
+func (c *EmbeddingCache) Get(ctx context.Context, text string) ([]float32, bool) {
+	hash := embeddingHash(text)
+
+	c.mu.Lock()
+	if element, ok := c.entries[hash]; ok {
+		c.order.MoveToFront(element)
+		c.memoryHits++
+		embedding := element.Value.(*embeddingCacheEntry).embedding
+		c.mu.Unlock()
+		return append([]float32(nil), embedding...), true
+	}
+	c.mu.Unlock()
+
+	if c.db != nil {
+		var row models.EmbeddingCache
+		err := c.db.WithContext(ctx).Where("model = ? AND text_hash = ?", c.model, hash).Take(&row).Error
+		if err == nil {
+			embedding := row.Embedding.Slice()
+			c.mu.Lock()
+			c.databaseHits++
+			c.remember(hash, embedding)
+			c.mu.Unlock()
+			return append([]float32(nil), embedding...), true
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			fmt.Printf("Warning: Failed to read embedding cache: %v\n", err)
+		}
+	}
+
+	c.mu.Lock()
+	c.misses++
+	c.mu.Unlock()
+	return nil, false
+}
+
+// Put stores the embedding of normalized text
+func (c *EmbeddingCache) Put(ctx context.Context, text string, embedding []float32) {
+	hash := embeddingHash(text)
+	embedding = append([]float32(nil), embedding...)
+
+	c.mu.Lock()
+	c.remember(hash, embedding)
+	c.mu.Unlock()
+
+	if c.db != nil {
+		row := models.EmbeddingCache{Model: c.model, TextHash: hash, Embedding: pgvector.NewVector(embedding)}
+		if err := c.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&row).Error; err != nil {
+			fmt.Printf("Warning: Failed to write embedding cache: %v\n", err)
+		}
+	}
+}
+
+// remember adds an entry to the in-memory LRU, evicting the least recently used one when
+// full; c.mu must be held
+func (c *EmbeddingCache) remember(hash string, embedding []float32) {
+	if c.capacity <= 0 {
+		return
+	}
+	if element, ok := c.entries[hash]; ok {
+		element.Value.(*embeddingCacheEntry).embedding = embedding
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[hash] = c.order.PushFront(&embeddingCacheEntry{hash: hash, embedding: embedding})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*embeddingCacheEntry).hash)
+	}
+}
+
+// Stats returns the hit and miss counts since the cache was created
+func (c *EmbeddingCache) Stats() EmbeddingCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := EmbeddingCacheStats{
+		Model:        c.model,
+		Entries:      c.order.Len(),
+		Capacity:     c.capacity,
+		Persistent:   c.db != nil,
+		MemoryHits:   c.memoryHits,
+		DatabaseHits: c.databaseHits,
+		Misses:       c.misses,
+	}
+	if lookups := c.memoryHits + c.databaseHits + c.misses; lookups > 0 {
+		stats.HitRate = float64(c.memoryHits+c.databaseHits) / float64(lookups)
+	}
+	return stats
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestEmbeddingCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	cache := NewEmbeddingCache("test-model", 2, nil)
+
+	cache.Put(ctx, "first", []float32{1})
+	cache.Put(ctx, "second", []float32{2})
+	if _, ok := cache.Get(ctx, "first"); !ok {
+		t.Fatal("expected first to be cached")
+	}
+	cache.Put(ctx, "third", []float32{3})
+
+	if _, ok := cache.Get(ctx, "second"); ok {
+		t.Error("expected second, the least recently used entry, to be evicted")
+	}
+	for _, text := range []string{"first", "third"} {
+		if _, ok := cache.Get(ctx, text); !ok {
+			t.Errorf("expected %s to be cached", text)
+		}
+	}
+
+	stats := cache.Stats()
+	if stats.Entries != 2 || stats.MemoryHits != 3 || stats.Misses != 1 || stats.HitRate != 0.75 {
+		t.Errorf("stats = %+v, want 2 entries, 3 hits, 1 miss and a 0.75 hit rate", stats)
+	}
+}
+
+func TestEmbeddingCacheReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	cache := NewEmbeddingCache("test-model", 10, nil)
+
+	embedding := []float32{1, 2}
+	cache.Put(ctx, "text", embedding)
+	embedding[0] = 9
+	got, _ := cache.Get(ctx, "text")
+	got[1] = 9
+
+	if again, _ := cache.Get(ctx, "text"); again[0] != 1 || again[1] != 2 {
+		t.Errorf("cached embedding = %v, want it unaffected by callers", again)
+	}
+}
+
+func TestGenerateEmbeddingUsesCache(t *testing.T) {
+	calls := scriptedServer(t, func(n int, w http.ResponseWriter) {
+		w.Write([]byte(`{"embedding":[0.1,0.2]}`))
+	})
+	previous := embeddingCache
+	SetEmbeddingCache(NewEmbeddingCache("test-model", 10, nil))
+	t.Cleanup(func() { SetEmbeddingCache(previous) })
+
+	for _, text := range []string{"What is it about?", "  What is\tit about? ", "Something else"} {
+		if _, err := GenerateEmbedding(context.Background(), text); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if calls.Load() != 2 {
+		t.Errorf("embedding calls = %d, want 2; text differing only in whitespace should be cached", calls.Load())
+	}
+	if stats := GetEmbeddingCache().Stats(); stats.MemoryHits != 1 || stats.Misses != 2 {
+		t.Errorf("stats = %+v, want 1 hit and 2 misses", stats)
+	}
+}
@@ -0,0 +1,239 @@
+package utils
+
+import (
+	"archive/zip"
+	"backend-go/dto"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+// ExportFormat describes a downloadable export format
+type ExportFormat struct {
+	ContentType string
+	Extension   string
+}
+
+// ExportFormats lists the supported export formats by name
+var ExportFormats = map[string]ExportFormat{
+	"md":   {ContentType: "text/markdown; charset=utf-8", Extension: ".md"},
+	"html": {ContentType: "text/html; charset=utf-8", Extension: ".html"},
+	"docx": {ContentType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", Extension: ".docx"},
+	"json": {ContentType: "application/json", Extension: ".json"},
+}
+
+// RenderExport renders an export document in the requested format
+func RenderExport(doc dto.ExportDocument, format string) ([]byte, error) {
+	switch format {
+	case "md":
+		return renderMarkdown(doc), nil
+	case "html":
+		return renderHTML(doc)
+	case "docx":
+		return renderDOCX(doc)
+	case "json":
+		return json.MarshalIndent(doc, "", "  ")
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+var unsafeFilenameChars = regexp.MustCompile(`[^\w\-. ]+`)
+
+// ExportFilename builds a safe attachment filename from a title
+func ExportFilename(title, extension string) string {
+	name := strings.TrimSpace(unsafeFilenameChars.ReplaceAllString(title, "_"))
+	if name == "" {
+		name = "export"
+	}
+	return name + extension
+}
+
+// summaryHeading describes a summary in headings, e.g. "Detailed summary (en)"
+func summaryHeading(summary dto.SummaryResponse) string {
+	style := summary.Style
+	if style != "" {
+		style = strings.ToUpper(style[:1]) + style[1:]
+	}
+	return fmt.Sprintf("%s summary (%s)", style, summary.Language)
+}
+
+// summarySources lists the provenance lines shown under each summary
+func summarySources(doc dto.ExportDocument, summary dto.SummaryResponse) []string {
+	sources := []string{
+		fmt.Sprintf("Source: %s (%s, %d pages)", doc.PDF.Title, doc.PDF.Filename, doc.PDF.PageCount),
+		fmt.Sprintf("Generated: %s", summary.CreatedAt.Format("2006-01-02 15:04")),
+	}
+	if summary.ModelName != "" {
+		sources = append(sources, fmt.Sprintf("Model: %s (prompt %s)", summary.ModelName, summary.PromptVersion))
+	}
+	if summary.SourceSummaryID != nil {
+		sources = append(sources, fmt.Sprintf("Translated from summary #%d", *summary.SourceSummaryID))
+	}
+	if summary.EditedAt != nil {
+		sources = append(sources, fmt.Sprintf("Edited by %s on %s", summary.EditedBy, summary.EditedAt.Format("2006-01-02 15:04")))
+	}
+	return sources
+}
+
+func renderMarkdown(doc dto.ExportDocument) []byte {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", doc.Title)
+	fmt.Fprintf(&b, "- **File:** %s\n", doc.PDF.Filename)
+	fmt.Fprintf(&b, "- **Pages:** %d\n", doc.PDF.PageCount)
+	fmt.Fprintf(&b, "- **Exported:** %s\n", doc.ExportedAt.Format("2006-01-02 15:04"))
+
+	for _, summary := range doc.Summaries {
+		fmt.Fprintf(&b, "\n## %s\n\n", summaryHeading(summary))
+		b.WriteString(strings.TrimSpace(summary.Content))
+		b.WriteString("\n\n")
+		for _, source := range summarySources(doc, summary) {
+			fmt.Fprintf(&b, "> %s  \n", source)
+		}
+	}
+
+	return []byte(b.String())
+}
+
+var htmlExportTemplate = template.Must(template.New("export").Funcs(template.FuncMap{
+	"heading": summaryHeading,
+	"paragraphs": func(content string) []string {
+		return strings.Split(strings.TrimSpace(content), "\n\n")
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Doc.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 48rem; margin: 2rem auto; line-height: 1.5; }
+p { white-space: pre-wrap; }
+.meta, .sources { color: #666; font-size: 0.9rem; }
+</style>
+</head>
+<body>
+<h1>{{.Doc.Title}}</h1>
+<p class="meta">{{.Doc.PDF.Filename}} &middot; {{.Doc.PDF.PageCount}} pages &middot; exported {{.Doc.ExportedAt.Format "2006-01-02 15:04"}}</p>
+{{range .Sections}}
+<section>
+<h2>{{heading .Summary}}</h2>
+{{range paragraphs .Summary.Content}}<p>{{.}}</p>
+{{end}}
+<ul class="sources">{{range .Sources}}<li>{{.}}</li>{{end}}</ul>
+</section>
+{{end}}
+</body>
+</html>
+`))
+
+func renderHTML(doc dto.ExportDocument) ([]byte, error) {
+	type section struct {
+		Summary dto.SummaryResponse
+		Sources []string
+	}
+
+	data := struct {
+		Doc      dto.ExportDocument
+		Sections []section
+	}{Doc: doc}
+	for _, summary := range doc.Summaries {
+		data.Sections = append(data.Sections, section{Summary: summary, Sources: summarySources(doc, summary)})
+	}
+
+	var buf bytes.Buffer
+	if err := htmlExportTemplate.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render HTML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>
+</Types>`
+
+const docxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`
+
+const docxDocumentRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+
+const docxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>
+<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:rPr><w:b/><w:sz w:val="48"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/><w:rPr><w:b/><w:sz w:val="32"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Caption"><w:name w:val="caption"/><w:basedOn w:val="Normal"/><w:rPr><w:i/><w:color w:val="666666"/><w:sz w:val="18"/></w:rPr></w:style>
+</w:styles>`
+
+// docxParagraph renders one WordprocessingML paragraph with an optional style
+func docxParagraph(text, style string) string {
+	var escaped bytes.Buffer
+	xml.EscapeText(&escaped, []byte(text))
+
+	var b strings.Builder
+	b.WriteString("<w:p>")
+	if style != "" {
+		fmt.Fprintf(&b, `<w:pPr><w:pStyle w:val="%s"/></w:pPr>`, style)
+	}
+	fmt.Fprintf(&b, `<w:r><w:t xml:space="preserve">%s</w:t></w:r></w:p>`, escaped.String())
+	return b.String()
+}
+
+func renderDOCX(doc dto.ExportDocument) ([]byte, error) {
+	var body strings.Builder
+	body.WriteString(docxParagraph(doc.Title, "Title"))
+	body.WriteString(docxParagraph(fmt.Sprintf("%s · %d pages · exported %s", doc.PDF.Filename, doc.PDF.PageCount, doc.ExportedAt.Format("2006-01-02 15:04")), "Caption"))
+
+	for _, summary := range doc.Summaries {
+		body.WriteString(docxParagraph(summaryHeading(summary), "Heading1"))
+		for _, line := range strings.Split(strings.TrimSpace(summary.Content), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				body.WriteString(docxParagraph(line, ""))
+			}
+		}
+		for _, source := range summarySources(doc, summary) {
+			body.WriteString(docxParagraph(source, "Caption"))
+		}
+	}
+
+	document := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		body.String() + `</w:body></w:document>`
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", docxContentTypes},
+		{"_rels/.rels", docxRels},
+		{"word/_rels/document.xml.rels", docxDocumentRels},
+		{"word/styles.xml", docxStyles},
+		{"word/document.xml", document},
+	}
+	for _, part := range parts {
+		w, err := zw.Create(part.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", part.name, err)
+		}
+		if _, err := w.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", part.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish DOCX: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"backend-go/dto"
+	"backend-go/models"
+	"context"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// ErrNoFlashcards is returned when the Python backend generates no usable flashcards
+var ErrNoFlashcards = errors.New("no flashcards were generated")
+
+// GenerateFlashcards asks the Python backend for flashcards from a PDF file, or from text
+// when file is nil, and returns them as unsaved models linked to the PDF and summary
+func GenerateFlashcards(ctx context.Context, pdfID uint, summaryID *uint, filename string, file io.Reader, text string, language Language, count int) ([]models.Flashcard, error) {
+	fields := map[string]string{
+		"count":                strconv.Itoa(count),
+		"language":             language.Code,
+		"language_name":        language.Name,
+		"language_instruction": language.Instruction,
+	}
+	if file == nil {
+		fields["text"] = text
+	}
+
+	var result dto.PythonFlashcardsResponse
+	if err := PostFileToPythonAPI(ctx, "/flashcards", filename, file, fields, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Flashcards) == 0 {
+		return nil, ErrNoFlashcards
+	}
+
+	cards := make([]models.Flashcard, len(result.Flashcards))
+	for i, card := range result.Flashcards {
+		cards[i] = models.Flashcard{
+			PDFID:         pdfID,
+			SummaryID:     summaryID,
+			Question:      card.Question,
+			Answer:        card.Answer,
+			SourcePage:    card.Page,
+			Language:      language.Code,
+			ModelName:     result.Model,
+			PromptVersion: result.PromptVersion,
+		}
+	}
+	return cards, nil
+}
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Identity is the caller and tenant a request acts on behalf of
+type Identity struct {
+	UserID      string
+	WorkspaceID string
+}
+
+// Defaults used when a request carries no identity
+const (
+	AnonymousUserID    = "anonymous"
+	DefaultWorkspaceID = "default"
+)
+
+// IdentityResolver determines the identity of a request. The backend has no accounts
+// yet, so the default resolver trusts headers; a real authentication scheme can be
+// plugged in by implementing this interface.
+type IdentityResolver interface {
+	Resolve(c *fiber.Ctx) (Identity, error)
+}
+
+// HeaderIdentityResolver reads the identity from trusted request headers
+type HeaderIdentityResolver struct {
+	UserHeader      string // Defaults to X-User-ID
+	WorkspaceHeader string // Defaults to X-Workspace-ID
+}
+
+func (r HeaderIdentityResolver) Resolve(c *fiber.Ctx) (Identity, error) {
+	userHeader := r.UserHeader
+	if userHeader == "" {
+		userHeader = "X-User-ID"
+	}
+	workspaceHeader := r.WorkspaceHeader
+	if workspaceHeader == "" {
+		workspaceHeader = "X-Workspace-ID"
+	}
+
+	identity := Identity{
+		UserID:      strings.TrimSpace(c.Get(userHeader)),
+		WorkspaceID: strings.TrimSpace(c.Get(workspaceHeader)),
+	}
+	if identity.UserID == "" {
+		identity.UserID = AnonymousUserID
+	}
+	if identity.WorkspaceID == "" {
+		identity.WorkspaceID = DefaultWorkspaceID
+	}
+
+	if len(identity.UserID) > 128 {
+		return Identity{}, fmt.Errorf("%s cannot exceed 128 characters", userHeader)
+	}
+	if len(identity.WorkspaceID) > 128 {
+		return Identity{}, fmt.Errorf("%s cannot exceed 128 characters", workspaceHeader)
+	}
+
+	return identity, nil
+}
+
+const identityKey = "identity"
+
+// IdentityMiddleware resolves the request identity and stores it for GetIdentity
+func IdentityMiddleware(resolver IdentityResolver) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		identity, err := resolver.Resolve(c)
+		if err != nil {
+			return c.Status(401).JSON(fiber.Map{
+				"error":   "unauthorized",
+				"message": err.Error(),
+			})
+		}
+
+		c.Locals(identityKey, identity)
+		return c.Next()
+	}
+}
+
+// GetIdentity returns the identity stored by IdentityMiddleware
+func GetIdentity(c *fiber.Ctx) Identity {
+	if identity, ok := c.Locals(identityKey).(Identity); ok {
+		return identity
+	}
+	return Identity{UserID: AnonymousUserID, WorkspaceID: DefaultWorkspaceID}
+}
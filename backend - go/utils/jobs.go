@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Jobs tracks background goroutines, such as request log writes, so that a stopping server
+// can wait for them to finish before it closes the database
+type Jobs struct {
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+	stopping bool
+}
+
+// Go runs fn in a tracked goroutine. Once Wait has been called no new jobs are started and
+// Go reports false.
+func (j *Jobs) Go(name string, fn func()) bool {
+	j.mu.Lock()
+	if j.stopping {
+		j.mu.Unlock()
+		fmt.Printf("Warning: Skipping %s, the server is shutting down\n", name)
+		return false
+	}
+	j.wg.Add(1)
+	j.mu.Unlock()
+
+	go func() {
+		defer j.wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				fmt.Printf("Recovered from panic in %s: %v\n", name, r)
+			}
+		}()
+		fn()
+	}()
+	return true
+}
+
+// Wait stops new jobs from starting and waits for the running ones, or until ctx is done
+func (j *Jobs) Wait(ctx context.Context) error {
+	j.mu.Lock()
+	j.stopping = true
+	j.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		j.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("background jobs still running: %w", ctx.Err())
+	}
+}
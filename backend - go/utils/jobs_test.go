@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJobsWaitDrainsRunningJobs(t *testing.T) {
+	var jobs Jobs
+	var finished atomic.Int32
+	release := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		jobs.Go("test job", func() {
+			<-release
+			finished.Add(1)
+		})
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+	if err := jobs.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if finished.Load() != 3 {
+		t.Errorf("finished jobs = %d, want 3", finished.Load())
+	}
+}
+
+func TestJobsWaitTimesOut(t *testing.T) {
+	var jobs Jobs
+	release := make(chan struct{})
+	defer close(release)
+	jobs.Go("stuck job", func() { <-release })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := jobs.Wait(ctx); err == nil {
+		t.Fatal("expected an error while a job is still running")
+	}
+}
+
+func TestJobsRejectedAfterWait(t *testing.T) {
+	var jobs Jobs
+	if err := jobs.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ran := false
+	if jobs.Go("late job", func() { ran = true }) {
+		t.Error("expected Go to report false once shutdown started")
+	}
+	if ran {
+		t.Error("expected the job not to run")
+	}
+}
+
+func TestJobsRecoverFromPanics(t *testing.T) {
+	var jobs Jobs
+	jobs.Go("panicking job", func() { panic("boom") })
+	if err := jobs.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
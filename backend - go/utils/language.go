@@ -0,0 +1,246 @@
+package utils
+
+import (
+	"backend-go/config"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Language describes a summary language supported by the AI service
+type Language struct {
+	Code        string   `json:"code"`                // ISO 639-1 code, e.g. "en"
+	Name        string   `json:"name"`                // English display name
+	NativeName  string   `json:"native_name"`         // Display name in the language itself
+	Instruction string   `json:"instruction"`         // Prompt instruction sent to the AI service
+	Aliases     []string `json:"aliases,omitempty"`   // Alternative identifiers accepted on input
+	Stopwords   []string `json:"stopwords,omitempty"` // Common words used for language detection
+	Scripts     []string `json:"scripts,omitempty"`   // Unicode scripts used for language detection
+}
+
+// defaultLanguages is used when LANGUAGES_FILE is not set
+var defaultLanguages = []Language{
+	{
+		Code:        "en",
+		Name:        "English",
+		NativeName:  "English",
+		Instruction: "respond in English",
+		Aliases:     []string{"english", "eng"},
+		Stopwords:   []string{"the", "and", "of", "to", "is", "in", "that", "for", "with", "this", "are", "was", "be", "on", "as"},
+	},
+	{
+		Code:        "id",
+		Name:        "Indonesian",
+		NativeName:  "Bahasa Indonesia",
+		Instruction: "respond in Bahasa Indonesia",
+		Aliases:     []string{"indonesian", "ind", "bahasa"},
+		Stopwords:   []string{"yang", "dan", "di", "ini", "dengan", "untuk", "dari", "dalam", "tidak", "adalah", "pada", "ke", "akan", "itu", "juga"},
+	},
+	{
+		Code:        "de",
+		Name:        "German",
+		NativeName:  "Deutsch",
+		Instruction: "respond in German (Deutsch)",
+		Aliases:     []string{"german", "deu", "deutsch"},
+		Stopwords:   []string{"der", "die", "und", "das", "ist", "nicht", "mit", "den", "ein", "eine", "zu", "auf", "für", "sich", "von"},
+	},
+	{
+		Code:        "ja",
+		Name:        "Japanese",
+		NativeName:  "日本語",
+		Instruction: "respond in Japanese (日本語)",
+		Aliases:     []string{"japanese", "jpn"},
+		Scripts:     []string{"Hiragana", "Katakana"},
+	},
+}
+
+var (
+	languageList    []Language
+	languageLookup  map[string]Language
+	defaultLanguage Language
+)
+
+func init() {
+	if err := SetLanguages(config.Default().Languages); err != nil {
+		panic(err)
+	}
+}
+
+// SetLanguages builds the language registry from the languages file of cfg, or the built-in
+// languages when none is set. It fails when the file is invalid or the default language is
+// not registered.
+func SetLanguages(cfg config.Languages) error {
+	languages := defaultLanguages
+	if cfg.File != "" {
+		loaded, err := readLanguagesFile(cfg.File)
+		if err != nil {
+			return fmt.Errorf("languages.file: %w", err)
+		}
+		languages = loaded
+	}
+
+	list := make([]Language, 0, len(languages))
+	lookup := make(map[string]Language)
+	for _, lang := range languages {
+		lang.Code = strings.ToLower(strings.TrimSpace(lang.Code))
+		if lang.Instruction == "" {
+			lang.Instruction = "respond in " + lang.Name
+		}
+		list = append(list, lang)
+
+		lookup[lang.Code] = lang
+		lookup[strings.ToLower(lang.Name)] = lang
+		for _, alias := range lang.Aliases {
+			lookup[strings.ToLower(alias)] = lang
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	def, ok := lookup[strings.ToLower(strings.TrimSpace(cfg.Default))]
+	if !ok {
+		return fmt.Errorf("languages.default: %q is not a registered language", cfg.Default)
+	}
+
+	languageList, languageLookup, defaultLanguage = list, lookup, def
+	return nil
+}
+
+// readLanguagesFile reads a JSON array of languages from disk
+func readLanguagesFile(path string) ([]Language, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var languages []Language
+	if err := json.Unmarshal(data, &languages); err != nil {
+		return nil, fmt.Errorf("invalid languages file: %w", err)
+	}
+	if len(languages) == 0 {
+		return nil, fmt.Errorf("languages file is empty")
+	}
+
+	for i, lang := range languages {
+		if strings.TrimSpace(lang.Code) == "" || strings.TrimSpace(lang.Name) == "" {
+			return nil, fmt.Errorf("language at index %d requires code and name", i)
+		}
+	}
+
+	return languages, nil
+}
+
+// GetLanguages returns all registered languages sorted by name
+func GetLanguages() []Language {
+	return languageList
+}
+
+// GetDefaultLanguage returns the language used when none is requested or detected
+func GetDefaultLanguage() Language {
+	return defaultLanguage
+}
+
+// LookupLanguage finds a language by ISO code, name or alias (case-insensitive)
+func LookupLanguage(language string) (Language, bool) {
+	lang, ok := languageLookup[strings.ToLower(strings.TrimSpace(language))]
+	return lang, ok
+}
+
+// ResolveLanguage resolves a requested language, falling back to the document's detected
+// language and then the default when the request is empty or "auto"
+func ResolveLanguage(requested, detected string) (Language, error) {
+	if requested == "" || strings.EqualFold(requested, "auto") {
+		requested = detected
+		if requested == "" {
+			return GetDefaultLanguage(), nil
+		}
+	}
+
+	if err := ValidateLanguage(requested); err != nil {
+		return Language{}, err
+	}
+	language, _ := LookupLanguage(requested)
+	return language, nil
+}
+
+// DetectLanguage guesses the language of text using the registry's stopwords and scripts.
+// It returns false when no registered language scores high enough.
+func DetectLanguage(text string) (Language, bool) {
+	languages := GetLanguages()
+
+	// Script based detection (e.g. Japanese kana)
+	var letters int
+	scriptCounts := make(map[string]int)
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, lang := range languages {
+			for _, script := range lang.Scripts {
+				if table, ok := unicode.Scripts[script]; ok && unicode.Is(table, r) {
+					scriptCounts[lang.Code]++
+					break
+				}
+			}
+		}
+	}
+
+	if letters == 0 {
+		return Language{}, false
+	}
+
+	var best Language
+	var bestScore float64
+	for _, lang := range languages {
+		score := float64(scriptCounts[lang.Code]) / float64(letters)
+		if score > bestScore {
+			best, bestScore = lang, score
+		}
+	}
+	if bestScore >= 0.1 {
+		return best, true
+	}
+
+	// Stopword based detection for alphabetic languages
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if len(words) == 0 {
+		return Language{}, false
+	}
+
+	bestScore = 0
+	for _, lang := range languages {
+		if len(lang.Stopwords) == 0 {
+			continue
+		}
+		stopwords := make(map[string]bool, len(lang.Stopwords))
+		for _, w := range lang.Stopwords {
+			stopwords[w] = true
+		}
+
+		var hits int
+		for _, w := range words {
+			if stopwords[w] {
+				hits++
+			}
+		}
+
+		score := float64(hits) / float64(len(words))
+		if score > bestScore {
+			best, bestScore = lang, score
+		}
+	}
+
+	if bestScore < 0.05 {
+		return Language{}, false
+	}
+
+	return best, true
+}
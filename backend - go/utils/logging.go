@@ -21,6 +21,7 @@ type LoggingConfig struct {
 	LogResponseBody   bool
 	MaxBodySize       int
 	SensitivePatterns []string
+	Jobs              *Jobs // Tracks database log writes so shutdown can wait for them
 }
 
 // NewLoggingMiddleware creates a new logging middleware with database support
@@ -28,6 +29,9 @@ func NewLoggingMiddleware(config LoggingConfig) fiber.Handler {
 	if config.MaxBodySize == 0 {
 		config.MaxBodySize = 10000 // Default 10KB
 	}
+	if config.Jobs == nil {
+		config.Jobs = &Jobs{}
+	}
 
 	// Compile sensitive data patterns
 	sensitiveRegexes := make([]*regexp.Regexp, 0)
@@ -94,14 +98,8 @@ func NewLoggingMiddleware(config LoggingConfig) fiber.Handler {
 			ipAddress := c.IP()
 			userAgent := c.Get("User-Agent")
 
-			go func() {
-				// Recover from any panics in the goroutine
-				defer func() {
-					if r := recover(); r != nil {
-						fmt.Printf("Recovered from panic in logging goroutine: %v\n", r)
-					}
-				}()
-
+			// Jobs recovers from panics in the write
+			config.Jobs.Go("request log write", func() {
 				// Double check DB is not nil
 				if config.DB == nil {
 					fmt.Println("Database is nil in logging goroutine")
@@ -148,7 +146,7 @@ func NewLoggingMiddleware(config LoggingConfig) fiber.Handler {
 				} else {
 					fmt.Printf("Log saved successfully: %s %s\n", method, path)
 				}
-			}()
+			})
 		}
 
 		return err
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"crypto/subtle"
 	"fmt"
 	"time"
 
@@ -81,3 +82,25 @@ func RateLimitMiddleware() fiber.Handler {
 		return c.Next()
 	}
 }
+
+// AdminAuthMiddleware protects admin endpoints with the configured admin token.
+// Admin endpoints are disabled when the token is empty.
+func AdminAuthMiddleware(token string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if token == "" {
+			return c.Status(403).JSON(fiber.Map{
+				"error":   "forbidden",
+				"message": "Admin endpoints are disabled; set ADMIN_TOKEN to enable them",
+			})
+		}
+
+		if subtle.ConstantTimeCompare([]byte(c.Get("X-Admin-Token")), []byte(token)) != 1 {
+			return c.Status(401).JSON(fiber.Map{
+				"error":   "unauthorized",
+				"message": "Invalid admin token",
+			})
+		}
+
+		return c.Next()
+	}
+}
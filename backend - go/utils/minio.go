@@ -1,10 +1,11 @@
 package utils
 
 import (
+	"backend-go/config"
 	"context"
 	"fmt"
 	"io"
-	"os"
+	"net/http"
 	"time"
 
 	"github.com/minio/minio-go/v7"
@@ -13,41 +14,42 @@ import (
 
 var minioClient *minio.Client
 
-// InitMinIO initializes the MinIO client
-func InitMinIO() error {
-	endpoint := os.Getenv("MINIO_ENDPOINT")
-	accessKey := os.Getenv("MINIO_ACCESS_KEY")
-	secretKey := os.Getenv("MINIO_SECRET_KEY")
-	useSSL := os.Getenv("MINIO_USE_SSL") == "true"
-	bucketName := os.Getenv("MINIO_BUCKET")
+// minioTransport is the HTTP transport of minioClient, kept so CloseMinIO can release its connections
+var minioTransport *http.Transport
 
-	// Default values
-	if endpoint == "" {
-		endpoint = "localhost:9000"
-	}
-	if accessKey == "" {
-		accessKey = "minioadmin"
-	}
-	if secretKey == "" {
-		secretKey = "minioadmin"
-	}
-	if bucketName == "" {
-		bucketName = "pdf-uploads"
+// minioBucket is the bucket uploaded files are stored in
+var minioBucket string
+
+// minioTimeout bounds each MinIO operation
+var minioTimeout time.Duration
+
+// InitMinIO initializes the MinIO client and creates the configured bucket if needed
+func InitMinIO(cfg config.MinIO) error {
+	bucketName := cfg.Bucket
+
+	transport, err := minio.DefaultTransport(cfg.UseSSL)
+	if err != nil {
+		return fmt.Errorf("failed to create MinIO transport: %w", err)
 	}
 
 	// Initialize MinIO client
-	client, err := minio.New(endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
-		Secure: useSSL,
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:     credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure:    cfg.UseSSL,
+		Transport: transport,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create MinIO client: %w", err)
 	}
 
 	minioClient = client
+	minioTransport = transport
+	minioBucket = bucketName
+	minioTimeout = cfg.Timeout
 
 	// Create bucket if it doesn't exist
-	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(context.Background(), minioTimeout)
+	defer cancel()
 	exists, err := client.BucketExists(ctx, bucketName)
 	if err != nil {
 		return fmt.Errorf("failed to check bucket existence: %w", err)
@@ -72,18 +74,14 @@ func GetMinIOClient() *minio.Client {
 }
 
 // UploadToMinIO uploads a file to MinIO
-func UploadToMinIO(filename string, reader io.Reader, size int64, contentType string) error {
+func UploadToMinIO(ctx context.Context, filename string, reader io.Reader, size int64, contentType string) error {
 	if minioClient == nil {
 		return fmt.Errorf("MinIO client not initialized")
 	}
 
-	bucketName := os.Getenv("MINIO_BUCKET")
-	if bucketName == "" {
-		bucketName = "pdf-uploads"
-	}
-
-	ctx := context.Background()
-	_, err := minioClient.PutObject(ctx, bucketName, filename, reader, size, minio.PutObjectOptions{
+	ctx, cancel := context.WithTimeout(ctx, minioTimeout)
+	defer cancel()
+	_, err := minioClient.PutObject(ctx, minioBucket, filename, reader, size, minio.PutObjectOptions{
 		ContentType: contentType,
 	})
 	if err != nil {
@@ -93,39 +91,49 @@ func UploadToMinIO(filename string, reader io.Reader, size int64, contentType st
 	return nil
 }
 
-// DownloadFromMinIO downloads a file from MinIO
-func DownloadFromMinIO(filename string) (*minio.Object, error) {
+// DownloadFromMinIO opens a file in MinIO and returns it with its size. The timeout covers
+// reading the whole file; the caller must close the returned reader.
+func DownloadFromMinIO(ctx context.Context, filename string) (io.ReadCloser, int64, error) {
 	if minioClient == nil {
-		return nil, fmt.Errorf("MinIO client not initialized")
+		return nil, 0, fmt.Errorf("MinIO client not initialized")
 	}
 
-	bucketName := os.Getenv("MINIO_BUCKET")
-	if bucketName == "" {
-		bucketName = "pdf-uploads"
+	ctx, cancel := context.WithTimeout(ctx, minioTimeout)
+	object, err := minioClient.GetObject(ctx, minioBucket, filename, minio.GetObjectOptions{})
+	if err != nil {
+		cancel()
+		return nil, 0, fmt.Errorf("failed to download from MinIO: %w", err)
 	}
-
-	ctx := context.Background()
-	object, err := minioClient.GetObject(ctx, bucketName, filename, minio.GetObjectOptions{})
+	info, err := object.Stat()
 	if err != nil {
-		return nil, fmt.Errorf("failed to download from MinIO: %w", err)
+		object.Close()
+		cancel()
+		return nil, 0, fmt.Errorf("failed to stat object: %w", err)
 	}
 
-	return object, nil
+	return &minioObjectReader{Object: object, cancel: cancel}, info.Size, nil
+}
+
+// minioObjectReader releases the download's context when the object is closed
+type minioObjectReader struct {
+	*minio.Object
+	cancel context.CancelFunc
+}
+
+func (r *minioObjectReader) Close() error {
+	defer r.cancel()
+	return r.Object.Close()
 }
 
 // DeleteFromMinIO deletes a file from MinIO
-func DeleteFromMinIO(filename string) error {
+func DeleteFromMinIO(ctx context.Context, filename string) error {
 	if minioClient == nil {
 		return fmt.Errorf("MinIO client not initialized")
 	}
 
-	bucketName := os.Getenv("MINIO_BUCKET")
-	if bucketName == "" {
-		bucketName = "pdf-uploads"
-	}
-
-	ctx := context.Background()
-	err := minioClient.RemoveObject(ctx, bucketName, filename, minio.RemoveObjectOptions{})
+	ctx, cancel := context.WithTimeout(ctx, minioTimeout)
+	defer cancel()
+	err := minioClient.RemoveObject(ctx, minioBucket, filename, minio.RemoveObjectOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to delete from MinIO: %w", err)
 	}
@@ -134,18 +142,14 @@ func DeleteFromMinIO(filename string) error {
 }
 
 // GetPresignedURL generates a presigned URL for temporary access
-func GetPresignedURL(filename string, expiry time.Duration) (string, error) {
+func GetPresignedURL(ctx context.Context, filename string, expiry time.Duration) (string, error) {
 	if minioClient == nil {
 		return "", fmt.Errorf("MinIO client not initialized")
 	}
 
-	bucketName := os.Getenv("MINIO_BUCKET")
-	if bucketName == "" {
-		bucketName = "pdf-uploads"
-	}
-
-	ctx := context.Background()
-	url, err := minioClient.PresignedGetObject(ctx, bucketName, filename, expiry, nil)
+	ctx, cancel := context.WithTimeout(ctx, minioTimeout)
+	defer cancel()
+	url, err := minioClient.PresignedGetObject(ctx, minioBucket, filename, expiry, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate presigned URL: %w", err)
 	}
@@ -154,18 +158,14 @@ func GetPresignedURL(filename string, expiry time.Duration) (string, error) {
 }
 
 // FileExistsInMinIO checks if a file exists in MinIO
-func FileExistsInMinIO(filename string) (bool, error) {
+func FileExistsInMinIO(ctx context.Context, filename string) (bool, error) {
 	if minioClient == nil {
 		return false, fmt.Errorf("MinIO client not initialized")
 	}
 
-	bucketName := os.Getenv("MINIO_BUCKET")
-	if bucketName == "" {
-		bucketName = "pdf-uploads"
-	}
-
-	ctx := context.Background()
-	_, err := minioClient.StatObject(ctx, bucketName, filename, minio.StatObjectOptions{})
+	ctx, cancel := context.WithTimeout(ctx, minioTimeout)
+	defer cancel()
+	_, err := minioClient.StatObject(ctx, minioBucket, filename, minio.StatObjectOptions{})
 	if err != nil {
 		errResponse := minio.ToErrorResponse(err)
 		if errResponse.Code == "NoSuchKey" {
@@ -177,6 +177,13 @@ func FileExistsInMinIO(filename string) (bool, error) {
 	return true, nil
 }
 
+// CloseMinIO releases the MinIO client's idle connections, for use once requests have stopped
+func CloseMinIO() {
+	if minioTransport != nil {
+		minioTransport.CloseIdleConnections()
+	}
+}
+
 // IsMinIOAvailable checks if MinIO is initialized and available
 func IsMinIOAvailable() bool {
 	return minioClient != nil
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"backend-go/config"
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// Quiz question types
+const (
+	QuestionMultipleChoice = "multiple_choice"
+	QuestionShortAnswer    = "short_answer"
+)
+
+// shortAnswerThreshold is the embedding similarity a short answer needs to be graded correct
+var shortAnswerThreshold = config.Default().Quiz.SimilarityThreshold
+
+// SetQuiz sets how quizzes are graded
+func SetQuiz(cfg config.Quiz) {
+	shortAnswerThreshold = cfg.SimilarityThreshold
+}
+
+// ShortAnswerThreshold returns the configured grading threshold
+func ShortAnswerThreshold() float64 {
+	return shortAnswerThreshold
+}
+
+var answerPunctuation = regexp.MustCompile(`[^\p{L}\p{N}\s]+`)
+
+// NormalizeAnswer lowercases an answer and strips punctuation and extra whitespace
+func NormalizeAnswer(answer string) string {
+	answer = answerPunctuation.ReplaceAllString(strings.ToLower(answer), " ")
+	return strings.Join(strings.Fields(answer), " ")
+}
+
+// GradeShortAnswer grades a free-text answer against the reference answer.
+// Answers that match after normalization are correct without calling the embedding
+// service; otherwise both are embedded and compared by cosine similarity.
+func GradeShortAnswer(ctx context.Context, response, reference string) (bool, *float64, error) {
+	normalized := NormalizeAnswer(response)
+	if normalized == "" {
+		return false, nil, nil
+	}
+	if normalized == NormalizeAnswer(reference) {
+		similarity := 1.0
+		return true, &similarity, nil
+	}
+
+	responseEmbedding, err := GenerateEmbedding(ctx, response)
+	if err != nil {
+		return false, nil, err
+	}
+	referenceEmbedding, err := GenerateEmbedding(ctx, reference)
+	if err != nil {
+		return false, nil, err
+	}
+
+	similarity, ok := CosineSimilarity(pgvector.NewVector(responseEmbedding), pgvector.NewVector(referenceEmbedding))
+	if !ok {
+		return false, nil, nil
+	}
+	return similarity >= ShortAnswerThreshold(), &similarity, nil
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"backend-go/dto"
+	"backend-go/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// DefaultRelatedLimit is how many related PDFs GET /pdf/:id includes
+const DefaultRelatedLimit = 5
+
+// similarPDFsSQL ranks other PDFs by the cosine distance between document vectors,
+// each being the average of the PDF's summary embeddings
+const similarPDFsSQL = `
+WITH docs AS (
+	SELECT s.pdf_id, AVG(s.embedding) AS embedding, COUNT(*) AS summary_count
+	FROM summaries s
+	JOIN pdfs p ON p.id = s.pdf_id AND p.deleted_at IS NULL
+	WHERE s.embedding IS NOT NULL AND s.deleted_at IS NULL
+	GROUP BY s.pdf_id
+)
+SELECT d.pdf_id, 1 - (d.embedding <=> t.embedding) AS score, d.summary_count
+FROM docs d
+JOIN docs t ON t.pdf_id = ?
+WHERE d.pdf_id <> t.pdf_id
+ORDER BY d.embedding <=> t.embedding, d.pdf_id
+LIMIT ?`
+
+// SimilarPDFs returns the PDFs nearest to pdfID with their similarity scores, most similar
+// first. A PDF without summary embeddings has no neighbours, so the result is empty.
+func SimilarPDFs(db *gorm.DB, pdfID uint, limit int) ([]dto.SimilarPDFResponse, error) {
+	var rows []struct {
+		PDFID        uint
+		Score        float64
+		SummaryCount int
+	}
+	if err := db.Raw(similarPDFsSQL, pdfID, limit).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to rank similar PDFs: %w", err)
+	}
+
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.PDFID
+	}
+	var pdfs []models.PDF
+	if len(ids) > 0 {
+		if err := db.Where("id IN ?", ids).Find(&pdfs).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch similar PDFs: %w", err)
+		}
+	}
+	byID := make(map[uint]models.PDF, len(pdfs))
+	for _, pdf := range pdfs {
+		byID[pdf.ID] = pdf
+	}
+
+	results := make([]dto.SimilarPDFResponse, 0, len(rows))
+	for _, row := range rows {
+		pdf, ok := byID[row.PDFID]
+		if !ok {
+			continue
+		}
+		results = append(results, dto.SimilarPDFResponse{
+			PDF:          ConvertPDFToBasicInfo(pdf),
+			Score:        row.Score,
+			SummaryCount: row.SummaryCount,
+		})
+	}
+
+	return results, nil
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// OpenStoredFile opens an uploaded file from MinIO, falling back to local storage.
+// The caller must close the returned reader.
+func OpenStoredFile(ctx context.Context, filename string) (io.ReadCloser, int64, error) {
+	if IsMinIOAvailable() {
+		return DownloadFromMinIO(ctx, filename)
+	}
+
+	file, err := os.Open(filepath.Join("uploads", filename))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open local file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, fmt.Errorf("failed to stat local file: %w", err)
+	}
+	return file, info.Size(), nil
+}
+
+// StoredFileExists reports whether an uploaded file is present in storage
+func StoredFileExists(ctx context.Context, filename string) (bool, error) {
+	if IsMinIOAvailable() {
+		return FileExistsInMinIO(ctx, filename)
+	}
+
+	_, err := os.Stat(filepath.Join("uploads", filename))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// SaveStoredFile writes an uploaded file to MinIO, falling back to local storage
+func SaveStoredFile(ctx context.Context, filename string, reader io.Reader, size int64) error {
+	if IsMinIOAvailable() {
+		return UploadToMinIO(ctx, filename, reader, size, "application/pdf")
+	}
+
+	if err := os.MkdirAll("uploads", os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create upload directory: %w", err)
+	}
+	file, err := os.Create(filepath.Join("uploads", filename))
+	if err != nil {
+		return fmt.Errorf("failed to create local file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, reader); err != nil {
+		return fmt.Errorf("failed to write local file: %w", err)
+	}
+	return nil
+}
+
+// DeleteStoredFile removes an uploaded file from MinIO, falling back to local storage.
+// A missing local file is not an error.
+func DeleteStoredFile(ctx context.Context, filename string) error {
+	if IsMinIOAvailable() {
+		return DeleteFromMinIO(ctx, filename)
+	}
+
+	if err := os.Remove(filepath.Join("uploads", filename)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete local file: %w", err)
+	}
+	return nil
+}
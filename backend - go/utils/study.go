@@ -0,0 +1,234 @@
+package utils
+
+import (
+	"backend-go/models"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SM-2 defaults (Wozniak, 1990)
+const (
+	DefaultEaseFactor = 2.5
+	MinEaseFactor     = 1.3
+	MaxReviewGrade    = 5
+	PassingGrade      = 3
+)
+
+// SM2State is the scheduling state of one study item for one user
+type SM2State struct {
+	EaseFactor   float64
+	IntervalDays int
+	Repetitions  int
+}
+
+// NewSM2State returns the state of an item that has never been reviewed
+func NewSM2State() SM2State {
+	return SM2State{EaseFactor: DefaultEaseFactor}
+}
+
+// ScheduleSM2 applies a review grade (0-5) to state and returns the next state.
+// Failed reviews (grade below 3) restart the repetition sequence.
+func ScheduleSM2(state SM2State, grade int) SM2State {
+	if state.EaseFactor == 0 {
+		state.EaseFactor = DefaultEaseFactor
+	}
+
+	if grade >= PassingGrade {
+		switch state.Repetitions {
+		case 0:
+			state.IntervalDays = 1
+		case 1:
+			state.IntervalDays = 6
+		default:
+			state.IntervalDays = int(math.Round(float64(state.IntervalDays) * state.EaseFactor))
+		}
+		state.Repetitions++
+	} else {
+		state.Repetitions = 0
+		state.IntervalDays = 1
+	}
+
+	miss := float64(MaxReviewGrade - grade)
+	state.EaseFactor += 0.1 - miss*(0.08+miss*0.02)
+	if state.EaseFactor < MinEaseFactor {
+		state.EaseFactor = MinEaseFactor
+	}
+
+	return state
+}
+
+// NextReview returns when an item reviewed at reviewedAt is due again
+func (s SM2State) NextReview(reviewedAt time.Time) time.Time {
+	return reviewedAt.AddDate(0, 0, s.IntervalDays)
+}
+
+// minKeyPointLength is the shortest text (in characters) worth reviewing
+const minKeyPointLength = 20
+
+var (
+	listMarker   = regexp.MustCompile(`^(?:[-*•]|\d+[.)])\s+`)
+	markdownEmph = regexp.MustCompile(`\*\*|__`)
+)
+
+// ExtractKeyPoints splits summary content into reviewable key points.
+// List items become one point each; prose is split into sentences.
+// Headings and fragments too short to review are skipped.
+func ExtractKeyPoints(content string) []string {
+	var points []string
+	seen := make(map[string]bool)
+
+	add := func(point string) {
+		point = strings.TrimSpace(markdownEmph.ReplaceAllString(point, ""))
+		if len([]rune(point)) < minKeyPointLength || strings.HasSuffix(point, ":") || seen[point] {
+			return
+		}
+		seen[point] = true
+		points = append(points, point)
+	}
+
+	var prose []string
+	flushProse := func() {
+		for _, sentence := range SplitSentences(strings.Join(prose, " ")) {
+			add(sentence)
+		}
+		prose = nil
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			flushProse()
+		case listMarker.MatchString(line):
+			flushProse()
+			add(listMarker.ReplaceAllString(line, ""))
+		default:
+			prose = append(prose, line)
+		}
+	}
+	flushProse()
+
+	return points
+}
+
+// PrimarySummaryIDs returns the primary summary (pinned, else latest) of each PDF.
+// An empty pdfIDs selects every PDF; PDFs without summaries are skipped.
+func PrimarySummaryIDs(db *gorm.DB, pdfIDs []uint) ([]uint, error) {
+	query := db.Table("pdfs p").
+		Select(`COALESCE(p.pinned_summary_id, (
+			SELECT s.id FROM summaries s
+			WHERE s.pdf_id = p.id AND s.deleted_at IS NULL
+			ORDER BY s.created_at DESC LIMIT 1
+		)) AS summary_id`).
+		Where("p.deleted_at IS NULL")
+	if len(pdfIDs) > 0 {
+		query = query.Where("p.id IN ?", pdfIDs)
+	}
+
+	var rows []struct {
+		SummaryID *uint
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	ids := []uint{}
+	for _, row := range rows {
+		if row.SummaryID != nil {
+			ids = append(ids, *row.SummaryID)
+		}
+	}
+	return ids, nil
+}
+
+// SyncStudyItems splits summaries that have no study items in the workspace yet into key points
+func SyncStudyItems(db *gorm.DB, workspaceID string, summaryIDs []uint) error {
+	if len(summaryIDs) == 0 {
+		return nil
+	}
+
+	var synced []uint
+	if err := db.Model(&models.StudyItem{}).
+		Where("workspace_id = ? AND summary_id IN ?", workspaceID, summaryIDs).
+		Distinct().Pluck("summary_id", &synced).Error; err != nil {
+		return fmt.Errorf("failed to check study items: %w", err)
+	}
+	done := make(map[uint]bool, len(synced))
+	for _, id := range synced {
+		done[id] = true
+	}
+
+	var pending []uint
+	for _, id := range summaryIDs {
+		if !done[id] {
+			pending = append(pending, id)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	var summaries []models.Summaries
+	if err := db.Omit("embedding").Where("id IN ?", pending).Find(&summaries).Error; err != nil {
+		return fmt.Errorf("failed to fetch summaries: %w", err)
+	}
+
+	var items []models.StudyItem
+	for _, summary := range summaries {
+		// Only per-document summaries are studied; synthesized ones belong to no single PDF
+		if summary.PDFID == nil {
+			continue
+		}
+		for i, point := range ExtractKeyPoints(summary.Content) {
+			items = append(items, models.StudyItem{
+				WorkspaceID: workspaceID,
+				SummaryID:   summary.ID,
+				Position:    i,
+				PDFID:       *summary.PDFID,
+				Content:     point,
+			})
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	// Concurrent requests may sync the same summary; the unique index keeps one copy
+	if err := db.Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(&items, 100).Error; err != nil {
+		return fmt.Errorf("failed to create study items: %w", err)
+	}
+	return nil
+}
+
+// StudyItemRow is a study item joined with the caller's schedule, if any
+type StudyItemRow struct {
+	ID             uint
+	PDFID          uint
+	PDFTitle       string
+	SummaryID      uint
+	Position       int
+	Content        string
+	ScheduleID     *uint
+	EaseFactor     *float64
+	IntervalDays   *int
+	Repetitions    *int
+	DueAt          *time.Time
+	LastReviewedAt *time.Time
+}
+
+// StudyItemsQuery selects the workspace's study items for summaryIDs with the user's schedule
+func StudyItemsQuery(db *gorm.DB, identity Identity, summaryIDs []uint) *gorm.DB {
+	return db.Table("study_items si").
+		Select(`si.id, si.pdf_id, p.title AS pdf_title, si.summary_id, si.position, si.content,
+			ss.id AS schedule_id, ss.ease_factor, ss.interval_days, ss.repetitions, ss.due_at, ss.last_reviewed_at`).
+		Joins("JOIN pdfs p ON p.id = si.pdf_id").
+		Joins("LEFT JOIN study_schedules ss ON ss.study_item_id = si.id AND ss.user_id = ? AND ss.deleted_at IS NULL", identity.UserID).
+		Where("si.deleted_at IS NULL AND si.workspace_id = ? AND si.summary_id IN ?", identity.WorkspaceID, summaryIDs)
+}
@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"backend-go/dto"
+	"backend-go/models"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pgvector/pgvector-go"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaxSynthesisSources limits how many PDFs one synthesized summary can combine
+const MaxSynthesisSources = 10
+
+// SynthesisSummariesPerPDF is how many focus-relevant summaries each PDF contributes
+const SynthesisSummariesPerPDF = 2
+
+// synthesisTextChars is how much extracted text is used for a PDF without summaries
+const synthesisTextChars = 8000
+
+// SynthesisContexts collects the context each PDF contributes to a synthesis, in the
+// order of pdfs, and the summary used for each PDF (nil when extracted text was used).
+// With a focus question the summaries closest to it are retrieved; otherwise, or when
+// the focus cannot be embedded, each PDF's primary summary is used. PDFs without
+// summaries fall back to the beginning of their text.
+func SynthesisContexts(ctx context.Context, db *gorm.DB, pdfs []models.PDF, focus string) ([]dto.PythonSynthesisSource, map[uint]*uint, error) {
+	var focusEmbedding *pgvector.Vector
+	if strings.TrimSpace(focus) != "" {
+		values, err := GenerateEmbedding(ctx, focus)
+		if err != nil {
+			fmt.Printf("Warning: Failed to embed synthesis focus, using primary summaries: %v\n", err)
+		} else {
+			vector := pgvector.NewVector(values)
+			focusEmbedding = &vector
+		}
+	}
+
+	primary := make(map[uint]models.Summaries)
+	if focusEmbedding == nil {
+		pdfIDs := make([]uint, len(pdfs))
+		for i, pdf := range pdfs {
+			pdfIDs[i] = pdf.ID
+		}
+		summaryIDs, err := PrimarySummaryIDs(db, pdfIDs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to find primary summaries: %w", err)
+		}
+
+		var summaries []models.Summaries
+		if len(summaryIDs) > 0 {
+			if err := db.Omit("embedding").Where("id IN ?", summaryIDs).Find(&summaries).Error; err != nil {
+				return nil, nil, fmt.Errorf("failed to fetch summaries: %w", err)
+			}
+		}
+		for _, summary := range summaries {
+			if summary.PDFID != nil {
+				primary[*summary.PDFID] = summary
+			}
+		}
+	}
+
+	sources := make([]dto.PythonSynthesisSource, 0, len(pdfs))
+	used := make(map[uint]*uint, len(pdfs))
+	for _, pdf := range pdfs {
+		var summaries []models.Summaries
+		if focusEmbedding != nil {
+			if err := WithVectorSearch(db, VectorSearchOptions{}, func(tx *gorm.DB) error {
+				return tx.Omit("embedding").
+					Where("pdf_id = ? AND embedding IS NOT NULL AND flagged = ?", pdf.ID, false).
+					Order(clause.OrderBy{Expression: clause.Expr{SQL: "embedding <=> ?", Vars: []interface{}{*focusEmbedding}}}).
+					Limit(SynthesisSummariesPerPDF).
+					Find(&summaries).Error
+			}); err != nil {
+				return nil, nil, fmt.Errorf("failed to retrieve summaries of PDF %d: %w", pdf.ID, err)
+			}
+		} else if summary, ok := primary[pdf.ID]; ok {
+			summaries = []models.Summaries{summary}
+		}
+
+		if len(summaries) > 0 {
+			parts := make([]string, len(summaries))
+			for i, summary := range summaries {
+				parts[i] = summary.Content
+			}
+			id := summaries[0].ID
+			used[pdf.ID] = &id
+			sources = append(sources, dto.PythonSynthesisSource{
+				PDFID:   pdf.ID,
+				Title:   pdf.Title,
+				Content: strings.Join(parts, "\n\n"),
+			})
+			continue
+		}
+
+		file, _, err := OpenStoredFile(ctx, pdf.Filename)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to retrieve PDF %d from storage: %w", pdf.ID, err)
+		}
+		text, err := ExtractPDFText(ctx, pdf.Filename, file, synthesisTextChars)
+		file.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to extract text of PDF %d: %w", pdf.ID, err)
+		}
+		used[pdf.ID] = nil
+		sources = append(sources, dto.PythonSynthesisSource{
+			PDFID:   pdf.ID,
+			Title:   pdf.Title,
+			Content: text,
+		})
+	}
+
+	return sources, used, nil
+}
@@ -2,6 +2,8 @@ package utils
 
 import (
 	"fmt"
+	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -16,17 +18,6 @@ func ValidatePaginationParams(page, itemsPerPage int) (int, int) {
 	return page, itemsPerPage
 }
 
-// ValidateSortParams validates sort field and order
-func ValidateSortParams(sortBy, order string, validFields map[string]bool, defaultSort string) (string, string) {
-	if !validFields[sortBy] {
-		sortBy = defaultSort
-	}
-	if order != "asc" && order != "desc" {
-		order = "desc"
-	}
-	return sortBy, order
-}
-
 // SanitizeSearchQuery sanitizes search query to prevent SQL injection
 func SanitizeSearchQuery(query string) string {
 	// Remove potentially dangerous characters
@@ -62,7 +53,7 @@ func ValidateFileExtension(filename string) error {
 		".pdf": true,
 	}
 
-	ext := strings.ToLower(filename[strings.LastIndex(filename, "."):])
+	ext := strings.ToLower(filepath.Ext(filename))
 	if !allowedExtensions[ext] {
 		return fmt.Errorf("file extension %s is not allowed", ext)
 	}
@@ -97,16 +88,185 @@ func ValidateSummaryStyle(style string) error {
 	return nil
 }
 
-// ValidateLanguage validates language code
+// ValidateLanguage validates language against the language registry
 func ValidateLanguage(language string) error {
-	validLanguages := map[string]bool{
-		"english":    true,
-		"indonesian": true,
+	if _, ok := LookupLanguage(language); !ok {
+		return fmt.Errorf("invalid language: %s", language)
 	}
 
-	if !validLanguages[strings.ToLower(language)] {
-		return fmt.Errorf("invalid language: %s", language)
+	return nil
+}
+
+// ValidFeedbackIssues lists the issues a summary can be flagged with
+var ValidFeedbackIssues = map[string]bool{
+	"hallucination":  true,
+	"inaccurate":     true,
+	"incomplete":     true,
+	"wrong_language": true,
+	"formatting":     true,
+	"other":          true,
+}
+
+// ValidateFeedback validates summary feedback fields
+func ValidateFeedback(vote int, rating *int, comment string, issues []string) error {
+	if vote < -1 || vote > 1 {
+		return fmt.Errorf("vote must be -1, 0 or 1")
+	}
+	if rating != nil && (*rating < 1 || *rating > 5) {
+		return fmt.Errorf("rating must be between 1 and 5")
+	}
+	if len(comment) > 2000 {
+		return fmt.Errorf("comment cannot exceed 2000 characters")
+	}
+	if vote == 0 && rating == nil && strings.TrimSpace(comment) == "" && len(issues) == 0 {
+		return fmt.Errorf("feedback must include a vote, rating, comment or issue")
+	}
+	for _, issue := range issues {
+		if !ValidFeedbackIssues[strings.ToLower(issue)] {
+			return fmt.Errorf("invalid feedback issue: %s", issue)
+		}
+	}
+	return nil
+}
+
+// ValidateFlashcardRequest validates and normalizes flashcard generation parameters
+func ValidateFlashcardRequest(source string, count int) (string, int, error) {
+	source = strings.ToLower(strings.TrimSpace(source))
+	if source == "" {
+		source = "document"
+	}
+	if source != "document" && source != "summary" {
+		return "", 0, fmt.Errorf("invalid flashcard source: %s", source)
+	}
+
+	if count == 0 {
+		count = 10
+	}
+	if count < 1 || count > 50 {
+		return "", 0, fmt.Errorf("count must be between 1 and 50")
+	}
+
+	return source, count, nil
+}
+
+// ValidateFlashcardText validates an edited flashcard question or answer
+func ValidateFlashcardText(field, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("%s cannot be empty", field)
+	}
+	if len(text) > 2000 {
+		return fmt.Errorf("%s cannot exceed 2000 characters", field)
+	}
+	return nil
+}
+
+// ParseID parses a record ID such as an :id route parameter. Only positive numbers
+// are accepted, so the value is never passed on to the database as raw SQL.
+func ParseID(value string) (uint, error) {
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("invalid ID: %s", value)
+	}
+	return uint(id), nil
+}
+
+// ParseIDList parses a comma-separated list of IDs such as "1,2,3"
+func ParseIDList(value string) ([]uint, error) {
+	var ids []uint
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := ParseID(part)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
 	}
+	return ids, nil
+}
 
+// ValidateReviewGrade validates an SM-2 review grade
+func ValidateReviewGrade(grade *int) error {
+	if grade == nil {
+		return fmt.Errorf("grade is required")
+	}
+	if *grade < 0 || *grade > MaxReviewGrade {
+		return fmt.Errorf("grade must be between 0 and %d", MaxReviewGrade)
+	}
 	return nil
 }
+
+// ValidateQuizRequest validates and normalizes quiz generation parameters
+func ValidateQuizRequest(count int, questionTypes []string) (int, []string, error) {
+	if count == 0 {
+		count = 10
+	}
+	if count < 1 || count > 30 {
+		return 0, nil, fmt.Errorf("count must be between 1 and 30")
+	}
+
+	if len(questionTypes) == 0 {
+		return count, []string{QuestionMultipleChoice, QuestionShortAnswer}, nil
+	}
+
+	types := make([]string, 0, len(questionTypes))
+	for _, t := range questionTypes {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t != QuestionMultipleChoice && t != QuestionShortAnswer {
+			return 0, nil, fmt.Errorf("invalid question type: %s", t)
+		}
+		types = append(types, t)
+	}
+	return count, types, nil
+}
+
+// ValidateGlossaryMaxTerms validates and normalizes the number of glossary terms to extract
+func ValidateGlossaryMaxTerms(maxTerms int) (int, error) {
+	if maxTerms == 0 {
+		return 30, nil
+	}
+	if maxTerms < 1 || maxTerms > 100 {
+		return 0, fmt.Errorf("max_terms must be between 1 and 100")
+	}
+	return maxTerms, nil
+}
+
+// NormalizeTerm lowercases a glossary term and collapses whitespace
+func NormalizeTerm(term string) string {
+	return strings.Join(strings.Fields(strings.ToLower(term)), " ")
+}
+
+// EscapeLike escapes LIKE/ILIKE wildcards so user input matches literally
+func EscapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// ValidateSynthesisRequest deduplicates the source PDF IDs and checks their count and the focus length
+func ValidateSynthesisRequest(pdfIDs []uint, focus string) ([]uint, error) {
+	seen := make(map[uint]bool, len(pdfIDs))
+	unique := make([]uint, 0, len(pdfIDs))
+	for _, id := range pdfIDs {
+		if id == 0 {
+			return nil, fmt.Errorf("invalid PDF ID: %d", id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	if len(unique) < 2 {
+		return nil, fmt.Errorf("at least two different PDFs are required")
+	}
+	if len(unique) > MaxSynthesisSources {
+		return nil, fmt.Errorf("at most %d PDFs can be synthesized at once", MaxSynthesisSources)
+	}
+	if len(focus) > 500 {
+		return nil, fmt.Errorf("focus cannot exceed 500 characters")
+	}
+
+	return unique, nil
+}
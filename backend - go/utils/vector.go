@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"backend-go/config"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/pgvector/pgvector-go"
+	"gorm.io/gorm"
+)
+
+// Approximate nearest-neighbour index types supported by pgvector
+const (
+	VectorIndexHNSW    = "hnsw"
+	VectorIndexIVFFlat = "ivfflat"
+)
+
+// vectorIndex is the index type built on vector columns and its tuning
+var vectorIndex = config.Default().VectorIndex
+
+// SetVectorIndex sets the index type and the build and search settings of vector columns
+func SetVectorIndex(cfg config.VectorIndex) {
+	vectorIndex = cfg
+}
+
+// CosineSimilarity returns the cosine similarity of two vectors, or false when
+// either is empty or their dimensions differ
+func CosineSimilarity(a, b pgvector.Vector) (float64, bool) {
+	x, y := a.Slice(), b.Slice()
+	if len(x) == 0 || len(x) != len(y) {
+		return 0, false
+	}
+
+	var dot, normX, normY float64
+	for i := range x {
+		dot += float64(x[i]) * float64(y[i])
+		normX += float64(x[i]) * float64(x[i])
+		normY += float64(y[i]) * float64(y[i])
+	}
+	if normX == 0 || normY == 0 {
+		return 0, false
+	}
+
+	return dot / (math.Sqrt(normX) * math.Sqrt(normY)), true
+}
+
+// VectorIndexType returns the configured index type for vector columns
+func VectorIndexType() string {
+	return vectorIndex.Type
+}
+
+// VectorIndexName returns the name of the index of the given type on table.column
+func VectorIndexName(table, column, indexType string) string {
+	return fmt.Sprintf("idx_%s_%s_%s", table, column, indexType)
+}
+
+// VectorIndexSQL returns the CREATE INDEX statement for a cosine-distance index on
+// table.column, built with the configured HNSW or IVFFlat settings
+func VectorIndexSQL(table, column, indexType string) string {
+	name := VectorIndexName(table, column, indexType)
+	if indexType == VectorIndexIVFFlat {
+		return fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING ivfflat (%s vector_cosine_ops) WITH (lists = %d)",
+			name, table, column, vectorIndex.IVFFlatLists)
+	}
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING hnsw (%s vector_cosine_ops) WITH (m = %d, ef_construction = %d)",
+		name, table, column, vectorIndex.HNSWM, vectorIndex.HNSWEfConstruction)
+}
+
+// EnsureVectorIndexes creates the configured index type on every vector column of the
+// current schema and drops indexes of the other type, returning the indexed columns.
+// Columns declared without a dimension cannot be indexed and are skipped; a failure on one
+// column does not stop the others, and all failures are returned together.
+func EnsureVectorIndexes(db *gorm.DB) ([]string, error) {
+	var columns []struct {
+		TableName  string
+		ColumnName string
+		Dimensions int
+	}
+	if err := db.Raw(`
+		SELECT c.relname AS table_name, a.attname AS column_name, a.atttypmod AS dimensions
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_type t ON t.oid = a.atttypid
+		WHERE n.nspname = current_schema() AND c.relkind = 'r' AND t.typname = 'vector'
+			AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY c.relname, a.attname`).Scan(&columns).Error; err != nil {
+		return nil, fmt.Errorf("failed to list vector columns: %w", err)
+	}
+
+	indexType := VectorIndexType()
+	other := VectorIndexIVFFlat
+	if indexType == VectorIndexIVFFlat {
+		other = VectorIndexHNSW
+	}
+
+	indexed := make([]string, 0, len(columns))
+	var errs []error
+	for _, col := range columns {
+		if col.Dimensions <= 0 {
+			fmt.Printf("Warning: Skipping vector index on %s.%s, which has no dimension\n", col.TableName, col.ColumnName)
+			continue
+		}
+		if err := db.Exec(fmt.Sprintf("DROP INDEX IF EXISTS %s", VectorIndexName(col.TableName, col.ColumnName, other))).Error; err != nil {
+			errs = append(errs, fmt.Errorf("failed to drop %s index on %s.%s: %w", other, col.TableName, col.ColumnName, err))
+			continue
+		}
+		if err := db.Exec(VectorIndexSQL(col.TableName, col.ColumnName, indexType)).Error; err != nil {
+			errs = append(errs, fmt.Errorf("failed to create %s index on %s.%s: %w", indexType, col.TableName, col.ColumnName, err))
+			continue
+		}
+		indexed = append(indexed, col.TableName+"."+col.ColumnName)
+	}
+
+	return indexed, errors.Join(errs...)
+}
+
+// VectorSearchOptions tunes one approximate search; zero values use the configured
+// ef_search and probes
+type VectorSearchOptions struct {
+	EfSearch int // HNSW candidate list size; higher is slower but more accurate
+	Probes   int // IVFFlat lists scanned; higher is slower but more accurate
+}
+
+// Settings returns the SET LOCAL statements applying the options
+func (o VectorSearchOptions) Settings() []string {
+	efSearch := o.EfSearch
+	if efSearch <= 0 {
+		efSearch = vectorIndex.HNSWEfSearch
+	}
+	probes := o.Probes
+	if probes <= 0 {
+		probes = vectorIndex.IVFFlatProbes
+	}
+	return []string{
+		fmt.Sprintf("SET LOCAL hnsw.ef_search = %d", efSearch),
+		fmt.Sprintf("SET LOCAL ivfflat.probes = %d", probes),
+	}
+}
+
+// WithVectorSearch runs fn in a transaction with the search options applied,
+// so they affect only fn's queries
+func WithVectorSearch(db *gorm.DB, opts VectorSearchOptions, fn func(tx *gorm.DB) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range opts.Settings() {
+			if err := tx.Exec(statement).Error; err != nil {
+				return fmt.Errorf("failed to apply vector search setting: %w", err)
+			}
+		}
+		return fn(tx)
+	})
+}
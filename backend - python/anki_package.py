@@ -0,0 +1,265 @@
+"""
+Build Anki .apkg packages.
+
+An .apkg file is a zip archive holding a SQLite collection (collection.anki2,
+schema version 11) and a JSON media map. Only the parts Anki needs to import
+a deck of basic front/back notes are written.
+"""
+
+import hashlib
+import io
+import json
+import os
+import re
+import sqlite3
+import tempfile
+import time
+import zipfile
+
+SCHEMA = """
+CREATE TABLE col (
+    id integer primary key, crt integer not null, mod integer not null,
+    scm integer not null, ver integer not null, dty integer not null,
+    usn integer not null, ls integer not null, conf text not null,
+    models text not null, decks text not null, dconf text not null,
+    tags text not null
+);
+CREATE TABLE notes (
+    id integer primary key, guid text not null, mid integer not null,
+    mod integer not null, usn integer not null, tags text not null,
+    flds text not null, sfld integer not null, csum integer not null,
+    flags integer not null, data text not null
+);
+CREATE TABLE cards (
+    id integer primary key, nid integer not null, did integer not null,
+    ord integer not null, mod integer not null, usn integer not null,
+    type integer not null, queue integer not null, due integer not null,
+    ivl integer not null, factor integer not null, reps integer not null,
+    lapses integer not null, left integer not null, odue integer not null,
+    odid integer not null, flags integer not null, data text not null
+);
+CREATE TABLE revlog (
+    id integer primary key, cid integer not null, usn integer not null,
+    ease integer not null, ivl integer not null, lastIvl integer not null,
+    factor integer not null, time integer not null, type integer not null
+);
+CREATE TABLE graves (
+    usn integer not null, oid integer not null, type integer not null
+);
+CREATE INDEX ix_notes_usn on notes (usn);
+CREATE INDEX ix_cards_usn on cards (usn);
+CREATE INDEX ix_revlog_usn on revlog (usn);
+CREATE INDEX ix_cards_nid on cards (nid);
+CREATE INDEX ix_cards_sched on cards (did, queue, due);
+CREATE INDEX ix_revlog_cid on revlog (cid);
+CREATE INDEX ix_notes_csum on notes (csum);
+"""
+
+MODEL_CSS = """.card {
+  font-family: arial;
+  font-size: 20px;
+  text-align: center;
+  color: black;
+  background-color: white;
+}
+"""
+
+DEFAULT_DECK_CONFIG = {
+    "id": 1,
+    "name": "Default",
+    "mod": 0,
+    "usn": 0,
+    "maxTaken": 60,
+    "autoplay": True,
+    "timer": 0,
+    "replayq": True,
+    "dyn": False,
+    "new": {
+        "bury": True,
+        "delays": [1, 10],
+        "initialFactor": 2500,
+        "ints": [1, 4, 7],
+        "order": 1,
+        "perDay": 20,
+        "separate": True,
+    },
+    "lapse": {
+        "delays": [10],
+        "leechAction": 0,
+        "leechFails": 8,
+        "minInt": 1,
+        "mult": 0,
+    },
+    "rev": {
+        "bury": True,
+        "ease4": 1.3,
+        "fuzz": 0.05,
+        "ivlFct": 1,
+        "maxIvl": 36500,
+        "minSpace": 1,
+        "perDay": 100,
+    },
+}
+
+
+def stable_id(*parts: str) -> int:
+    """Derive a positive 53-bit ID from text so re-exports update the same deck and notes"""
+    digest = hashlib.sha1("\x1f".join(parts).encode("utf-8")).hexdigest()
+    return int(digest[:13], 16)
+
+
+def field_checksum(text: str) -> int:
+    """Anki's sort field checksum: first 8 hex digits of the SHA-1 of the HTML-stripped text"""
+    stripped = re.sub(r"<[^>]+>", "", text)
+    return int(hashlib.sha1(stripped.encode("utf-8")).hexdigest()[:8], 16)
+
+
+def sanitize_tag(tag: str) -> str:
+    """Anki tags cannot contain whitespace"""
+    return re.sub(r"\s+", "_", tag.strip())
+
+
+def escape_html(text: str) -> str:
+    """Escape card text, keeping line breaks"""
+    escaped = text.replace("&", "&amp;").replace("<", "&lt;").replace(">", "&gt;")
+    return escaped.replace("\n", "<br>")
+
+
+def build_apkg(deck_name: str, cards: list) -> bytes:
+    """
+    Build an .apkg package for a deck of basic notes
+
+    Args:
+        deck_name: Name of the Anki deck
+        cards: List of dicts with "front", "back" and optional "id" and "tags"
+
+    Returns:
+        The .apkg file contents
+    """
+    now = int(time.time())
+    now_ms = int(time.time() * 1000)
+    deck_id = stable_id("deck", deck_name)
+    model_id = stable_id("model", "AI PDF Summarizer Basic")
+
+    model = {
+        "id": model_id,
+        "name": "AI PDF Summarizer Basic",
+        "type": 0,
+        "mod": now,
+        "usn": -1,
+        "sortf": 0,
+        "did": deck_id,
+        "tmpls": [{
+            "name": "Card 1",
+            "ord": 0,
+            "qfmt": "{{Front}}",
+            "afmt": "{{FrontSide}}<hr id=answer>{{Back}}",
+            "did": None,
+            "bqfmt": "",
+            "bafmt": "",
+        }],
+        "flds": [
+            {"name": name, "ord": i, "sticky": False, "rtl": False, "font": "Arial", "size": 20, "media": []}
+            for i, name in enumerate(["Front", "Back"])
+        ],
+        "css": MODEL_CSS,
+        "latexPre": "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage[utf8]{inputenc}\n\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n\\setlength{\\parindent}{0in}\n\\begin{document}\n",
+        "latexPost": "\\end{document}",
+        "tags": [],
+        "vers": [],
+        "req": [[0, "any", [0]]],
+    }
+
+    def deck(did: int, name: str) -> dict:
+        return {
+            "id": did,
+            "name": name,
+            "mod": now,
+            "usn": -1,
+            "desc": "",
+            "dyn": 0,
+            "conf": 1,
+            "collapsed": False,
+            "newToday": [0, 0],
+            "revToday": [0, 0],
+            "lrnToday": [0, 0],
+            "timeToday": [0, 0],
+            "extendNew": 10,
+            "extendRev": 50,
+        }
+
+    conf = {
+        "activeDecks": [1],
+        "curDeck": 1,
+        "newSpread": 0,
+        "collapseTime": 1200,
+        "timeLim": 0,
+        "estTimes": True,
+        "dueCounts": True,
+        "curModel": str(model_id),
+        "nextPos": len(cards) + 1,
+        "sortType": "noteFld",
+        "sortBackwards": False,
+        "addToCur": True,
+    }
+
+    # sqlite3 needs a real file; the temporary directory is removed afterwards
+    with tempfile.TemporaryDirectory() as tmp:
+        path = os.path.join(tmp, "collection.anki2")
+        db = sqlite3.connect(path)
+        try:
+            db.executescript(SCHEMA)
+            db.execute(
+                "INSERT INTO col VALUES (1, ?, ?, ?, 11, 0, 0, 0, ?, ?, ?, ?, '{}')",
+                (
+                    now,
+                    now_ms,
+                    now_ms,
+                    json.dumps(conf),
+                    json.dumps({str(model_id): model}),
+                    json.dumps({"1": deck(1, "Default"), str(deck_id): deck(deck_id, deck_name)}),
+                    json.dumps({"1": DEFAULT_DECK_CONFIG}),
+                ),
+            )
+
+            used_ids = set()
+            for position, card in enumerate(cards):
+                front = escape_html(str(card.get("front", "")))
+                back = escape_html(str(card.get("back", "")))
+                tags = " ".join(sanitize_tag(t) for t in card.get("tags") or [] if t.strip())
+
+                # The flashcard ID survives edits to the front; the text is only a fallback
+                if card.get("id"):
+                    note_id = stable_id("note", "flashcard", str(card["id"]))
+                else:
+                    note_id = stable_id("note", deck_name, front)
+                while note_id in used_ids:
+                    note_id += 1
+                used_ids.add(note_id)
+
+                db.execute(
+                    "INSERT INTO notes VALUES (?, ?, ?, ?, -1, ?, ?, ?, ?, 0, '')",
+                    (
+                        note_id,
+                        "%x" % note_id,  # Anki matches notes on import by guid
+                        model_id,
+                        now,
+                        f" {tags} " if tags else "",
+                        f"{front}\x1f{back}",
+                        front,
+                        field_checksum(front),
+                    ),
+                )
+                db.execute(
+                    "INSERT INTO cards VALUES (?, ?, ?, 0, ?, -1, 0, 0, ?, 0, 0, 0, 0, 0, 0, 0, 0, '')",
+                    (note_id, note_id, deck_id, now, position + 1),
+                )
+            db.commit()
+        finally:
+            db.close()
+
+        buffer = io.BytesIO()
+        with zipfile.ZipFile(buffer, "w", zipfile.ZIP_DEFLATED) as package:
+            package.write(path, "collection.anki2")
+            package.writestr("media", "{}")
+        return buffer.getvalue()
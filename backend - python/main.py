@@ -1,19 +1,23 @@
 from fastapi import FastAPI, File, UploadFile, HTTPException, Form, Request
 from fastapi.middleware.cors import CORSMiddleware
-from fastapi.responses import JSONResponse
+from fastapi.responses import JSONResponse, Response
 from pathlib import Path
 from dotenv import load_dotenv
 from enum import Enum
 from pydantic import BaseModel
 from typing import List, Optional
+from google.api_core import exceptions as google_exceptions
 import google.generativeai as genai
 import io
+import json
+import math
 import PyPDF2
 import re
 import os
 import time
 import requests
 from simple_logger import SimpleLogger
+from anki_package import build_apkg
 
 load_dotenv()
 api_key = os.getenv("GEMINI_API_KEY")
@@ -30,17 +34,62 @@ print(f"✓ Using custom embedding API: {EMBEDDING_API_URL}")
 print("✓ Embedding dimensions: 1024")
 
 
+class EmbeddingRateLimited(Exception):
+    """The custom embedding API refused a call with 429 Too Many Requests"""
+
+    def __init__(self, retry_after: Optional[str] = None):
+        super().__init__("Embedding API rate limit exceeded")
+        self.retry_after = retry_after
+
+
+# Errors meaning an upstream AI service is rate limiting us; they become 429 responses so the
+# Go backend backs off and retries instead of treating them as failures
+RATE_LIMIT_ERRORS = (google_exceptions.ResourceExhausted, EmbeddingRateLimited)
+
+
+def retry_after_seconds(error: Exception) -> Optional[str]:
+    """Return the delay an upstream service asked for, in whole seconds, if it sent one"""
+    if isinstance(error, EmbeddingRateLimited):
+        return error.retry_after
+    # Gemini reports the delay as a RetryInfo detail, or only in the message of older clients
+    for detail in getattr(error, "details", None) or []:
+        delay = getattr(detail, "retry_delay", None)
+        if delay is not None and hasattr(delay, "seconds"):
+            return str(max(1, math.ceil(delay.seconds + getattr(delay, "nanos", 0) / 1e9)))
+    match = re.search(r"retry in ([\d.]+)\s*s|retry_delay\s*\{\s*seconds:\s*(\d+)", str(error), re.IGNORECASE)
+    if match:
+        return str(max(1, math.ceil(float(match.group(1) or match.group(2)))))
+    return None
+
+
+def rate_limited(error: Exception) -> HTTPException:
+    """Turn a rate limit error from Gemini or the embedding API into a 429 carrying its Retry-After"""
+    retry_after = retry_after_seconds(error)
+    return HTTPException(
+        status_code=429,
+        detail=f"AI service rate limit exceeded: {str(error)}",
+        headers={"Retry-After": retry_after} if retry_after else None,
+    )
+
+
+# Provenance recorded with every generated summary
+GENERATION_MODEL = "gemini-2.5-flash-lite"
+SUMMARIZE_PROMPT_VERSION = "summarize-v2"
+TRANSLATE_PROMPT_VERSION = "translate-v1"
+FLASHCARDS_PROMPT_VERSION = "flashcards-v1"
+QUIZ_PROMPT_VERSION = "quiz-v1"
+GLOSSARY_PROMPT_VERSION = "glossary-v1"
+OUTLINE_PROMPT_VERSION = "outline-v1"
+SYNTHESIZE_PROMPT_VERSION = "synthesize-v1"
+LABEL_TOPICS_PROMPT_VERSION = "label-topics-v1"
+
+
 # Enum for summary style
 class Style(str, Enum):
     SHORT = "short"
     GENERAL = "general"
     DETAILED = "detailed"
     
-# Enum for summary language
-class Language(str, Enum):
-    IND = "indonesian"
-    ENG = "english"
-
 # Pydantic models for chat
 class ChatMessage(BaseModel):
     role: str  # "user" or "assistant"
@@ -54,6 +103,46 @@ class ChatRequest(BaseModel):
 class EmbeddingRequest(BaseModel):
     text: str
 
+class AnkiCard(BaseModel):
+    id: Optional[int] = None  # Flashcard ID; keeps the Anki note when the card is edited
+    front: str
+    back: str
+    tags: Optional[List[str]] = []
+
+class AnkiPackageRequest(BaseModel):
+    deck_name: str
+    cards: List[AnkiCard]
+
+class SynthesisSource(BaseModel):
+    pdf_id: int
+    title: str
+    content: str  # Summaries or extracted text of the document
+
+class SynthesizeRequest(BaseModel):
+    sources: List[SynthesisSource]
+    focus: Optional[str] = None  # Question the synthesis should answer
+    style: str = "general"
+    language: str = "en"
+    language_name: Optional[str] = None
+    language_instruction: Optional[str] = None
+
+class TopicDocument(BaseModel):
+    title: str
+    excerpt: Optional[str] = ""
+
+class TopicCluster(BaseModel):
+    id: int
+    documents: List[TopicDocument]
+
+class LabelTopicsRequest(BaseModel):
+    clusters: List[TopicCluster]
+
+class TranslateRequest(BaseModel):
+    text: str
+    language: str  # Target language code
+    language_name: Optional[str] = None
+    language_instruction: Optional[str] = None
+
 
 # Initialize FastAPI app
 app = FastAPI(
@@ -148,6 +237,8 @@ async def generate_text_embedding(request: EmbeddingRequest):
             timeout=30
         )
         
+        if response.status_code == 429:
+            raise EmbeddingRateLimited(response.headers.get("Retry-After"))
         if response.status_code != 200:
             raise HTTPException(
                 status_code=response.status_code,
@@ -179,6 +270,8 @@ async def generate_text_embedding(request: EmbeddingRequest):
         
     except HTTPException:
         raise
+    except RATE_LIMIT_ERRORS as e:
+        raise rate_limited(e)
     except Exception as e:
         # Log the full error for debugging
         import traceback
@@ -278,6 +371,8 @@ async def chat(request: ChatRequest):
     except HTTPException:
         # Re-raise HTTP exceptions
         raise
+    except RATE_LIMIT_ERRORS as e:
+        raise rate_limited(e)
     except Exception as e:
         # Log the full error for debugging
         import traceback
@@ -306,6 +401,128 @@ def extract_text_from_pdf(file_content: bytes) -> str:
             detail=f"Error extracting text from PDF: {str(e)}"
         )
 
+def extract_pages_from_pdf(file_content: bytes) -> list:
+    """Extract the text of each PDF page, in page order"""
+    try:
+        pdf_reader = PyPDF2.PdfReader(io.BytesIO(file_content))
+        return [(page.extract_text() or "").strip() for page in pdf_reader.pages]
+    except Exception as e:
+        raise HTTPException(
+            status_code=400,
+            detail=f"Error extracting text from PDF: {str(e)}"
+        )
+
+def paginated_text(pages: list, max_chars: int = 30000) -> str:
+    """
+    Join page texts with [Page N] markers so the model can cite source pages
+    
+    Args:
+        pages: Text of each page
+        max_chars: Maximum characters to include; pages are sampled evenly beyond it
+        
+    Returns:
+        Text with page markers
+    """
+    numbered = [(i + 1, text) for i, text in enumerate(pages) if text]
+    total = sum(len(text) for _, text in numbered)
+
+    # Keep every n-th page of long documents so cards cover the whole document
+    if total > max_chars and numbered:
+        step = -(-total // max_chars)
+        numbered = numbered[::step]
+
+    parts = []
+    used = 0
+    for number, text in numbered:
+        if used >= max_chars:
+            break
+        text = text[:max_chars - used]
+        parts.append(f"[Page {number}]\n{text}")
+        used += len(text)
+    return "\n\n".join(parts)
+
+def first_occurrence_page(term: str, pages: list):
+    """Return the 1-based number of the first page mentioning term (case-insensitive), or None"""
+    pattern = re.compile(r"(?<!\w)" + re.escape(term.strip()) + r"(?!\w)", re.IGNORECASE)
+    for number, text in enumerate(pages, start=1):
+        if pattern.search(text):
+            return number
+    return None
+
+def embedded_outline(pdf_reader) -> list:
+    """
+    Read the PDF's own bookmarks as a section tree
+    
+    Args:
+        pdf_reader: PyPDF2 reader of the document
+        
+    Returns:
+        List of sections with heading, level, page, gist and children; empty when the PDF has no outline
+    """
+    def walk(items, level):
+        sections = []
+        for item in items:
+            # A nested list holds the children of the preceding bookmark
+            if isinstance(item, list):
+                if sections:
+                    sections[-1]["children"].extend(walk(item, level + 1))
+                else:
+                    sections.extend(walk(item, level))
+                continue
+
+            try:
+                page = pdf_reader.get_destination_page_number(item) + 1
+            except Exception:
+                page = None
+            sections.append({
+                "heading": str(getattr(item, "title", "") or "").strip(),
+                "level": level,
+                "page": page,
+                "gist": "",
+                "children": [],
+            })
+        return [s for s in sections if s["heading"]]
+
+    try:
+        return walk(pdf_reader.outline, 1)
+    except Exception as e:
+        print(f"Warning: Could not read embedded outline: {e}")
+        return []
+
+def normalize_outline(items, page_count: int, level: int = 1) -> list:
+    """Validate a model-generated section tree and make levels follow nesting depth"""
+    sections = []
+    for item in items if isinstance(items, list) else []:
+        if not isinstance(item, dict):
+            continue
+        heading = str(item.get("heading") or "").strip()
+        if not heading:
+            continue
+        sections.append({
+            "heading": heading,
+            "level": level,
+            "page": valid_page(item.get("page"), page_count),
+            "gist": str(item.get("gist") or "").strip(),
+            "children": normalize_outline(item.get("children"), page_count, level + 1),
+        })
+    return sections
+
+def flatten_outline(sections: list) -> list:
+    """List sections depth-first"""
+    flat = []
+    for section in sections:
+        flat.append(section)
+        flat.extend(flatten_outline(section["children"]))
+    return flat
+
+def parse_json_response(text: str):
+    """Parse JSON from a model response, tolerating Markdown code fences"""
+    cleaned = text.strip()
+    fence = re.match(r"^```(?:json)?\s*(.*?)\s*```$", cleaned, re.DOTALL)
+    if fence:
+        cleaned = fence.group(1)
+    return json.loads(cleaned)
+
 def count_words(text: str) -> dict:
     """
     Count words in text and return detailed statistics
@@ -424,7 +641,7 @@ def chunk_text(text: str, max_chunk_size: int = 8000, overlap: int = 200) -> lis
     
     return chunks
 
-def summarize_chunks(chunks: list, style: str, language: str) -> str:
+def summarize_chunks(chunks: list, style: str, language: str, language_instruction: str) -> str:
     """
     Summarize multiple chunks and combine them into a final summary
     
@@ -432,6 +649,7 @@ def summarize_chunks(chunks: list, style: str, language: str) -> str:
         chunks: List of text chunks
         style: Summary style (short, general, detailed)
         language: Language for summary
+        language_instruction: Prompt instruction for the summary language
         
     Returns:
         Combined summary
@@ -441,7 +659,7 @@ def summarize_chunks(chunks: list, style: str, language: str) -> str:
     
     # If only one chunk, summarize directly
     if len(chunks) == 1:
-        return summarize_single_chunk(chunks[0], style, language)
+        return summarize_single_chunk(chunks[0], style, language, language_instruction)
     
     # Summarize each chunk first
     chunk_summaries = []
@@ -456,7 +674,7 @@ def summarize_chunks(chunks: list, style: str, language: str) -> str:
                 - Create a concise summary of this section
                 - Focus on key points and main ideas
                 - Keep it factual and based only on the provided content
-                - Language: {language}
+                - Language: {language} ({language_instruction})
                 
                 Content to summarize:
                 {chunk}
@@ -469,6 +687,8 @@ def summarize_chunks(chunks: list, style: str, language: str) -> str:
                 )
             )
             chunk_summaries.append(response.text)
+        except RATE_LIMIT_ERRORS:
+            raise
         except Exception as e:
             chunk_summaries.append(f"Error summarizing section {i+1}: {str(e)}")
     
@@ -493,8 +713,7 @@ def summarize_chunks(chunks: list, style: str, language: str) -> str:
             - detailed: in-depth summary with key explanations and important details
             
             Language: {language}
-            - indonesian: respond in Bahasa Indonesia
-            - english: respond in English
+            - {language_instruction}
             
             Section summaries to combine:
             {combined_text}
@@ -507,6 +726,8 @@ def summarize_chunks(chunks: list, style: str, language: str) -> str:
             )
         )
         return response.text
+    except RATE_LIMIT_ERRORS:
+        raise
     except Exception as e:
         return f"Error creating final summary: {str(e)}\n\nSection summaries:\n{combined_text}"
 
@@ -554,7 +775,7 @@ def generate_embedding(text: str, max_retries: int = 3) -> list:
     return []
 
 
-def summarize_single_chunk(text: str, style: str, language: str) -> str:
+def summarize_single_chunk(text: str, style: str, language: str, language_instruction: str) -> str:
     """
     Summarize a single chunk of text
     
@@ -562,6 +783,7 @@ def summarize_single_chunk(text: str, style: str, language: str) -> str:
         text: Text to summarize
         style: Summary style
         language: Language for summary
+        language_instruction: Prompt instruction for the summary language
         
     Returns:
         Summary text
@@ -581,13 +803,9 @@ def summarize_single_chunk(text: str, style: str, language: str) -> str:
             - general: moderate-length summary covering main points and function of the section.
             - detailed: in-depth summary with key explanations and important details.
 
-            Languages:
-            - indonesian: respond in Bahasa Indonesia.
-            - english: respond in English.
-
             Selected options:
             - Summary style: {style}
-            - Language: {language}
+            - Language: {language} ({language_instruction})
 
             PDF content:
             {text}
@@ -600,6 +818,8 @@ def summarize_single_chunk(text: str, style: str, language: str) -> str:
             )
         )
         return response.text
+    except RATE_LIMIT_ERRORS:
+        raise
     except Exception as e:
         return f"Error generating summary: {str(e)}"
 
@@ -617,13 +837,892 @@ def validate_pdf_file(file: UploadFile) -> bool:
     
     return True
 
+@app.post("/translate")
+async def translate_summary(request: TranslateRequest):
+    """
+    Translate an existing summary into another language
+    
+    Args:
+        request: TranslateRequest containing summary text and target language
+        
+    Returns:
+        JSON response with translated text and its embedding
+    """
+    try:
+        start_time = time.time()
+
+        if not api_key:
+            raise HTTPException(
+                status_code=500,
+                detail="GEMINI_API_KEY not configured. Please set the API key in environment variables."
+            )
+
+        language_name = request.language_name or request.language
+        language_instruction = request.language_instruction or f"respond in {language_name}"
+
+        model = genai.GenerativeModel(GENERATION_MODEL)
+        response = model.generate_content(
+            f"""
+            You are translating a summary of a PDF document.
+
+            Instructions:
+            - Translate the summary faithfully into {language_name} ({language_instruction})
+            - Preserve the structure, formatting and level of detail
+            - Do NOT add, remove or reinterpret information
+            - Return only the translated summary
+
+            Summary to translate:
+            {request.text}
+            """,
+            generation_config=genai.types.GenerationConfig(
+                temperature=0.2,
+                top_k=1,
+                top_p=1,
+                max_output_tokens=2048,
+            )
+        )
+        translation = response.text
+
+        embedding_vector = generate_embedding(translation)
+        if not embedding_vector:
+            print("Warning: Failed to generate embedding for translation")
+
+        processing_time = round(time.time() - start_time, 2)
+
+        return JSONResponse(
+            status_code=200,
+            content={
+                "translation": translation,
+                "embedding": embedding_vector,
+                "language": request.language,
+                "processing_time": processing_time,
+                "model": GENERATION_MODEL,
+                "prompt_version": TRANSLATE_PROMPT_VERSION,
+                "status": "success"
+            }
+        )
+
+    except HTTPException:
+        raise
+    except RATE_LIMIT_ERRORS as e:
+        raise rate_limited(e)
+    except Exception as e:
+        import traceback
+        print(f"Translate endpoint error: {traceback.format_exc()}")
+
+        raise HTTPException(
+            status_code=500,
+            detail=f"Error translating summary: {str(e)}"
+        )
+
+@app.post("/synthesize")
+async def synthesize(request: SynthesizeRequest):
+    """
+    Combine material from several documents into one summary
+    
+    Args:
+        request: SynthesizeRequest with per-document context and an optional focus question
+        
+    Returns:
+        JSON response with the synthesized summary and its embedding
+    """
+    try:
+        start_time = time.time()
+
+        if not api_key:
+            raise HTTPException(
+                status_code=500,
+                detail="GEMINI_API_KEY not configured. Please set the API key in environment variables."
+            )
+
+        sources = [s for s in request.sources if s.content.strip()]
+        if len(sources) < 2:
+            raise HTTPException(status_code=400, detail="At least two sources with content are required")
+
+        language_name = request.language_name or request.language
+        language_instruction = request.language_instruction or f"respond in {language_name}"
+
+        # Share the context budget evenly so no single document crowds out the others
+        per_source_chars = max(2000, 30000 // len(sources))
+        documents = "\n\n".join(
+            f"[{s.title}]\n{s.content[:per_source_chars]}" for s in sources
+        )
+        focus_instruction = (
+            f"- Answer this question across the documents: {request.focus}"
+            if request.focus else
+            "- Cover the main themes the documents share and where they differ"
+        )
+
+        model = genai.GenerativeModel(GENERATION_MODEL)
+        response = model.generate_content(
+            f"""
+            You are writing one summary that synthesizes several documents.
+
+            Instructions:
+            {focus_instruction}
+            - Compare the documents: point out agreements, disagreements and gaps
+            - Attribute claims to their documents using the bracketed titles, e.g. [Title]
+            - Use ONLY the provided content; do NOT add outside information
+            - Summary style: {request.style} (short: a few sentences, general: moderate length, detailed: in-depth)
+            - Write in {language_name} ({language_instruction})
+
+            Documents:
+            {documents}
+            """,
+            generation_config=genai.types.GenerationConfig(
+                temperature=0.4,
+                top_k=1,
+                top_p=1,
+                max_output_tokens=4096,
+            )
+        )
+        synthesis = response.text
+
+        embedding_vector = generate_embedding(synthesis)
+        if not embedding_vector:
+            print("Warning: Failed to generate embedding for synthesis")
+
+        processing_time = round(time.time() - start_time, 2)
+
+        return JSONResponse(
+            status_code=200,
+            content={
+                "synthesis": synthesis,
+                "embedding": embedding_vector,
+                "language": request.language,
+                "processing_time": processing_time,
+                "model": GENERATION_MODEL,
+                "prompt_version": SYNTHESIZE_PROMPT_VERSION,
+                "status": "success"
+            }
+        )
+
+    except HTTPException:
+        raise
+    except RATE_LIMIT_ERRORS as e:
+        raise rate_limited(e)
+    except Exception as e:
+        import traceback
+        print(f"Synthesize endpoint error: {traceback.format_exc()}")
+
+        raise HTTPException(
+            status_code=500,
+            detail=f"Error synthesizing summary: {str(e)}"
+        )
+
+@app.post("/label-topics")
+async def label_topics(request: LabelTopicsRequest):
+    """
+    Name clusters of similar documents
+    
+    Args:
+        request: LabelTopicsRequest with the most representative documents of each cluster
+        
+    Returns:
+        JSON response with a short label and description per cluster ID
+    """
+    try:
+        start_time = time.time()
+
+        if not api_key:
+            raise HTTPException(
+                status_code=500,
+                detail="GEMINI_API_KEY not configured. Please set the API key in environment variables."
+            )
+        if not request.clusters:
+            raise HTTPException(status_code=400, detail="No clusters to label")
+
+        clusters_text = "\n\n".join(
+            f"Cluster {cluster.id}:\n" + "\n".join(
+                f"- {doc.title}" + (f": {doc.excerpt}" if doc.excerpt else "") for doc in cluster.documents
+            )
+            for cluster in request.clusters
+        )
+
+        model = genai.GenerativeModel(GENERATION_MODEL)
+        response = model.generate_content(
+            f"""
+            You are organizing a document library into topics.
+
+            Instructions:
+            - Each cluster below lists documents that were grouped together by similarity
+            - Give each cluster a short topic label (2-5 words) naming what its documents share
+            - Add a one-sentence description of the topic
+            - Labels must be distinct from each other
+            - Write in English
+            - Return ONLY a JSON array of objects with keys "id" (the cluster number), "label" and "description"
+
+            Clusters:
+            {clusters_text}
+            """,
+            generation_config=genai.types.GenerationConfig(
+                temperature=0.3,
+                top_k=1,
+                top_p=1,
+                max_output_tokens=2048,
+            )
+        )
+
+        try:
+            items = parse_json_response(response.text)
+        except ValueError:
+            raise HTTPException(status_code=502, detail="Model returned invalid topic JSON")
+
+        cluster_ids = {cluster.id for cluster in request.clusters}
+        topics = []
+        for item in items if isinstance(items, list) else []:
+            if not isinstance(item, dict):
+                continue
+            try:
+                cluster_id = int(item.get("id"))
+            except (TypeError, ValueError):
+                continue
+            label = str(item.get("label") or "").strip()
+            if cluster_id in cluster_ids and label:
+                topics.append({
+                    "id": cluster_id,
+                    "label": label,
+                    "description": str(item.get("description") or "").strip(),
+                })
+
+        processing_time = round(time.time() - start_time, 2)
+
+        return JSONResponse(
+            status_code=200,
+            content={
+                "topics": topics,
+                "processing_time": processing_time,
+                "model": GENERATION_MODEL,
+                "prompt_version": LABEL_TOPICS_PROMPT_VERSION,
+                "status": "success"
+            }
+        )
+
+    except HTTPException:
+        raise
+    except RATE_LIMIT_ERRORS as e:
+        raise rate_limited(e)
+    except Exception as e:
+        import traceback
+        print(f"Label topics endpoint error: {traceback.format_exc()}")
+
+        raise HTTPException(
+            status_code=500,
+            detail=f"Error labeling topics: {str(e)}"
+        )
+
+async def read_generation_source(file: Optional[UploadFile], text: Optional[str]) -> tuple:
+    """
+    Read the source for study material generation
+    
+    Args:
+        file: PDF file; its text is returned with [Page N] markers
+        text: Plain text (e.g. a summary), used when no file is given
+        
+    Returns:
+        Tuple of (source text, page count); the page count is 0 for plain text
+    """
+    if file is not None:
+        file_content = await file.read()
+        if len(file_content) > MAX_FILE_SIZE:
+            raise HTTPException(
+                status_code=413,
+                detail=f"File too large. Maximum size is {MAX_FILE_SIZE // (1024*1024)}MB."
+            )
+        pages = extract_pages_from_pdf(file_content)
+        source_text, page_count = paginated_text(pages), len(pages)
+    elif text:
+        source_text, page_count = text, 0
+    else:
+        raise HTTPException(status_code=400, detail="Either file or text is required")
+
+    if not source_text.strip():
+        raise HTTPException(status_code=400, detail="No text found in the source")
+
+    return source_text, page_count
+
+def valid_page(page, page_count: int):
+    """Return page if it is a valid 1-based page number, otherwise None"""
+    if isinstance(page, int) and 1 <= page <= page_count:
+        return page
+    return None
+
+@app.post("/flashcards")
+async def generate_flashcards(
+    file: Optional[UploadFile] = File(None),
+    text: Optional[str] = Form(None),
+    count: int = Form(10),
+    language: str = Form("en"),
+    language_name: Optional[str] = Form(None),
+    language_instruction: Optional[str] = Form(None),
+):
+    """
+    Generate question/answer flashcards from a PDF or from summary text
+    
+    Args:
+        file: PDF file; pages are cited in the generated cards
+        text: Summary text, used when no file is given
+        count: Number of cards to generate
+        language: Language code for the cards
+        language_name: Display name of the language
+        language_instruction: Prompt instruction for the card language
+        
+    Returns:
+        JSON response with the generated flashcards
+    """
+    try:
+        start_time = time.time()
+
+        if not api_key:
+            raise HTTPException(
+                status_code=500,
+                detail="GEMINI_API_KEY not configured. Please set the API key in environment variables."
+            )
+
+        source_text, page_count = await read_generation_source(file, text)
+        count = max(1, min(count, 50))
+        language_name = language_name or language
+        language_instruction = language_instruction or f"respond in {language_name}"
+        page_rule = (
+            '- "page" is the number from the [Page N] marker the answer comes from'
+            if page_count else
+            '- "page" is always null'
+        )
+
+        model = genai.GenerativeModel(GENERATION_MODEL)
+        response = model.generate_content(
+            f"""
+            You are creating study flashcards from a document.
+
+            Instructions:
+            - Write exactly {count} flashcards covering the most important facts and concepts
+            - Each question must be answerable from the text alone
+            - Keep answers short (one or two sentences)
+            - Write the cards in {language_name} ({language_instruction})
+            - Return ONLY a JSON array of objects with keys "question", "answer" and "page"
+            {page_rule}
+
+            Text:
+            {source_text}
+            """,
+            generation_config=genai.types.GenerationConfig(
+                temperature=0.4,
+                top_k=1,
+                top_p=1,
+                max_output_tokens=4096,
+            )
+        )
+
+        try:
+            cards = parse_json_response(response.text)
+        except ValueError:
+            raise HTTPException(status_code=502, detail="Model returned invalid flashcard JSON")
+
+        flashcards = []
+        for card in cards if isinstance(cards, list) else []:
+            if not isinstance(card, dict):
+                continue
+            question = str(card.get("question") or "").strip()
+            answer = str(card.get("answer") or "").strip()
+            if not question or not answer:
+                continue
+
+            flashcards.append({
+                "question": question,
+                "answer": answer,
+                "page": valid_page(card.get("page"), page_count),
+            })
+
+        processing_time = round(time.time() - start_time, 2)
+
+        return JSONResponse(
+            status_code=200,
+            content={
+                "flashcards": flashcards,
+                "language": language,
+                "processing_time": processing_time,
+                "model": GENERATION_MODEL,
+                "prompt_version": FLASHCARDS_PROMPT_VERSION,
+                "status": "success"
+            }
+        )
+
+    except HTTPException:
+        raise
+    except RATE_LIMIT_ERRORS as e:
+        raise rate_limited(e)
+    except Exception as e:
+        import traceback
+        print(f"Flashcards endpoint error: {traceback.format_exc()}")
+
+        raise HTTPException(
+            status_code=500,
+            detail=f"Error generating flashcards: {str(e)}"
+        )
+
+@app.post("/quiz")
+async def generate_quiz(
+    file: Optional[UploadFile] = File(None),
+    text: Optional[str] = Form(None),
+    count: int = Form(10),
+    question_types: str = Form("multiple_choice,short_answer"),
+    language: str = Form("en"),
+    language_name: Optional[str] = Form(None),
+    language_instruction: Optional[str] = Form(None),
+):
+    """
+    Generate a quiz of multiple-choice and short-answer questions from a PDF or text
+    
+    Args:
+        file: PDF file; pages are cited in the generated questions
+        text: Summary text, used when no file is given
+        count: Number of questions to generate
+        question_types: Comma-separated question types to include
+        language: Language code for the quiz
+        language_name: Display name of the language
+        language_instruction: Prompt instruction for the quiz language
+        
+    Returns:
+        JSON response with the generated questions and their answers
+    """
+    try:
+        start_time = time.time()
+
+        if not api_key:
+            raise HTTPException(
+                status_code=500,
+                detail="GEMINI_API_KEY not configured. Please set the API key in environment variables."
+            )
+
+        types = [t.strip() for t in question_types.split(",") if t.strip() in ("multiple_choice", "short_answer")]
+        if not types:
+            raise HTTPException(status_code=400, detail="question_types must include multiple_choice or short_answer")
+
+        source_text, page_count = await read_generation_source(file, text)
+        count = max(1, min(count, 30))
+        language_name = language_name or language
+        language_instruction = language_instruction or f"respond in {language_name}"
+        page_rule = (
+            '- "page" is the number from the [Page N] marker the answer comes from'
+            if page_count else
+            '- "page" is always null'
+        )
+
+        model = genai.GenerativeModel(GENERATION_MODEL)
+        response = model.generate_content(
+            f"""
+            You are writing an exam-preparation quiz about a document.
+
+            Instructions:
+            - Write exactly {count} questions of these types: {", ".join(types)}
+            - Every question and answer must be grounded in the text; do not use outside knowledge
+            - multiple_choice questions have exactly 4 options and one correct option
+            - short_answer questions have a concise reference answer (one sentence)
+            - Write the quiz in {language_name} ({language_instruction})
+            - Return ONLY a JSON array of objects with keys:
+              "type" ("multiple_choice" or "short_answer"), "question", "options" (array of strings, empty for short_answer),
+              "correct_option" (0-based index of the correct option, null for short_answer),
+              "answer" (the correct answer text), "explanation" (why it is correct) and "page"
+            {page_rule}
+
+            Text:
+            {source_text}
+            """,
+            generation_config=genai.types.GenerationConfig(
+                temperature=0.4,
+                top_k=1,
+                top_p=1,
+                max_output_tokens=8192,
+            )
+        )
+
+        try:
+            items = parse_json_response(response.text)
+        except ValueError:
+            raise HTTPException(status_code=502, detail="Model returned invalid quiz JSON")
+
+        questions = []
+        for item in items if isinstance(items, list) else []:
+            if not isinstance(item, dict) or item.get("type") not in types:
+                continue
+            question = str(item.get("question") or "").strip()
+            answer = str(item.get("answer") or "").strip()
+            if not question:
+                continue
+
+            options = []
+            correct_option = None
+            if item["type"] == "multiple_choice":
+                options = [str(o).strip() for o in item.get("options") or [] if str(o).strip()]
+                correct_option = item.get("correct_option")
+                if len(options) < 2 or not isinstance(correct_option, int) or not 0 <= correct_option < len(options):
+                    continue
+                answer = answer or options[correct_option]
+            elif not answer:
+                continue
+
+            questions.append({
+                "type": item["type"],
+                "question": question,
+                "options": options,
+                "correct_option": correct_option,
+                "answer": answer,
+                "explanation": str(item.get("explanation") or "").strip(),
+                "page": valid_page(item.get("page"), page_count),
+            })
+
+        processing_time = round(time.time() - start_time, 2)
+
+        return JSONResponse(
+            status_code=200,
+            content={
+                "questions": questions,
+                "language": language,
+                "processing_time": processing_time,
+                "model": GENERATION_MODEL,
+                "prompt_version": QUIZ_PROMPT_VERSION,
+                "status": "success"
+            }
+        )
+
+    except HTTPException:
+        raise
+    except RATE_LIMIT_ERRORS as e:
+        raise rate_limited(e)
+    except Exception as e:
+        import traceback
+        print(f"Quiz endpoint error: {traceback.format_exc()}")
+
+        raise HTTPException(
+            status_code=500,
+            detail=f"Error generating quiz: {str(e)}"
+        )
+
+@app.post("/glossary")
+async def extract_glossary(
+    file: UploadFile = File(...),
+    max_terms: int = Form(30),
+    language: str = Form("en"),
+    language_name: Optional[str] = Form(None),
+    language_instruction: Optional[str] = Form(None),
+):
+    """
+    Extract key terms with definitions from a PDF
+    
+    Args:
+        file: PDF file to read
+        max_terms: Maximum number of terms to extract
+        language: Language code for the definitions
+        language_name: Display name of the language
+        language_instruction: Prompt instruction for the definition language
+        
+    Returns:
+        JSON response with terms, definitions and first-occurrence pages
+    """
+    try:
+        start_time = time.time()
+
+        if not api_key:
+            raise HTTPException(
+                status_code=500,
+                detail="GEMINI_API_KEY not configured. Please set the API key in environment variables."
+            )
+
+        file_content = await file.read()
+        if len(file_content) > MAX_FILE_SIZE:
+            raise HTTPException(
+                status_code=413,
+                detail=f"File too large. Maximum size is {MAX_FILE_SIZE // (1024*1024)}MB."
+            )
+        pages = extract_pages_from_pdf(file_content)
+        source_text = paginated_text(pages)
+        if not source_text.strip():
+            raise HTTPException(status_code=400, detail="No text found in the PDF")
+
+        max_terms = max(1, min(max_terms, 100))
+        language_name = language_name or language
+        language_instruction = language_instruction or f"respond in {language_name}"
+
+        model = genai.GenerativeModel(GENERATION_MODEL)
+        response = model.generate_content(
+            f"""
+            You are building a glossary for a technical document.
+
+            Instructions:
+            - Extract up to {max_terms} key terms, acronyms and named concepts a reader needs to understand
+            - Use the term exactly as it is written in the document
+            - Give each term a concise definition (one or two sentences) based on the document
+            - Write the definitions in {language_name} ({language_instruction})
+            - "page" is the number from the [Page N] marker where the term first appears
+            - Return ONLY a JSON array of objects with keys "term", "definition" and "page"
+
+            Text:
+            {source_text}
+            """,
+            generation_config=genai.types.GenerationConfig(
+                temperature=0.2,
+                top_k=1,
+                top_p=1,
+                max_output_tokens=8192,
+            )
+        )
+
+        try:
+            items = parse_json_response(response.text)
+        except ValueError:
+            raise HTTPException(status_code=502, detail="Model returned invalid glossary JSON")
+
+        terms = []
+        seen = set()
+        for item in items if isinstance(items, list) else []:
+            if not isinstance(item, dict):
+                continue
+            term = str(item.get("term") or "").strip()
+            definition = str(item.get("definition") or "").strip()
+            if not term or not definition or term.lower() in seen:
+                continue
+            seen.add(term.lower())
+
+            # Prefer the page found in the text; the model's page is only a fallback
+            page = first_occurrence_page(term, pages) or valid_page(item.get("page"), len(pages))
+            terms.append({"term": term, "definition": definition, "page": page})
+
+        processing_time = round(time.time() - start_time, 2)
+
+        return JSONResponse(
+            status_code=200,
+            content={
+                "terms": terms,
+                "language": language,
+                "processing_time": processing_time,
+                "model": GENERATION_MODEL,
+                "prompt_version": GLOSSARY_PROMPT_VERSION,
+                "status": "success"
+            }
+        )
+
+    except HTTPException:
+        raise
+    except RATE_LIMIT_ERRORS as e:
+        raise rate_limited(e)
+    except Exception as e:
+        import traceback
+        print(f"Glossary endpoint error: {traceback.format_exc()}")
+
+        raise HTTPException(
+            status_code=500,
+            detail=f"Error extracting glossary: {str(e)}"
+        )
+
+@app.post("/outline")
+async def generate_outline(
+    file: UploadFile = File(...),
+    language: str = Form("en"),
+    language_name: Optional[str] = Form(None),
+    language_instruction: Optional[str] = Form(None),
+):
+    """
+    Build a hierarchical outline of a PDF
+    
+    The PDF's embedded bookmarks are used when present, with one-line gists
+    added by the model; otherwise the model derives the structure from the text.
+    
+    Args:
+        file: PDF file to read
+        language: Language code for gists (and derived headings)
+        language_name: Display name of the language
+        language_instruction: Prompt instruction for the outline language
+        
+    Returns:
+        JSON response with the section tree and its source ("embedded" or "ai")
+    """
+    try:
+        start_time = time.time()
+
+        file_content = await file.read()
+        if len(file_content) > MAX_FILE_SIZE:
+            raise HTTPException(
+                status_code=413,
+                detail=f"File too large. Maximum size is {MAX_FILE_SIZE // (1024*1024)}MB."
+            )
+
+        try:
+            pdf_reader = PyPDF2.PdfReader(io.BytesIO(file_content))
+        except Exception as e:
+            raise HTTPException(status_code=400, detail=f"Error reading PDF: {str(e)}")
+
+        pages = extract_pages_from_pdf(file_content)
+        source_text = paginated_text(pages)
+        sections = embedded_outline(pdf_reader)
+        source = "embedded" if sections else "ai"
+
+        language_name = language_name or language
+        language_instruction = language_instruction or f"respond in {language_name}"
+        model = genai.GenerativeModel(GENERATION_MODEL) if api_key else None
+        generation_config = genai.types.GenerationConfig(
+            temperature=0.2,
+            top_k=1,
+            top_p=1,
+            max_output_tokens=8192,
+        )
+
+        if sections:
+            # Gists are a best-effort addition to the document's own outline
+            flat = flatten_outline(sections)
+            if model and source_text.strip():
+                headings = "\n".join(
+                    f"{i + 1}. {s['heading']} (page {s['page'] or '?'})" for i, s in enumerate(flat)
+                )
+                try:
+                    response = model.generate_content(
+                        f"""
+                        Write a one-line gist for each section of this document's outline.
+
+                        Instructions:
+                        - Each gist is a single sentence describing what the section covers
+                        - Write the gists in {language_name} ({language_instruction})
+                        - Return ONLY a JSON array of {len(flat)} strings, in the same order as the sections
+
+                        Sections:
+                        {headings}
+
+                        Text:
+                        {source_text}
+                        """,
+                        generation_config=generation_config,
+                    )
+                    gists = parse_json_response(response.text)
+                    if isinstance(gists, list):
+                        for section, gist in zip(flat, gists):
+                            section["gist"] = str(gist or "").strip()
+                except Exception as e:
+                    print(f"Warning: Could not generate outline gists: {e}")
+        else:
+            if not model:
+                raise HTTPException(
+                    status_code=500,
+                    detail="GEMINI_API_KEY not configured. Please set the API key in environment variables."
+                )
+            if not source_text.strip():
+                raise HTTPException(status_code=400, detail="No text found in the PDF")
+
+            response = model.generate_content(
+                f"""
+                You are writing the table of contents of a document.
+
+                Instructions:
+                - Identify the document's sections and subsections in reading order
+                - Use the document's own headings when it has them; otherwise write short descriptive headings
+                - Nest subsections under their parent section (at most 3 levels)
+                - "page" is the number from the [Page N] marker where the section starts
+                - "gist" is one sentence describing what the section covers
+                - Write in {language_name} ({language_instruction})
+                - Return ONLY a JSON array of objects with keys "heading", "page", "gist" and "children" (an array of the same objects)
+
+                Text:
+                {source_text}
+                """,
+                generation_config=generation_config,
+            )
+
+            try:
+                sections = normalize_outline(parse_json_response(response.text), len(pages))
+            except ValueError:
+                raise HTTPException(status_code=502, detail="Model returned invalid outline JSON")
+
+        processing_time = round(time.time() - start_time, 2)
+
+        return JSONResponse(
+            status_code=200,
+            content={
+                "source": source,
+                "sections": sections,
+                "language": language,
+                "processing_time": processing_time,
+                "model": GENERATION_MODEL if model else "",
+                "prompt_version": OUTLINE_PROMPT_VERSION,
+                "status": "success"
+            }
+        )
+
+    except HTTPException:
+        raise
+    except RATE_LIMIT_ERRORS as e:
+        raise rate_limited(e)
+    except Exception as e:
+        import traceback
+        print(f"Outline endpoint error: {traceback.format_exc()}")
+
+        raise HTTPException(
+            status_code=500,
+            detail=f"Error generating outline: {str(e)}"
+        )
+
+@app.post("/anki-package")
+async def anki_package(request: AnkiPackageRequest):
+    """
+    Package question/answer cards as an Anki deck
+    
+    Args:
+        request: AnkiPackageRequest with the deck name and cards
+        
+    Returns:
+        The .apkg file contents
+    """
+    if not request.cards:
+        raise HTTPException(status_code=400, detail="At least one card is required")
+
+    try:
+        package = build_apkg(request.deck_name, [card.dict() for card in request.cards])
+    except Exception as e:
+        raise HTTPException(
+            status_code=500,
+            detail=f"Error building Anki package: {str(e)}"
+        )
+
+    return Response(content=package, media_type="application/octet-stream")
+
+@app.post("/extract-text")
+async def extract_text(file: UploadFile = File(...), max_chars: int = Form(5000)):
+    """
+    Extract a text sample from a PDF file (used for language detection)
+    
+    Args:
+        file: PDF file to read
+        max_chars: Maximum number of characters to return
+        
+    Returns:
+        JSON response with the extracted text
+    """
+    file_content = await file.read()
+    pdf_text = extract_text_from_pdf(file_content)
+
+    return JSONResponse(
+        status_code=200,
+        content={
+            "text": pdf_text[:max_chars],
+            "total_characters": len(pdf_text),
+            "status": "success"
+        }
+    )
+
 @app.post("/summarize")
-async def summarize_pdf(file: UploadFile = File(...), style: Style = Form(...), language: Language = Form(...)):
+async def summarize_pdf(
+    file: UploadFile = File(...),
+    style: Style = Form(...),
+    language: str = Form(...),
+    language_name: Optional[str] = Form(None),
+    language_instruction: Optional[str] = Form(None),
+):
     """
     Upload and summarize a PDF file in one step
     
     Args:
         file: PDF file to upload and process
+        style: Summary style
+        language: Language code from the Go backend's language registry
+        language_name: Display name of the language
+        language_instruction: Prompt instruction for the summary language
         
     Returns:
         JSON response with summary data
@@ -659,8 +1758,15 @@ async def summarize_pdf(file: UploadFile = File(...), style: Style = Form(...),
             print(f"Processing {len(chunks)} chunks for summarization")
             
             # Summarize using chunking strategy
-            ai_summary = summarize_chunks(chunks, style.value, language.value)
+            ai_summary = summarize_chunks(
+                chunks,
+                style.value,
+                language_name or language,
+                language_instruction or f"respond in {language_name or language}",
+            )
             
+        except RATE_LIMIT_ERRORS:
+            raise
         except Exception as e:
             # Fallback to a basic summary if AI fails
             ai_summary = f"AI summarization unavailable. Document contains {word_stats['total_words']} words across {word_stats['paragraphs']} paragraphs. Error: {str(e)}"
@@ -702,6 +1808,8 @@ async def summarize_pdf(file: UploadFile = File(...), style: Style = Form(...),
                 "processing_time_seconds": processing_time,
                 "embedding_dimensions": len(embedding_vector)
             },
+            "model": GENERATION_MODEL,
+            "prompt_version": SUMMARIZE_PROMPT_VERSION,
             "status": "completed"
         }
         
@@ -713,6 +1821,8 @@ async def summarize_pdf(file: UploadFile = File(...), style: Style = Form(...),
     except HTTPException:
         # Re-raise HTTP exceptions
         raise
+    except RATE_LIMIT_ERRORS as e:
+        raise rate_limited(e)
     except Exception as e:
         # Handle unexpected errors
         raise HTTPException(
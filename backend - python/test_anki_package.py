@@ -0,0 +1,56 @@
+"""
+Re-importing an exported deck must update the notes Anki already has, even
+after a card's front was edited, so note guids follow the flashcard ID.
+
+Run with: python -m unittest test_anki_package
+"""
+
+import io
+import os
+import sqlite3
+import tempfile
+import unittest
+import zipfile
+
+from anki_package import build_apkg
+
+
+def note_guids(package: bytes) -> list:
+    """Read the note guids from an .apkg package, in card order"""
+    with tempfile.TemporaryDirectory() as tmp:
+        with zipfile.ZipFile(io.BytesIO(package)) as archive:
+            archive.extract("collection.anki2", tmp)
+        db = sqlite3.connect(os.path.join(tmp, "collection.anki2"))
+        try:
+            rows = db.execute("SELECT n.guid FROM notes n JOIN cards c ON c.nid = n.id ORDER BY c.due").fetchall()
+        finally:
+            db.close()
+    return [guid for (guid,) in rows]
+
+
+class AnkiPackageTest(unittest.TestCase):
+    def test_edited_fronts_keep_their_guid(self):
+        before = note_guids(build_apkg("Report", [
+            {"id": 7, "front": "What is revenue?", "back": "Income"},
+            {"id": 8, "front": "What is profit?", "back": "Revenue minus costs"},
+        ]))
+        after = note_guids(build_apkg("Report", [
+            {"id": 7, "front": "What is total revenue?", "back": "Income"},
+            {"id": 8, "front": "What is profit?", "back": "Revenue minus costs"},
+        ]))
+
+        self.assertEqual(before, after)
+        self.assertEqual(len(set(before)), 2)
+
+    def test_cards_without_an_id_fall_back_to_their_front(self):
+        cards = [{"front": "What is revenue?", "back": "Income"}]
+        first = note_guids(build_apkg("Report", cards))
+        again = note_guids(build_apkg("Report", cards))
+        edited = note_guids(build_apkg("Report", [{"front": "What is total revenue?", "back": "Income"}]))
+
+        self.assertEqual(first, again)
+        self.assertNotEqual(first, edited)
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,73 @@
+"""
+Rate limits from Gemini and the embedding API must reach the Go backend as
+429 responses carrying Retry-After, so that it backs off and retries.
+
+Run with: python -m unittest test_rate_limits
+"""
+
+import asyncio
+import unittest
+from types import SimpleNamespace
+from unittest import mock
+
+from fastapi import HTTPException
+from google.api_core import exceptions as google_exceptions
+
+import main
+
+
+def raise_quota_exceeded(*args, **kwargs):
+    raise google_exceptions.ResourceExhausted("Quota exceeded for generate_content, retry in 12.4s")
+
+
+class RateLimitTest(unittest.TestCase):
+    def test_chat_maps_gemini_resource_exhausted_to_429(self):
+        model = mock.Mock()
+        model.start_chat.return_value.send_message.side_effect = raise_quota_exceeded
+
+        with mock.patch.object(main, "api_key", "test-key"), \
+                mock.patch.object(main.genai, "GenerativeModel", return_value=model):
+            with self.assertRaises(HTTPException) as raised:
+                asyncio.run(main.chat(main.ChatRequest(message="What is this about?")))
+
+        self.assertEqual(raised.exception.status_code, 429)
+        self.assertEqual(raised.exception.headers, {"Retry-After": "13"})
+
+    def test_summaries_do_not_swallow_gemini_rate_limits(self):
+        model = mock.Mock()
+        model.generate_content.side_effect = raise_quota_exceeded
+
+        with mock.patch.object(main.genai, "GenerativeModel", return_value=model):
+            with self.assertRaises(google_exceptions.ResourceExhausted):
+                main.summarize_chunks(["first part", "second part"], "short", "English", "respond in English")
+            with self.assertRaises(google_exceptions.ResourceExhausted):
+                main.summarize_single_chunk("text", "short", "English", "respond in English")
+
+    def test_embedding_forwards_the_embedding_api_429(self):
+        response = SimpleNamespace(status_code=429, headers={"Retry-After": "7"}, text="Too Many Requests")
+
+        with mock.patch.object(main.requests, "post", return_value=response):
+            with self.assertRaises(HTTPException) as raised:
+                asyncio.run(main.generate_text_embedding(main.EmbeddingRequest(text="hello")))
+
+        self.assertEqual(raised.exception.status_code, 429)
+        self.assertEqual(raised.exception.headers, {"Retry-After": "7"})
+
+    def test_retry_after_seconds(self):
+        class RetryInfoError(Exception):
+            details = [SimpleNamespace(retry_delay=SimpleNamespace(seconds=20, nanos=500000000))]
+
+        tests = [
+            ("retry info detail", RetryInfoError("quota"), "21"),
+            ("delay in message", Exception("Please retry in 3.2s."), "4"),
+            ("retry_delay in message", Exception("retry_delay {\n  seconds: 45\n}"), "45"),
+            ("embedding api header", main.EmbeddingRateLimited("9"), "9"),
+            ("no delay", Exception("Quota exceeded"), None),
+        ]
+        for name, error, want in tests:
+            with self.subTest(name):
+                self.assertEqual(main.retry_after_seconds(error), want)
+
+
+if __name__ == "__main__":
+    unittest.main()